@@ -13,7 +13,10 @@
 
 package log
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // Level is a logging priority. Higher levels are more important.
 type Level string
@@ -43,3 +46,13 @@ func (m *Level) UnmarshalText(text []byte) error {
 	*m = Level(strings.ToUpper(string(text)))
 	return nil
 }
+
+// ParseLevel parses a level, case insensitive. Unlike UnmarshalText, unknown levels are rejected.
+func ParseLevel(text string) (Level, error) {
+	switch l := Level(strings.ToUpper(text)); l {
+	case TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel:
+		return l, nil
+	default:
+		return "", fmt.Errorf("unknown log level %q", text)
+	}
+}
@@ -29,3 +29,12 @@ func TestLevel_UnmarshalText(t *testing.T) {
 	err = s.UnmarshalText([]byte("unknown"))
 	assert.NoError(t, err)
 }
+
+func TestParseLevel(t *testing.T) {
+	l, err := ParseLevel("warn")
+	assert.NoError(t, err)
+	assert.Equal(t, WarnLevel, l)
+
+	_, err = ParseLevel("unknown")
+	assert.Error(t, err)
+}
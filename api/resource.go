@@ -22,6 +22,13 @@ const (
 	TpsOwnerKey      = attribute.Key("server.owner")
 	TpsCmdbIDKey     = attribute.Key("cmdb.module.id")
 
+	// HostIPKey the first non-loopback IP of the host
+	HostIPKey = attribute.Key("host.ip")
+	// TpsCPUQuotaKey the CPU cores available to the process, the cgroup quota in container
+	TpsCPUQuotaKey = attribute.Key("tps.cpu.quota")
+	// TpsMemoryQuotaKey the memory bytes available to the process, the cgroup quota in container
+	TpsMemoryQuotaKey = attribute.Key("tps.memory.quota")
+
 	OpenTelemetryName = "opentelemetry"
 	TenantHeaderKey   = "X-Tps-TenantID"
 )
@@ -17,10 +17,17 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"trpc-system/go-opentelemetry"
 	"trpc-system/go-opentelemetry/api/log"
 	"trpc-system/go-opentelemetry/config/codes"
+	"trpc-system/go-opentelemetry/exporter/retry"
+	"trpc-system/go-opentelemetry/pkg/envconfig"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
+	sdklog "trpc-system/go-opentelemetry/sdk/log"
 	"trpc-system/go-opentelemetry/sdk/metric"
+	"trpc-system/go-opentelemetry/sdk/trace"
 )
 
 // Config opentelemetry trpc plugin config
@@ -33,6 +40,19 @@ type Config struct {
 	Traces     TracesConfig  `yaml:"traces"`
 	Codes      []*codes.Code `yaml:"codes"`
 	Attributes []*Attribute  `yaml:"attributes"`
+	// Resource the detection of the resource shared by traces, metrics and logs
+	Resource ResourceConfig `yaml:"resource"`
+	// TLS the TLS of the trace, metric and log exporters, logs.tls takes precedence for the log exporter if enabled
+	TLS tlsconfig.Config `yaml:"tls"`
+}
+
+// ResourceConfig the resource detectors, the detected attributes are overridden by OTEL_RESOURCE_ATTRIBUTES
+// and the attributes of the plugin
+type ResourceConfig struct {
+	// Detectors host, process, container, kubernetes and quota run in order, opt-in, none by default
+	Detectors []string `yaml:"detectors"`
+	// PodInfoDir the mount path of the downward API volume read by the kubernetes detector, default /etc/podinfo
+	PodInfoDir string `yaml:"pod_info_dir"`
 }
 
 // TracesConfig traces config
@@ -49,9 +69,53 @@ type TracesConfig struct {
 	DisableParentSampling bool `yaml:"disable_parent_sampling"`
 	// EnableZPage local zpage
 	EnableZPage bool `yaml:"enable_zpage"`
+	// TailSample trace aware deferred sampling, works with EnableDeferredSample
+	TailSample TailSampleConfig `yaml:"tail_sample"`
+	// SpanMetrics the RED metrics aggregated from the ended spans
+	SpanMetrics SpanMetricsConfig `yaml:"span_metrics"`
+	// ServiceGraph the service dependency graph metrics aggregated from the client and server spans
+	ServiceGraph ServiceGraphConfig `yaml:"service_graph"`
+	// Propagators the formats extracted in order, also injected if InjectPropagators is empty,
+	// tracecontext, baggage, b3, b3multi, jaeger, ot and trpc are supported, default tracecontext and baggage
+	Propagators []string `yaml:"propagators"`
+	// InjectPropagators the formats injected
+	InjectPropagators []string `yaml:"inject_propagators"`
 
 	// ExportConfig config of trace exporter
 	ExportConfig TraceExporterOption `yaml:"export_config"`
+	// Exporters the additional destinations of the spans besides addr, e.g. a second backend or an error store
+	Exporters []ExporterConfig `yaml:"exporters"`
+}
+
+// TailSampleConfig defines the behavior of trace aware deferred sampling.
+// Ended spans are buffered by trace and the whole local trace is kept or dropped together.
+// For detailed parameter description, ref to sdk/trace/tail_sample_processor.go (TailSampleConfig)
+type TailSampleConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	DecisionWait time.Duration `yaml:"decision_wait"`
+	MaxSpans     int           `yaml:"max_spans"`
+	MaxBytes     int           `yaml:"max_bytes"`
+	// SampleAttributes keep the trace if any span has one of the attributes
+	SampleAttributes []*Attribute `yaml:"sample_attributes"`
+}
+
+// SpanMetricsConfig defines the RED metrics aggregated from the ended spans.
+// For detailed parameter description, ref to sdk/trace/span_metrics_processor.go (SpanMetricsConfig)
+type SpanMetricsConfig struct {
+	Enabled    bool      `yaml:"enabled"`
+	Attributes []string  `yaml:"attributes"`
+	Buckets    []float64 `yaml:"buckets"`
+	MaxSeries  int       `yaml:"max_series"`
+}
+
+// ServiceGraphConfig defines the service dependency graph metrics.
+// For detailed parameter description, ref to sdk/trace/service_graph_processor.go (ServiceGraphConfig)
+type ServiceGraphConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Buckets    []float64     `yaml:"buckets"`
+	WaitTime   time.Duration `yaml:"wait_time"`
+	MaxPending int           `yaml:"max_pending"`
+	MaxEdges   int           `yaml:"max_edges"`
 }
 
 // TraceExporterOption defines the behavior of the trace span exporter.
@@ -63,6 +127,164 @@ type TraceExporterOption struct {
 	MaxExportBatchSize int           `yaml:"max_export_batch_size"`
 	MaxPacketSize      int           `yaml:"max_packet_size"`
 	BlockOnQueueFull   bool          `yaml:"block_on_queue_full"`
+	Spool              SpoolConfig   `yaml:"spool"`
+}
+
+// SpoolConfig defines the on-disk spool, batches failed to export are written to the spool
+// and replayed when the exporter reconnects.
+// For detailed parameter description, ref to exporter/spool/spool.go (Config)
+type SpoolConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Dir spool directory, each telemetry uses its own subdirectory, default is the directory of the service in a temp directory
+	Dir          string `yaml:"dir"`
+	MaxBytes     int64  `yaml:"max_bytes"`
+	SegmentBytes int64  `yaml:"segment_bytes"`
+}
+
+// ExporterConfig defines an additional destination of the spans or the logs, with its own exporter, batcher,
+// queue and filter. For detailed parameter description, ref to destination.go (Destination)
+type ExporterConfig struct {
+	// Name the destination label of the opentelemetry_sdk_destination_total metric, required and unique
+	Name string `yaml:"name"`
+	// Addr http:// or https:// for OTLP/HTTP, gRPC otherwise
+	Addr string `yaml:"addr"`
+	// TenantID the tenant of the destination, the top level tenant_id if empty
+	TenantID string            `yaml:"tenant_id"`
+	Headers  map[string]string `yaml:"headers"`
+	// TLS the TLS of the destination, the top level tls is not inherited
+	TLS   tlsconfig.Config `yaml:"tls"`
+	Retry RetryConfig      `yaml:"retry"`
+	Batch BatchConfig      `yaml:"batch"`
+	// Filter the spans or the logs exported to the destination, all if empty
+	Filter FilterConfig `yaml:"filter"`
+}
+
+// RetryConfig defines the retry of the failed exports, the zero values are of exporter/retry DefaultConfig
+type RetryConfig struct {
+	Disabled        bool          `yaml:"disabled"`
+	InitialInterval time.Duration `yaml:"initial_interval"`
+	MaxInterval     time.Duration `yaml:"max_interval"`
+	MaxElapsedTime  time.Duration `yaml:"max_elapsed_time"`
+}
+
+// BatchConfig defines the batcher and the queue of a destination, the zero values are of the batch processors
+type BatchConfig struct {
+	MaxQueueSize       int           `yaml:"max_queue_size"`
+	BatchTimeout       time.Duration `yaml:"batch_timeout"`
+	ExportTimeout      time.Duration `yaml:"export_timeout"`
+	MaxExportBatchSize int           `yaml:"max_export_batch_size"`
+	BlockOnQueueFull   bool          `yaml:"block_on_queue_full"`
+}
+
+// FilterConfig defines the spans or the logs exported to a destination, which must pass all the conditions
+type FilterConfig struct {
+	// ErrorOnly the spans of the error status only, ignored by the logs
+	ErrorOnly bool `yaml:"error_only"`
+	// MinLevel the logs of the level or higher only, ignored by the spans
+	MinLevel log.Level `yaml:"min_level"`
+	// Attributes the spans or the logs with all the attributes only
+	Attributes []*Attribute `yaml:"attributes"`
+}
+
+// SpanDestination returns the destination of opentelemetry.WithSpanDestinations
+func (c ExporterConfig) SpanDestination() opentelemetry.SpanDestination {
+	d := opentelemetry.SpanDestination{Destination: c.destination()}
+	b := c.Batch
+	if b.MaxQueueSize > 0 {
+		d.BatchOptions = append(d.BatchOptions, trace.WithMaxQueueSize(b.MaxQueueSize))
+	}
+	if b.BatchTimeout > 0 {
+		d.BatchOptions = append(d.BatchOptions, trace.WithBatchTimeout(b.BatchTimeout))
+	}
+	if b.ExportTimeout > 0 {
+		d.BatchOptions = append(d.BatchOptions, trace.WithExportTimeout(b.ExportTimeout))
+	}
+	if b.MaxExportBatchSize > 0 {
+		d.BatchOptions = append(d.BatchOptions, trace.WithMaxExportBatchSize(b.MaxExportBatchSize))
+	}
+	if b.BlockOnQueueFull {
+		d.BatchOptions = append(d.BatchOptions, trace.WithBlocking())
+	}
+	var filters []trace.SpanFilter
+	if c.Filter.ErrorOnly {
+		filters = append(filters, trace.ErrorSpanFilter())
+	}
+	if kvs := c.Filter.attributes(); len(kvs) > 0 {
+		filters = append(filters, trace.AttributeSpanFilter(kvs...))
+	}
+	if len(filters) > 0 {
+		d.Filter = trace.AllSpanFilters(filters...)
+	}
+	return d
+}
+
+// LogDestination returns the destination of opentelemetry.WithLogDestinations and opentelemetry.NewLogFanOutProcessor
+func (c ExporterConfig) LogDestination() opentelemetry.LogDestination {
+	d := opentelemetry.LogDestination{Destination: c.destination()}
+	b := c.Batch
+	if b.MaxQueueSize > 0 {
+		d.BatchOptions = append(d.BatchOptions, sdklog.WithMaxQueueSize(b.MaxQueueSize))
+	}
+	if b.BatchTimeout > 0 {
+		d.BatchOptions = append(d.BatchOptions, sdklog.WithBatchTimeout(b.BatchTimeout))
+	}
+	if b.ExportTimeout > 0 {
+		d.BatchOptions = append(d.BatchOptions, sdklog.WithExportTimeout(b.ExportTimeout))
+	}
+	if b.MaxExportBatchSize > 0 {
+		d.BatchOptions = append(d.BatchOptions, sdklog.WithMaxExportBatchSize(b.MaxExportBatchSize))
+	}
+	if b.BlockOnQueueFull {
+		d.BatchOptions = append(d.BatchOptions, sdklog.WithBlocking())
+	}
+	var filters []sdklog.Filter
+	if c.Filter.MinLevel != "" {
+		filters = append(filters, sdklog.SeverityFilter(c.Filter.MinLevel))
+	}
+	if kvs := c.Filter.attributes(); len(kvs) > 0 {
+		filters = append(filters, sdklog.AttributeFilter(kvs...))
+	}
+	if len(filters) > 0 {
+		d.Filter = sdklog.AllFilters(filters...)
+	}
+	return d
+}
+
+func (c ExporterConfig) destination() opentelemetry.Destination {
+	d := opentelemetry.Destination{
+		Name:     c.Name,
+		Addr:     c.Addr,
+		TenantID: c.TenantID,
+		Headers:  c.Headers,
+	}
+	if c.TLS.Enabled {
+		tlsCfg := c.TLS
+		d.TLS = &tlsCfg
+	}
+	r := c.Retry
+	if r.Disabled || r.InitialInterval > 0 || r.MaxInterval > 0 || r.MaxElapsedTime > 0 {
+		retryCfg := retry.DefaultConfig
+		retryCfg.Enabled = !r.Disabled
+		if r.InitialInterval > 0 {
+			retryCfg.InitialInterval = r.InitialInterval
+		}
+		if r.MaxInterval > 0 {
+			retryCfg.MaxInterval = r.MaxInterval
+		}
+		if r.MaxElapsedTime > 0 {
+			retryCfg.MaxElapsedTime = r.MaxElapsedTime
+		}
+		d.Retry = &retryCfg
+	}
+	return d
+}
+
+func (c FilterConfig) attributes() []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(c.Attributes))
+	for _, a := range c.Attributes {
+		kvs = append(kvs, attribute.String(a.Key, a.Value))
+	}
+	return kvs
 }
 
 // Attribute defines struct of k-v data
@@ -77,6 +299,25 @@ type SamplerConfig struct {
 	SpecialFractions  []SpecialFraction `yaml:"special_fractions"`
 	SamplerServerAddr string            `yaml:"sampler_server_addr"`
 	SyncInterval      time.Duration     `yaml:"sync_interval"`
+	// RateLimit max sampled traces per second, 0 means unlimited
+	RateLimit float64 `yaml:"rate_limit"`
+	// Adaptive adjusts the fractions to hit the export budget, fraction is the initial fraction
+	Adaptive AdaptiveSamplerConfig `yaml:"adaptive"`
+	// Consistent samples consistently across services by the th and rv values of the ot tracestate entry
+	Consistent bool `yaml:"consistent"`
+}
+
+// AdaptiveSamplerConfig adaptive sampling config
+type AdaptiveSamplerConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TargetSpansPerSecond target exported spans per second
+	TargetSpansPerSecond float64 `yaml:"target_spans_per_second"`
+	// TargetBytesPerSecond target exported bytes per second
+	TargetBytesPerSecond float64 `yaml:"target_bytes_per_second"`
+	// Interval adjust interval, default 10s
+	Interval time.Duration `yaml:"interval"`
+	// Smoothing weight of the latest interval in (0, 1], lower is smoother, default 0.5
+	Smoothing float64 `yaml:"smoothing"`
 }
 
 // SpecialFraction special fraction config
@@ -84,12 +325,57 @@ type SpecialFraction struct {
 	CalleeService   string           `yaml:"callee_service"`
 	DefaultFraction float64          `yaml:"default_fraction"`
 	CalleeMethods   []MethodFraction `yaml:"callee_methods"`
+	// RateLimit max sampled traces per second of the callee service, 0 means unlimited
+	RateLimit float64 `yaml:"rate_limit"`
 }
 
 // MethodFraction method special fraction
 type MethodFraction struct {
 	Method   string  `yaml:"method"`
 	Fraction float64 `yaml:"fraction"`
+	// RateLimit max sampled traces per second of the callee method, 0 means unlimited
+	RateLimit float64 `yaml:"rate_limit"`
+}
+
+// TraceConfig returns the config of trace.NewSampler
+func (c SamplerConfig) TraceConfig() trace.SamplerConfig {
+	return trace.SamplerConfig{
+		Fraction:           c.Fraction,
+		SpecialFractions:   specialFractions(c.SpecialFractions),
+		SamplerServiceAddr: c.SamplerServerAddr,
+		SyncInterval:       c.SyncInterval,
+		RateLimit:          c.RateLimit,
+		Adaptive:           adaptiveConfig(c.Adaptive),
+		Consistent:         c.Consistent,
+	}
+}
+
+func adaptiveConfig(c AdaptiveSamplerConfig) *trace.AdaptiveConfig {
+	if !c.Enabled {
+		return nil
+	}
+	return &trace.AdaptiveConfig{
+		TargetSpansPerSecond: c.TargetSpansPerSecond,
+		TargetBytesPerSecond: c.TargetBytesPerSecond,
+		Interval:             c.Interval,
+		Smoothing:            c.Smoothing,
+	}
+}
+
+func specialFractions(fractions []SpecialFraction) map[string]trace.SpecialFraction {
+	result := make(map[string]trace.SpecialFraction)
+	for _, f := range fractions {
+		methods := make(map[string]trace.MethodFraction)
+		for _, m := range f.CalleeMethods {
+			methods[m.Method] = trace.MethodFraction{Fraction: m.Fraction, RateLimit: m.RateLimit}
+		}
+		result[f.CalleeService] = trace.SpecialFraction{
+			DefaultFraction: f.DefaultFraction,
+			Methods:         methods,
+			RateLimit:       f.RateLimit,
+		}
+	}
+	return result
 }
 
 // MetricsConfig defines the configuration for the various elements of Metrics
@@ -98,6 +384,10 @@ type MetricsConfig struct {
 	// EnabledRegister if true, register service to registry
 	EnabledRegister   bool     `yaml:"enable_register"`
 	RegistryEndpoints []string `yaml:"registry_endpoints"`
+	// RegistryType etcd (default), consul, file_sd, http_sd or the type registered by metric.RegisterRegistryFactory
+	RegistryType string `yaml:"registry_type"`
+	// FileSDPath the file written by the file_sd registry
+	FileSDPath string `yaml:"file_sd_path"`
 	// TLSCert certificate chain, private key, and root CA certificate
 	TLSCert     metric.TLSCert `yaml:"tls_cert"`
 	ServerOwner string         `yaml:"server_owner"`
@@ -108,8 +398,20 @@ type MetricsConfig struct {
 	ServerHistogramBuckets []float64                                     `yaml:"server_histogram_buckets"`
 	// DisableRPCMethodMapping do not process with RPCName (cannot be true when using restful API)
 	DisableRPCMethodMapping bool `yaml:"disable_rpc_method_mapping"`
+	// MethodTemplates the route templates of the http paths, e.g. /user/{id}/orders and /files/*,
+	// applied to both the metrics and the span names
+	MethodTemplates []string `yaml:"method_templates"`
+	// AutoMethodTemplate learns the templates of the unmatched http paths by the numeric, UUID and hex segments
+	AutoMethodTemplate bool `yaml:"auto_method_template"`
 	// PrometheusPush prometheus push config
 	PrometheusPush metric.PrometheusPushConfig `yaml:"prometheus_push"`
+	// CardinalityLimit the cardinality budgets of the RPC metrics, the values beyond are folded into __overflow__
+	CardinalityLimit metric.CardinalityLimitConfig `yaml:"cardinality_limit"`
+	// Exemplar the exemplar policies of the RPC handled metrics, by default and by metric name
+	Exemplar metric.ExemplarConfig `yaml:"exemplar"`
+	// Backend the backend of the RPC metrics, prometheus (default) or opentelemetry,
+	// opentelemetry exports the RPC metrics by the OTLP metric exporter
+	Backend string `yaml:"backend"`
 }
 
 // LogsConfig defines the configuration for the various elements of Logs
@@ -130,6 +432,8 @@ type LogsConfig struct {
 	RateLimit RateLimit `yaml:"rate_limit"`
 	// log exporter config
 	ExportOption ExportOption `yaml:"export_option"`
+	// Exporters the additional destinations of the logs besides addr, exported over gRPC only
+	Exporters []ExporterConfig `yaml:"exporters"`
 }
 
 // TraceLogOption defines trace_log option, which also called flow log, print request and response.
@@ -161,9 +465,11 @@ type ExportOption struct {
 	// MaxBatchPacketSize max batch size of log to send to remote server, when the size of logs in buffer exceeds this
 	// config, the logs will be sent to remote server
 	MaxBatchPacketSize int `yaml:"max_batch_packet_size"`
+	// Spool on-disk spool of the logs failed to export
+	Spool SpoolConfig `yaml:"spool"`
 }
 
-// TLSConfig defines tls config
+// TLSConfig defines tls config of the log exporter, the top level tls is used if not enabled
 type TLSConfig struct {
 	Enabled            bool `yaml:"enabled"`
 	InsecureSkipVeriry bool `yaml:"insecure_skip_veriry"`
@@ -220,6 +526,39 @@ func DefaultConfig() Config {
 	return cfg
 }
 
+// ApplyEnv applies the OTEL_* environment variables to the defaults, it is called before the YAML is decoded
+// so that the YAML takes precedence. The address is cleared if any endpoint is set by the environment,
+// and the empty address is resolved by signal from the environment in opentelemetry.Setup.
+func (c *Config) ApplyEnv(env *envconfig.Config) {
+	if env.Traces.Endpoint != "" || env.Metrics.Endpoint != "" || env.Logs.Endpoint != "" {
+		c.Addr = ""
+	}
+	if fraction, ok := env.SamplerFraction(); ok {
+		c.Sampler.Fraction = fraction
+	}
+	if env.BSP.ScheduleDelay > 0 {
+		c.Traces.ExportConfig.BatchTimeout = env.BSP.ScheduleDelay
+	}
+	if env.BSP.ExportTimeout > 0 {
+		c.Traces.ExportConfig.ExportTimeout = env.BSP.ExportTimeout
+	}
+	if env.BSP.MaxQueueSize > 0 {
+		c.Traces.ExportConfig.MaxQueueSize = env.BSP.MaxQueueSize
+	}
+	if env.BSP.MaxExportBatchSize > 0 {
+		c.Traces.ExportConfig.MaxExportBatchSize = env.BSP.MaxExportBatchSize
+	}
+	if env.BLRP.ScheduleDelay > 0 {
+		c.Logs.ExportOption.BatchTimeout = env.BLRP.ScheduleDelay
+	}
+	if env.BLRP.MaxQueueSize > 0 {
+		c.Logs.ExportOption.QueueSize = env.BLRP.MaxQueueSize
+	}
+	if env.BLRP.MaxExportBatchSize > 0 {
+		c.Logs.ExportOption.BatchSize = env.BLRP.MaxExportBatchSize
+	}
+}
+
 var logModeMap = map[string]LogMode{
 	"disable":   LogModeDisable,   // do not print
 	"verbose":   LogModeOneLine,   // single line include body
@@ -16,6 +16,19 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	logsproto "go.opentelemetry.io/proto/otlp/logs/v1"
+	"gopkg.in/yaml.v3"
+
+	"trpc-system/go-opentelemetry/pkg/envconfig"
+	"trpc-system/go-opentelemetry/sdk/trace"
 )
 
 func TestLogMode_MarshalText(t *testing.T) {
@@ -43,3 +56,159 @@ func TestLogMode_MarshalText(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "http://collector:4318")
+	t.Setenv("OTEL_TRACES_SAMPLER", "traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.5")
+	t.Setenv("OTEL_BSP_MAX_QUEUE_SIZE", "100")
+	t.Setenv("OTEL_BLRP_SCHEDULE_DELAY", "1000")
+
+	cfg := DefaultConfig()
+	cfg.ApplyEnv(envconfig.Load())
+	assert.Equal(t, "", cfg.Addr)
+	assert.Equal(t, 0.5, cfg.Sampler.Fraction)
+	assert.Equal(t, 100, cfg.Traces.ExportConfig.MaxQueueSize)
+	assert.Equal(t, time.Second, cfg.Logs.ExportOption.BatchTimeout)
+
+	// the YAML decoded later takes precedence
+	require.Nil(t, yaml.Unmarshal([]byte("addr: localhost:4317\nsampler:\n  fraction: 0.1\n"), &cfg))
+	assert.Equal(t, "localhost:4317", cfg.Addr)
+	assert.Equal(t, 0.1, cfg.Sampler.Fraction)
+	assert.Equal(t, 100, cfg.Traces.ExportConfig.MaxQueueSize)
+}
+
+func TestSamplerConfig_TraceConfig(t *testing.T) {
+	c := SamplerConfig{
+		Fraction: 0.1,
+		SpecialFractions: []SpecialFraction{{
+			CalleeService:   "service1",
+			DefaultFraction: 1,
+			CalleeMethods:   []MethodFraction{{Method: "method1", Fraction: 0.1, RateLimit: 10}},
+			RateLimit:       100,
+		}},
+		SamplerServerAddr: "localhost:14941",
+		Adaptive:          AdaptiveSamplerConfig{Enabled: true, TargetSpansPerSecond: 100},
+	}
+	assert.Equal(t, trace.SamplerConfig{
+		Fraction: 0.1,
+		SpecialFractions: map[string]trace.SpecialFraction{
+			"service1": {
+				DefaultFraction: 1,
+				Methods:         map[string]trace.MethodFraction{"method1": {Fraction: 0.1, RateLimit: 10}},
+				RateLimit:       100,
+			},
+		},
+		SamplerServiceAddr: "localhost:14941",
+		Adaptive:           &trace.AdaptiveConfig{TargetSpansPerSecond: 100},
+	}, c.TraceConfig())
+	assert.Nil(t, SamplerConfig{}.TraceConfig().Adaptive)
+}
+
+func Test_specialFractions(t *testing.T) {
+	type args struct {
+		fractions []SpecialFraction
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]trace.SpecialFraction
+	}{
+		{
+			name: "test config",
+			args: args{fractions: []SpecialFraction{
+				{
+					CalleeService:   "service1",
+					DefaultFraction: 1,
+					CalleeMethods: []MethodFraction{
+						{
+							Method:    "method1",
+							Fraction:  0.1,
+							RateLimit: 10,
+						},
+					},
+					RateLimit: 100,
+				},
+			}},
+			want: map[string]trace.SpecialFraction{
+				"service1": {
+					DefaultFraction: 1,
+					Methods: map[string]trace.MethodFraction{
+						"method1": {
+							Fraction:  0.1,
+							RateLimit: 10,
+						},
+					},
+					RateLimit: 100,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, specialFractions(tt.args.fractions), "specialFractions(%v)",
+				tt.args.fractions)
+		})
+	}
+}
+
+func TestExporterConfig_Destination(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+traces:
+  exporters:
+    - name: errors
+      addr: https://errors:4318
+      tenant_id: archive
+      headers: {x-token: secret}
+      retry:
+        max_elapsed_time: 1m
+      batch:
+        max_queue_size: 100
+      filter:
+        error_only: true
+        attributes:
+          - key: env
+            value: prod
+logs:
+  exporters:
+    - name: backup
+      addr: backup:4317
+      tls:
+        enabled: true
+      retry:
+        disabled: true
+      filter:
+        min_level: warn
+`), &cfg))
+	require.Len(t, cfg.Traces.Exporters, 1)
+	require.Len(t, cfg.Logs.Exporters, 1)
+
+	span := cfg.Traces.Exporters[0].SpanDestination()
+	assert.Equal(t, "errors", span.Name)
+	assert.Equal(t, "archive", span.TenantID)
+	assert.Equal(t, map[string]string{"x-token": "secret"}, span.Headers)
+	assert.Nil(t, span.TLS)
+	require.NotNil(t, span.Retry)
+	assert.True(t, span.Retry.Enabled)
+	assert.Equal(t, time.Minute, span.Retry.MaxElapsedTime)
+	assert.Equal(t, time.Second, span.Retry.InitialInterval)
+	assert.Len(t, span.BatchOptions, 1)
+	failed := tracetest.SpanStub{
+		Attributes: []attribute.KeyValue{attribute.String("env", "prod")},
+		Status:     sdktrace.Status{Code: codes.Error},
+	}
+	assert.True(t, span.Filter(failed.Snapshot()))
+	failed.Attributes = nil
+	assert.False(t, span.Filter(failed.Snapshot()))
+
+	logs := cfg.Logs.Exporters[0].LogDestination()
+	assert.True(t, logs.TLS.Enabled)
+	assert.False(t, logs.Retry.Enabled)
+	assert.Empty(t, logs.BatchOptions)
+	assert.True(t, logs.Filter(&logsproto.LogRecord{SeverityText: "ERROR"}))
+	assert.False(t, logs.Filter(&logsproto.LogRecord{SeverityNumber: logsproto.SeverityNumber_SEVERITY_NUMBER_INFO}))
+
+	assert.Nil(t, ExporterConfig{Name: "all"}.SpanDestination().Filter)
+	assert.Nil(t, ExporterConfig{Name: "all"}.LogDestination().Retry)
+}
@@ -0,0 +1,234 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package fileconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"trpc-system/go-opentelemetry"
+	ecosystemotlp "trpc-system/go-opentelemetry/exporter/otlp"
+	"trpc-system/go-opentelemetry/exporter/retry"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
+)
+
+// otlpExporter the resolved OTLP exporter of a signal
+type otlpExporter struct {
+	http     bool
+	host     string
+	urlPath  string
+	headers  map[string]string
+	gzip     bool
+	timeout  time.Duration
+	tls      *tls.Config
+	insecure bool
+}
+
+// newOTLPExporter resolves the exporter, the URL path of http/protobuf defaults to signalPath
+func newOTLPExporter(e Exporter, signalPath string) (*otlpExporter, error) {
+	o := e.OTLP
+	if o == nil {
+		return nil, errors.New("fileconfig: only the otlp exporter is supported")
+	}
+	c := &otlpExporter{
+		headers: make(map[string]string, len(o.Headers)),
+		timeout: time.Duration(o.Timeout) * time.Millisecond,
+	}
+	switch o.Protocol {
+	case "", ProtocolGRPC:
+	case ProtocolHTTPProtobuf:
+		c.http = true
+	default:
+		return nil, fmt.Errorf("fileconfig: unsupported otlp protocol %q", o.Protocol)
+	}
+	switch o.Compression {
+	case "", "none":
+	case "gzip":
+		c.gzip = true
+	default:
+		return nil, fmt.Errorf("fileconfig: unsupported otlp compression %q", o.Compression)
+	}
+	for _, h := range o.Headers {
+		c.headers[h.Name] = h.Value
+	}
+	if err := c.parseEndpoint(o, signalPath); err != nil {
+		return nil, err
+	}
+	if c.insecure {
+		return c, nil
+	}
+	tlsCfg := tlsconfig.Config{Enabled: true, CAFile: o.Certificate, CertFile: o.ClientCertificate, KeyFile: o.ClientKey}
+	if o.TLS != nil && o.TLS.Enabled {
+		tlsCfg = *o.TLS
+	}
+	var err error
+	if c.tls, err = tlsconfig.New(tlsCfg, c.host); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseEndpoint parses the URL or the host:port of the endpoint, the latter is secure unless insecure
+func (c *otlpExporter) parseEndpoint(o *OTLP, signalPath string) error {
+	if o.Endpoint == "" {
+		return errors.New("fileconfig: otlp endpoint is required")
+	}
+	c.host, c.urlPath, c.insecure = o.Endpoint, signalPath, o.Insecure
+	if !strings.Contains(o.Endpoint, "://") {
+		return nil
+	}
+	u, err := url.Parse(o.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("fileconfig: invalid otlp endpoint %q", o.Endpoint)
+	}
+	c.host, c.insecure = u.Host, u.Scheme == "http"
+	if u.Path != "" && u.Path != "/" {
+		c.urlPath = u.Path
+	}
+	return nil
+}
+
+func (c *otlpExporter) newSpanExporter() (sdktrace.SpanExporter, error) {
+	var client otlptrace.Client
+	if c.http {
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(c.host),
+			otlptracehttp.WithURLPath(c.urlPath),
+			otlptracehttp.WithHeaders(c.headers),
+			otlptracehttp.WithRetry(otlptracehttp.RetryConfig(retryConfig())),
+		}
+		if c.insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(c.tls))
+		}
+		if c.gzip {
+			opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+		if c.timeout > 0 {
+			opts = append(opts, otlptracehttp.WithTimeout(c.timeout))
+		}
+		client = otlptracehttp.NewClient(opts...)
+	} else {
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(c.host),
+			otlptracegrpc.WithHeaders(c.headers),
+			otlptracegrpc.WithDialOption(grpc.WithDefaultCallOptions(
+				grpc.MaxCallSendMsgSize(opentelemetry.MaxSendMessageSize))),
+			otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(retryConfig())),
+		}
+		if c.insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(c.tls)))
+		}
+		if c.gzip {
+			opts = append(opts, otlptracegrpc.WithCompressor("gzip"))
+		}
+		if c.timeout > 0 {
+			opts = append(opts, otlptracegrpc.WithTimeout(c.timeout))
+		}
+		client = otlptracegrpc.NewClient(opts...)
+	}
+	return otlptrace.New(context.Background(), client)
+}
+
+func (c *otlpExporter) newMetricExporter() (sdkmetric.Exporter, error) {
+	if c.http {
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(c.host),
+			otlpmetrichttp.WithURLPath(c.urlPath),
+			otlpmetrichttp.WithHeaders(c.headers),
+			otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(retryConfig())),
+		}
+		if c.insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else {
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(c.tls))
+		}
+		if c.gzip {
+			opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+		}
+		if c.timeout > 0 {
+			opts = append(opts, otlpmetrichttp.WithTimeout(c.timeout))
+		}
+		return otlpmetrichttp.New(context.Background(), opts...)
+	}
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(c.host),
+		otlpmetricgrpc.WithHeaders(c.headers),
+		otlpmetricgrpc.WithDialOption(grpc.WithDefaultCallOptions(
+			grpc.MaxCallSendMsgSize(opentelemetry.MaxSendMessageSize))),
+		otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(retryConfig())),
+	}
+	if c.insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	} else {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(c.tls)))
+	}
+	if c.gzip {
+		opts = append(opts, otlpmetricgrpc.WithCompressor("gzip"))
+	}
+	if c.timeout > 0 {
+		opts = append(opts, otlpmetricgrpc.WithTimeout(c.timeout))
+	}
+	return otlpmetricgrpc.New(context.Background(), opts...)
+}
+
+// newLogExporter returns the log exporter of exporter/otlp, which supports grpc only
+func (c *otlpExporter) newLogExporter() (*ecosystemotlp.Exporter, error) {
+	if c.http {
+		return nil, fmt.Errorf("fileconfig: the log exporter supports grpc only")
+	}
+	opts := []ecosystemotlp.ExporterOption{
+		ecosystemotlp.WithAddress(c.host),
+		ecosystemotlp.WithHeaders(c.headers),
+		ecosystemotlp.WithRetryConfig(retry.DefaultConfig),
+		ecosystemotlp.WithTimeout(c.timeout),
+	}
+	if c.insecure {
+		opts = append(opts, ecosystemotlp.WithInsecure())
+	} else {
+		opts = append(opts, ecosystemotlp.WithTLSCredentials(credentials.NewTLS(c.tls)))
+	}
+	if c.gzip {
+		opts = append(opts, ecosystemotlp.WithCompressor("gzip"))
+	}
+	return ecosystemotlp.NewExporter(opts...)
+}
+
+// retryConfig the retry of the OTLP trace and metric exporters, the same as the log exporter
+func retryConfig() otlptracegrpc.RetryConfig {
+	return otlptracegrpc.RetryConfig{
+		Enabled:         true,
+		InitialInterval: retry.DefaultConfig.InitialInterval,
+		MaxInterval:     retry.DefaultConfig.MaxInterval,
+		MaxElapsedTime:  retry.DefaultConfig.MaxElapsedTime,
+	}
+}
@@ -0,0 +1,313 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package fileconfig loads the OpenTelemetry declarative configuration file and builds the SDK of this project,
+// the config.Config of the tRPC plugin is translated to the same model so that both formats are supported.
+// The subset of file format 0.3 is supported, plus the extensions of this project marked in the model.
+package fileconfig
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"trpc-system/go-opentelemetry/api/log"
+	"trpc-system/go-opentelemetry/config"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
+)
+
+// FileFormat the file format written by FromConfig, the files of 0.x are accepted
+const FileFormat = "0.3"
+
+// protocols of the OTLP exporter
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+)
+
+// Configuration the declarative configuration, the absent providers are not set up
+type Configuration struct {
+	FileFormat string `yaml:"file_format"`
+	// Disabled no provider is built
+	Disabled       bool            `yaml:"disabled"`
+	Resource       *Resource       `yaml:"resource"`
+	Propagator     *Propagator     `yaml:"propagator"`
+	TracerProvider *TracerProvider `yaml:"tracer_provider"`
+	MeterProvider  *MeterProvider  `yaml:"meter_provider"`
+	LoggerProvider *LoggerProvider `yaml:"logger_provider"`
+}
+
+// Resource the resource shared by traces, metrics and logs
+type Resource struct {
+	Attributes []Attribute `yaml:"attributes"`
+	SchemaURL  string      `yaml:"schema_url"`
+	// Detectors extension, the detectors of sdk/resource run in order: host, process, container, kubernetes
+	// and quota, none by default
+	Detectors []string `yaml:"detectors"`
+	// PodInfoDir extension, the downward API volume of the kubernetes detector, default /etc/podinfo
+	PodInfoDir string `yaml:"pod_info_dir"`
+}
+
+// Attribute the resource attribute, type is string by default, or bool, int, double, string_array,
+// bool_array, int_array and double_array
+type Attribute struct {
+	Name  string      `yaml:"name"`
+	Value interface{} `yaml:"value"`
+	Type  string      `yaml:"type"`
+}
+
+// Propagator the propagators of sdk/propagators
+type Propagator struct {
+	// Composite the formats extracted in order, also injected if Inject is empty
+	Composite []string `yaml:"composite"`
+	// Inject extension, the formats injected
+	Inject []string `yaml:"inject"`
+}
+
+// TracerProvider the tracer provider
+type TracerProvider struct {
+	Processors []SpanProcessor `yaml:"processors"`
+	Limits     *SpanLimits     `yaml:"limits"`
+	// Sampler default parent_based with always_on root
+	Sampler *Sampler `yaml:"sampler"`
+	// DeferredSample extension, the unsampled spans are kept after they end by the error or the duration
+	DeferredSample *DeferredSample `yaml:"deferred_sample"`
+}
+
+// SpanProcessor one of the span processors
+type SpanProcessor struct {
+	Batch  *BatchProcessor  `yaml:"batch"`
+	Simple *SimpleProcessor `yaml:"simple"`
+}
+
+// BatchProcessor the batch processor of spans or log records, the durations are in milliseconds
+type BatchProcessor struct {
+	ScheduleDelay      int      `yaml:"schedule_delay"`
+	ExportTimeout      int      `yaml:"export_timeout"`
+	MaxQueueSize       int      `yaml:"max_queue_size"`
+	MaxExportBatchSize int      `yaml:"max_export_batch_size"`
+	Exporter           Exporter `yaml:"exporter"`
+}
+
+// SimpleProcessor the processor exporting each span as it ends
+type SimpleProcessor struct {
+	Exporter Exporter `yaml:"exporter"`
+}
+
+// SpanLimits the limits of the spans, zero means the default
+type SpanLimits struct {
+	AttributeValueLengthLimit int `yaml:"attribute_value_length_limit"`
+	AttributeCountLimit       int `yaml:"attribute_count_limit"`
+	EventCountLimit           int `yaml:"event_count_limit"`
+	LinkCountLimit            int `yaml:"link_count_limit"`
+	EventAttributeCountLimit  int `yaml:"event_attribute_count_limit"`
+	LinkAttributeCountLimit   int `yaml:"link_attribute_count_limit"`
+}
+
+// Sampler one of the samplers, always_on and always_off have no properties
+type Sampler struct {
+	AlwaysOn          bool
+	AlwaysOff         bool
+	TraceIDRatioBased *TraceIDRatioBased
+	ParentBased       *ParentBased
+	// Dyeing extension, the sampler of sdk/trace with the special fractions, the dyeing and the remote config
+	Dyeing *DyeingSampler
+}
+
+// UnmarshalYAML decodes the sampler of the only key, whose value may be null
+func (s *Sampler) UnmarshalYAML(value *yaml.Node) error {
+	return decodeOneOf(value, "sampler", map[string]func(*yaml.Node) error{
+		"always_on":  func(*yaml.Node) error { s.AlwaysOn = true; return nil },
+		"always_off": func(*yaml.Node) error { s.AlwaysOff = true; return nil },
+		"trace_id_ratio_based": func(n *yaml.Node) error {
+			s.TraceIDRatioBased = &TraceIDRatioBased{}
+			return n.Decode(s.TraceIDRatioBased)
+		},
+		"parent_based": func(n *yaml.Node) error {
+			s.ParentBased = &ParentBased{}
+			return n.Decode(s.ParentBased)
+		},
+		"dyeing": func(n *yaml.Node) error {
+			s.Dyeing = &DyeingSampler{}
+			return n.Decode(s.Dyeing)
+		},
+	})
+}
+
+// TraceIDRatioBased the sampler of the trace ID ratio
+type TraceIDRatioBased struct {
+	Ratio float64 `yaml:"ratio"`
+}
+
+// ParentBased the sampler by the parent, root is always_on by default, the others are of the spec defaults
+type ParentBased struct {
+	Root                   *Sampler `yaml:"root"`
+	RemoteParentSampled    *Sampler `yaml:"remote_parent_sampled"`
+	RemoteParentNotSampled *Sampler `yaml:"remote_parent_not_sampled"`
+	LocalParentSampled     *Sampler `yaml:"local_parent_sampled"`
+	LocalParentNotSampled  *Sampler `yaml:"local_parent_not_sampled"`
+}
+
+// DyeingSampler the sampler of trace.NewSampler
+type DyeingSampler struct {
+	TenantID             string `yaml:"tenant_id"`
+	config.SamplerConfig `yaml:",inline"`
+}
+
+// DeferredSample the deferred sampling of trace.NewDeferredSampler
+type DeferredSample struct {
+	Enabled     bool `yaml:"enabled"`
+	SampleError bool `yaml:"sample_error"`
+	// SampleSlowDuration in milliseconds, the spans slower are kept
+	SampleSlowDuration int `yaml:"sample_slow_duration"`
+}
+
+// MeterProvider the meter provider
+type MeterProvider struct {
+	Readers []MetricReader `yaml:"readers"`
+	Views   []View         `yaml:"views"`
+}
+
+// MetricReader one of the metric readers, only periodic is supported
+type MetricReader struct {
+	Periodic *PeriodicReader `yaml:"periodic"`
+}
+
+// PeriodicReader the periodic reader, the durations are in milliseconds
+type PeriodicReader struct {
+	Interval int      `yaml:"interval"`
+	Timeout  int      `yaml:"timeout"`
+	Exporter Exporter `yaml:"exporter"`
+}
+
+// View the view of the instruments matched by the selector
+type View struct {
+	Selector ViewSelector `yaml:"selector"`
+	Stream   ViewStream   `yaml:"stream"`
+}
+
+// ViewSelector the criteria of the instruments, instrument_name supports the * and ? wildcards
+type ViewSelector struct {
+	InstrumentName string `yaml:"instrument_name"`
+	// InstrumentType counter, up_down_counter, histogram, observable_counter, observable_up_down_counter
+	// or observable_gauge
+	InstrumentType string `yaml:"instrument_type"`
+	Unit           string `yaml:"unit"`
+	MeterName      string `yaml:"meter_name"`
+	MeterVersion   string `yaml:"meter_version"`
+	MeterSchemaURL string `yaml:"meter_schema_url"`
+}
+
+// ViewStream the stream of the matched instruments, the zero fields are taken from the instrument
+type ViewStream struct {
+	Name          string          `yaml:"name"`
+	Description   string          `yaml:"description"`
+	Aggregation   *Aggregation    `yaml:"aggregation"`
+	AttributeKeys *IncludeExclude `yaml:"attribute_keys"`
+}
+
+// IncludeExclude the keys included and excluded, all are included if included is empty
+type IncludeExclude struct {
+	Included []string `yaml:"included"`
+	Excluded []string `yaml:"excluded"`
+}
+
+// Aggregation one of the aggregations, only explicit_bucket_histogram has properties
+type Aggregation struct {
+	Default                 bool
+	Drop                    bool
+	Sum                     bool
+	LastValue               bool
+	ExplicitBucketHistogram *ExplicitBucketHistogram
+}
+
+// UnmarshalYAML decodes the aggregation of the only key, whose value may be null
+func (a *Aggregation) UnmarshalYAML(value *yaml.Node) error {
+	return decodeOneOf(value, "aggregation", map[string]func(*yaml.Node) error{
+		"default":    func(*yaml.Node) error { a.Default = true; return nil },
+		"drop":       func(*yaml.Node) error { a.Drop = true; return nil },
+		"sum":        func(*yaml.Node) error { a.Sum = true; return nil },
+		"last_value": func(*yaml.Node) error { a.LastValue = true; return nil },
+		"explicit_bucket_histogram": func(n *yaml.Node) error {
+			a.ExplicitBucketHistogram = &ExplicitBucketHistogram{}
+			return n.Decode(a.ExplicitBucketHistogram)
+		},
+	})
+}
+
+// ExplicitBucketHistogram the histogram of the boundaries, the default boundaries if empty
+type ExplicitBucketHistogram struct {
+	Boundaries []float64 `yaml:"boundaries"`
+	// RecordMinMax default true
+	RecordMinMax *bool `yaml:"record_min_max"`
+}
+
+// LoggerProvider the logger provider of sdk/log, which has exactly one batch processor
+type LoggerProvider struct {
+	Processors []LogRecordProcessor `yaml:"processors"`
+	// Level extension, the lowest level exported, default info
+	Level log.Level `yaml:"level"`
+	// EnableSampler extension, only the logs of the sampled spans are exported
+	EnableSampler bool `yaml:"enable_sampler"`
+	// EnableSamplerError extension, works with EnableSampler, the error logs are exported as well
+	EnableSamplerError bool `yaml:"enable_sampler_error"`
+}
+
+// LogRecordProcessor the log record processor, only batch is supported
+type LogRecordProcessor struct {
+	Batch *BatchProcessor `yaml:"batch"`
+}
+
+// Exporter the exporter of spans, metrics or log records, only otlp is supported
+type Exporter struct {
+	OTLP *OTLP `yaml:"otlp"`
+}
+
+// OTLP the OTLP exporter. The endpoint of http/protobuf is the full URL of the signal, e.g.
+// http://localhost:4318/v1/traces, the one of grpc is the URL or the host:port, e.g. http://localhost:4317.
+// TLS is used for the https endpoint, and for the grpc endpoint without scheme unless insecure.
+type OTLP struct {
+	// Protocol grpc or http/protobuf, logs support grpc only
+	Protocol          string      `yaml:"protocol"`
+	Endpoint          string      `yaml:"endpoint"`
+	Certificate       string      `yaml:"certificate"`
+	ClientKey         string      `yaml:"client_key"`
+	ClientCertificate string      `yaml:"client_certificate"`
+	Headers           []NameValue `yaml:"headers"`
+	Compression       string      `yaml:"compression"`
+	// Timeout in milliseconds, default 10000
+	Timeout  int  `yaml:"timeout"`
+	Insecure bool `yaml:"insecure"`
+	// TLS extension, the TLS of pkg/tlsconfig with the server name and the reload, it takes precedence over
+	// the certificate files if enabled
+	TLS *tlsconfig.Config `yaml:"tls"`
+}
+
+// NameValue the name and the value of a header
+type NameValue struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// decodeOneOf decodes the mapping of the only key by the decoder of the key
+func decodeOneOf(value *yaml.Node, kind string, decoders map[string]func(*yaml.Node) error) error {
+	if value.Kind != yaml.MappingNode || len(value.Content) != 2 {
+		return fmt.Errorf("%s of line %d must have exactly one key", kind, value.Line)
+	}
+	key, v := value.Content[0].Value, value.Content[1]
+	decode, ok := decoders[key]
+	if !ok {
+		return fmt.Errorf("unsupported %s %q of line %d", kind, key, value.Line)
+	}
+	return decode(v)
+}
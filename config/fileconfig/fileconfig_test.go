@@ -0,0 +1,279 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package fileconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+
+	"trpc-system/go-opentelemetry/api/log"
+)
+
+const testConfig = `
+file_format: "0.3"
+resource:
+  schema_url: https://opentelemetry.io/schemas/1.7.0
+  attributes:
+    - name: service.name
+      value: ${SERVICE_NAME}
+    - name: service.version
+      value: ${SERVICE_VERSION:-1.0.0}
+    - name: replicas
+      value: 3
+      type: int
+    - name: zones
+      value: [a, b]
+      type: string_array
+    - name: price
+      value: $${not substituted}
+propagator:
+  composite: [tracecontext, b3]
+  inject: [tracecontext]
+tracer_provider:
+  processors:
+    - batch:
+        schedule_delay: 100
+        max_export_batch_size: 10
+        exporter:
+          otlp:
+            protocol: http/protobuf
+            endpoint: ${ENDPOINT}/v1/traces
+            headers:
+              - name: X-Tps-TenantID
+                value: tenant
+  limits:
+    attribute_count_limit: 16
+  sampler:
+    parent_based:
+      root:
+        trace_id_ratio_based:
+          ratio: 1
+      remote_parent_not_sampled:
+        always_on:
+  deferred_sample:
+    enabled: true
+    sample_error: true
+meter_provider:
+  readers:
+    - periodic:
+        interval: 60000
+        exporter:
+          otlp:
+            protocol: http/protobuf
+            endpoint: ${ENDPOINT}
+            compression: gzip
+  views:
+    - selector:
+        instrument_name: rpc.*
+        instrument_type: histogram
+      stream:
+        aggregation:
+          explicit_bucket_histogram:
+            boundaries: [0.1, 1]
+            record_min_max: false
+        attribute_keys:
+          excluded: [peer]
+    - selector:
+        instrument_name: debug.counter
+      stream:
+        aggregation:
+          drop:
+logger_provider:
+  level: warn
+  processors:
+    - batch:
+        exporter:
+          otlp:
+            endpoint: localhost:4317
+            insecure: true
+`
+
+func TestParse(t *testing.T) {
+	t.Setenv("SERVICE_NAME", "app.server")
+	t.Setenv("ENDPOINT", "http://localhost:4318")
+
+	c, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+	assert.Equal(t, []Attribute{
+		{Name: "service.name", Value: "app.server"},
+		{Name: "service.version", Value: "1.0.0"},
+		{Name: "replicas", Value: 3, Type: "int"},
+		{Name: "zones", Value: []interface{}{"a", "b"}, Type: "string_array"},
+		{Name: "price", Value: "${not substituted}"},
+	}, c.Resource.Attributes)
+	assert.Equal(t, "http://localhost:4318/v1/traces", c.TracerProvider.Processors[0].Batch.Exporter.OTLP.Endpoint)
+
+	sampler := c.TracerProvider.Sampler.ParentBased
+	require.NotNil(t, sampler)
+	assert.Equal(t, &TraceIDRatioBased{Ratio: 1}, sampler.Root.TraceIDRatioBased)
+	assert.True(t, sampler.RemoteParentNotSampled.AlwaysOn)
+	assert.Nil(t, sampler.LocalParentSampled)
+
+	views := c.MeterProvider.Views
+	require.Len(t, views, 2)
+	assert.Equal(t, []float64{0.1, 1}, views[0].Stream.Aggregation.ExplicitBucketHistogram.Boundaries)
+	assert.True(t, views[1].Stream.Aggregation.Drop)
+	assert.Equal(t, log.Level("WARN"), c.LoggerProvider.Level)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range []string{
+		"tracer_provider: {}",
+		`file_format: "1.0"`,
+		"file_format: \"0.3\"\ntracer_provider:\n  sampler:\n    jaeger_remote:",
+		"file_format: \"0.3\"\ntracer_provider:\n  sampler:\n    always_on:\n    always_off:",
+		"file_format: \"0.3\"\nmeter_provider:\n  views:\n    - stream:\n        aggregation:\n          base2_exponential_bucket_histogram:",
+	} {
+		_, err := Parse([]byte(data))
+		assert.Error(t, err, data)
+	}
+	_, err := ParseFile("not_exist.yaml")
+	assert.Error(t, err)
+}
+
+// collector records the paths and the tenant headers of the OTLP/HTTP requests
+type collector struct {
+	mu       sync.Mutex
+	requests map[string]string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[r.URL.Path] = r.Header.Get("X-Tps-TenantID")
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestNew(t *testing.T) {
+	coll := &collector{requests: make(map[string]string)}
+	srv := httptest.NewServer(coll)
+	defer srv.Close()
+	t.Setenv("SERVICE_NAME", "app.server")
+	t.Setenv("ENDPOINT", srv.URL)
+	c, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	sdk, err := New(ctx, c)
+	require.NoError(t, err)
+	require.NotNil(t, sdk.TracerProvider)
+	require.NotNil(t, sdk.MeterProvider)
+	require.NotNil(t, sdk.Logger)
+	assert.Contains(t, sdk.Propagator.Fields(), "traceparent")
+
+	_, span := sdk.TracerProvider.Tracer("test").Start(ctx, "span")
+	span.End()
+	counter, err := sdk.MeterProvider.Meter("test").Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+	require.NoError(t, sdk.TracerProvider.ForceFlush(ctx))
+	require.NoError(t, sdk.MeterProvider.ForceFlush(ctx))
+
+	coll.mu.Lock()
+	assert.Equal(t, map[string]string{"/v1/traces": "tenant", "/v1/metrics": ""}, coll.requests)
+	coll.mu.Unlock()
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_ = sdk.Shutdown(shutdownCtx)
+}
+
+func TestNew_Resource(t *testing.T) {
+	res, err := newResource(context.Background(), &Resource{
+		Attributes: []Attribute{
+			{Name: "service.name", Value: "app.server"},
+			{Name: "ratio", Value: 1, Type: "double"},
+			{Name: "flags", Value: []interface{}{true, false}, Type: "bool_array"},
+		},
+		Detectors: []string{"process"},
+	})
+	require.NoError(t, err)
+	attrs := attribute.NewSet(res.Attributes()...)
+	v, _ := attrs.Value("service.name")
+	assert.Equal(t, "app.server", v.AsString())
+	v, _ = attrs.Value("ratio")
+	assert.Equal(t, float64(1), v.AsFloat64())
+	v, _ = attrs.Value("flags")
+	assert.Equal(t, []bool{true, false}, v.AsBoolSlice())
+	assert.True(t, attrs.HasValue("process.pid"))
+
+	for _, r := range []*Resource{
+		{Detectors: []string{"unknown"}},
+		{Attributes: []Attribute{{Name: "n", Value: "1", Type: "int"}}},
+		{Attributes: []Attribute{{Name: "n", Value: []interface{}{"a"}, Type: "int_array"}}},
+		{Attributes: []Attribute{{Name: "n", Value: 1, Type: "map"}}},
+	} {
+		_, err := newResource(context.Background(), r)
+		assert.Error(t, err)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	otlp := Exporter{OTLP: &OTLP{Endpoint: "localhost:4317", Insecure: true}}
+	for _, c := range []*Configuration{
+		{TracerProvider: &TracerProvider{Processors: []SpanProcessor{{}}}},
+		{TracerProvider: &TracerProvider{Processors: []SpanProcessor{{Batch: &BatchProcessor{}}}}},
+		{TracerProvider: &TracerProvider{Processors: []SpanProcessor{{Batch: &BatchProcessor{
+			Exporter: Exporter{OTLP: &OTLP{Endpoint: "ftp://localhost"}}}}}}},
+		{TracerProvider: &TracerProvider{Processors: []SpanProcessor{{Simple: &SimpleProcessor{
+			Exporter: Exporter{OTLP: &OTLP{Endpoint: "localhost:4317", Protocol: "http/json"}}}}}}},
+		{MeterProvider: &MeterProvider{Readers: []MetricReader{{}}}},
+		{MeterProvider: &MeterProvider{Views: []View{{}}}},
+		{MeterProvider: &MeterProvider{Views: []View{{Selector: ViewSelector{InstrumentType: "gauge"}}}}},
+		{MeterProvider: &MeterProvider{Views: []View{{Selector: ViewSelector{InstrumentName: "rpc.*"},
+			Stream: ViewStream{Name: "rpc"}}}}},
+		{LoggerProvider: &LoggerProvider{}},
+		{LoggerProvider: &LoggerProvider{Level: "verbose", Processors: []LogRecordProcessor{{
+			Batch: &BatchProcessor{Exporter: otlp}}}}},
+		{LoggerProvider: &LoggerProvider{Processors: []LogRecordProcessor{{Batch: &BatchProcessor{
+			Exporter: Exporter{OTLP: &OTLP{Endpoint: "http://localhost:4318", Protocol: ProtocolHTTPProtobuf}}}}}}},
+		{Propagator: &Propagator{Composite: []string{"xray"}}},
+	} {
+		_, err := New(context.Background(), c)
+		assert.Error(t, err)
+	}
+
+	sdk, err := New(context.Background(), &Configuration{Disabled: true, TracerProvider: &TracerProvider{}})
+	require.NoError(t, err)
+	assert.Nil(t, sdk.TracerProvider)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	for _, tt := range []struct {
+		otlp     OTLP
+		host     string
+		urlPath  string
+		insecure bool
+	}{
+		{OTLP{Endpoint: "localhost:4317"}, "localhost:4317", "/v1/traces", false},
+		{OTLP{Endpoint: "localhost:4317", Insecure: true}, "localhost:4317", "/v1/traces", true},
+		{OTLP{Endpoint: "http://localhost:4318/"}, "localhost:4318", "/v1/traces", true},
+		{OTLP{Endpoint: "https://collector/otlp/traces"}, "collector", "/otlp/traces", false},
+	} {
+		c, err := newOTLPExporter(Exporter{OTLP: &tt.otlp}, "/v1/traces")
+		require.NoError(t, err)
+		assert.Equal(t, tt.host, c.host)
+		assert.Equal(t, tt.urlPath, c.urlPath)
+		assert.Equal(t, tt.insecure, c.insecure)
+		assert.Equal(t, tt.insecure, c.tls == nil)
+	}
+}
@@ -0,0 +1,61 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package fileconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envRef matches $$ and the references ${VAR}, ${env:VAR} and ${VAR:-default}
+var envRef = regexp.MustCompile(`\$\$|\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ParseFile reads and parses the configuration file
+func ParseFile(path string) (*Configuration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fileconfig: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses the configuration after the environment variables are substituted,
+// the unset variables without default are replaced by empty strings and $$ is the escaped $
+func Parse(data []byte) (*Configuration, error) {
+	var c Configuration
+	if err := yaml.Unmarshal([]byte(substituteEnv(string(data))), &c); err != nil {
+		return nil, fmt.Errorf("fileconfig: %w", err)
+	}
+	if !strings.HasPrefix(c.FileFormat, "0.") {
+		return nil, fmt.Errorf("fileconfig: unsupported file_format %q", c.FileFormat)
+	}
+	return &c, nil
+}
+
+func substituteEnv(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		m := envRef.FindStringSubmatch(ref)
+		if v, ok := os.LookupEnv(m[1]); ok && v != "" {
+			return v
+		}
+		return m[2]
+	})
+}
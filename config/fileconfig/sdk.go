@@ -0,0 +1,601 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package fileconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+
+	"trpc-system/go-opentelemetry"
+	"trpc-system/go-opentelemetry/api"
+	apilog "trpc-system/go-opentelemetry/api/log"
+	sdklog "trpc-system/go-opentelemetry/sdk/log"
+	"trpc-system/go-opentelemetry/sdk/propagators"
+	ecosystemresource "trpc-system/go-opentelemetry/sdk/resource"
+	"trpc-system/go-opentelemetry/sdk/trace"
+)
+
+// SDK the providers built from the configuration, the providers absent from the configuration are nil
+type SDK struct {
+	TracerProvider *sdktrace.TracerProvider
+	MeterProvider  *sdkmetric.MeterProvider
+	Logger         *sdklog.Logger
+	Propagator     propagation.TextMapPropagator
+	// samplers the dyeing samplers, closed on shutdown
+	samplers []*trace.Sampler
+}
+
+// Option the option of New
+type Option func(*options)
+
+type options struct {
+	metricViews []sdkmetric.View
+}
+
+// WithMetricViews adds the views of the meter provider after the views of the configuration
+func WithMetricViews(views ...sdkmetric.View) Option {
+	return func(o *options) {
+		o.metricViews = append(o.metricViews, views...)
+	}
+}
+
+// New builds the SDK of the configuration, the providers are not installed until Install is called
+func New(ctx context.Context, c *Configuration, opts ...Option) (sdk *SDK, err error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	s := &SDK{}
+	if c.Disabled {
+		return s, nil
+	}
+	defer func() {
+		if err != nil {
+			_ = s.Shutdown(ctx)
+		}
+	}()
+	res, err := newResource(ctx, c.Resource)
+	if err != nil {
+		return nil, err
+	}
+	if s.Propagator, err = newPropagator(c.Propagator); err != nil {
+		return nil, err
+	}
+	if c.TracerProvider != nil {
+		if s.TracerProvider, err = s.newTracerProvider(c.TracerProvider, res); err != nil {
+			return nil, err
+		}
+	}
+	if c.MeterProvider != nil {
+		if s.MeterProvider, err = newMeterProvider(ctx, c.MeterProvider, res, o.metricViews); err != nil {
+			return nil, err
+		}
+	}
+	if c.LoggerProvider != nil {
+		if s.Logger, err = newLogger(c.LoggerProvider, res); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Install sets the providers as the globals of otel and api/log,
+// the tracer provider is also used by opentelemetry.Start and opentelemetry.WithSpan
+func (s *SDK) Install() {
+	if s.TracerProvider != nil {
+		opentelemetry.SetTracerProvider(s.TracerProvider)
+	}
+	if s.MeterProvider != nil {
+		otel.SetMeterProvider(s.MeterProvider)
+	}
+	if s.Propagator != nil {
+		otel.SetTextMapPropagator(s.Propagator)
+	}
+	if s.Logger != nil {
+		apilog.SetGlobalLogger(s.Logger)
+	}
+}
+
+// Shutdown flushes and shuts down the providers, the first error is returned
+func (s *SDK) Shutdown(ctx context.Context) error {
+	var errs []error
+	if s.TracerProvider != nil {
+		errs = append(errs, s.TracerProvider.Shutdown(ctx))
+	}
+	if s.MeterProvider != nil {
+		errs = append(errs, s.MeterProvider.Shutdown(ctx))
+	}
+	if s.Logger != nil {
+		errs = append(errs, s.Logger.Shutdown(ctx))
+	}
+	for _, sampler := range s.samplers {
+		errs = append(errs, sampler.Close())
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newResource returns the resource merged in ascending precedence from the default resource of otel,
+// the detectors and the attributes
+func newResource(ctx context.Context, c *Resource) (*resource.Resource, error) {
+	res := ecosystemresource.Merge(resource.Default(),
+		resource.NewSchemaless(semconv.TelemetrySDKNameKey.String(api.OpenTelemetryName)))
+	if c == nil {
+		return res, nil
+	}
+	if len(c.Detectors) > 0 {
+		detectors, err := ecosystemresource.NamedDetectors(c.Detectors, c.PodInfoDir)
+		if err != nil {
+			return nil, err
+		}
+		res = ecosystemresource.Merge(res, ecosystemresource.Detect(ctx, detectors...))
+	}
+	kvs := make([]attribute.KeyValue, 0, len(c.Attributes))
+	for _, a := range c.Attributes {
+		kv, err := a.keyValue()
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, kv)
+	}
+	res = ecosystemresource.Merge(res, resource.NewSchemaless(kvs...))
+	if c.SchemaURL != "" {
+		res = resource.NewWithAttributes(c.SchemaURL, res.Attributes()...)
+	}
+	return res, nil
+}
+
+// keyValue returns the attribute of the type, the value of string is formatted if not a string
+func (a Attribute) keyValue() (attribute.KeyValue, error) {
+	key := attribute.Key(a.Name)
+	invalid := fmt.Errorf("fileconfig: invalid %s value %v of resource attribute %q", a.Type, a.Value, a.Name)
+	switch a.Type {
+	case "", "string":
+		return key.String(fmt.Sprint(a.Value)), nil
+	case "bool":
+		if v, ok := a.Value.(bool); ok {
+			return key.Bool(v), nil
+		}
+	case "int":
+		if v, ok := a.Value.(int); ok {
+			return key.Int(v), nil
+		}
+	case "double":
+		if v, ok := toFloat(a.Value); ok {
+			return key.Float64(v), nil
+		}
+	case "string_array", "bool_array", "int_array", "double_array":
+		values, ok := a.Value.([]interface{})
+		if !ok {
+			return attribute.KeyValue{}, invalid
+		}
+		return arrayKeyValue(key, strings.TrimSuffix(a.Type, "_array"), values, invalid)
+	default:
+		return attribute.KeyValue{}, fmt.Errorf("fileconfig: unknown type %q of resource attribute %q", a.Type, a.Name)
+	}
+	return attribute.KeyValue{}, invalid
+}
+
+func arrayKeyValue(key attribute.Key, typ string, values []interface{}, invalid error) (attribute.KeyValue, error) {
+	var (
+		strs   []string
+		bools  []bool
+		ints   []int
+		floats []float64
+	)
+	for _, value := range values {
+		var ok bool
+		switch typ {
+		case "string":
+			strs, ok = append(strs, fmt.Sprint(value)), true
+		case "bool":
+			var v bool
+			v, ok = value.(bool)
+			bools = append(bools, v)
+		case "int":
+			var v int
+			v, ok = value.(int)
+			ints = append(ints, v)
+		default:
+			var v float64
+			v, ok = toFloat(value)
+			floats = append(floats, v)
+		}
+		if !ok {
+			return attribute.KeyValue{}, invalid
+		}
+	}
+	switch typ {
+	case "string":
+		return key.StringSlice(strs), nil
+	case "bool":
+		return key.BoolSlice(bools), nil
+	case "int":
+		return key.IntSlice(ints), nil
+	}
+	return key.Float64Slice(floats), nil
+}
+
+// toFloat accepts the integers of YAML as doubles
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func newPropagator(c *Propagator) (propagation.TextMapPropagator, error) {
+	if c == nil || (len(c.Composite) == 0 && len(c.Inject) == 0) {
+		return nil, nil
+	}
+	extract := c.Composite
+	if len(extract) == 0 {
+		extract = []string{propagators.NameTraceContext, propagators.NameBaggage}
+	}
+	return propagators.New(extract, c.Inject)
+}
+
+func (s *SDK) newTracerProvider(c *TracerProvider, res *resource.Resource) (*sdktrace.TracerProvider, error) {
+	var deferred trace.DeferredSampleConfig
+	if d := c.DeferredSample; d != nil {
+		deferred = trace.DeferredSampleConfig{
+			Enabled:            d.Enabled,
+			SampleError:        d.SampleError,
+			SampleSlowDuration: time.Duration(d.SampleSlowDuration) * time.Millisecond,
+		}
+	}
+	sampler, err := s.newSampler(c.Sampler, deferred.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithSampler(sampler), sdktrace.WithResource(res)}
+	if c.Limits != nil {
+		opts = append(opts, sdktrace.WithRawSpanLimits(c.Limits.spanLimits()))
+	}
+	deferredSampler := trace.NewDeferredSampler(deferred)
+	var processors []sdktrace.SpanProcessor
+	for _, p := range c.Processors {
+		sp, err := s.newSpanProcessor(p)
+		if err != nil {
+			for _, sp := range processors {
+				_ = sp.Shutdown(context.Background())
+			}
+			return nil, err
+		}
+		processors = append(processors, trace.NewDeferredSampleProcessor(sp, deferredSampler))
+	}
+	for _, sp := range processors {
+		opts = append(opts, sdktrace.WithSpanProcessor(sp))
+	}
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+func (s *SDK) newSpanProcessor(p SpanProcessor) (sdktrace.SpanProcessor, error) {
+	if p.Simple != nil {
+		exp, err := newSpanExporter(p.Simple.Exporter)
+		if err != nil {
+			return nil, err
+		}
+		return sdktrace.NewSimpleSpanProcessor(exp), nil
+	}
+	if p.Batch == nil {
+		return nil, errors.New("fileconfig: span processor must be batch or simple")
+	}
+	exp, err := newSpanExporter(p.Batch.Exporter)
+	if err != nil {
+		return nil, err
+	}
+	var opts []trace.BatchSpanProcessorOption
+	for _, sampler := range s.samplers {
+		if sampler.AdaptiveEnabled() {
+			opts = append(opts, trace.WithExportObserver(sampler.ObserveExportedSpan))
+		}
+	}
+	b := p.Batch
+	if b.ScheduleDelay > 0 {
+		opts = append(opts, trace.WithBatchTimeout(time.Duration(b.ScheduleDelay)*time.Millisecond))
+	}
+	if b.ExportTimeout > 0 {
+		opts = append(opts, trace.WithExportTimeout(time.Duration(b.ExportTimeout)*time.Millisecond))
+	}
+	if b.MaxQueueSize > 0 {
+		opts = append(opts, trace.WithMaxQueueSize(b.MaxQueueSize))
+	}
+	if b.MaxExportBatchSize > 0 {
+		opts = append(opts, trace.WithMaxExportBatchSize(b.MaxExportBatchSize))
+	}
+	return trace.NewBatchSpanProcessor(exp, opts...), nil
+}
+
+func newSpanExporter(e Exporter) (sdktrace.SpanExporter, error) {
+	c, err := newOTLPExporter(e, "/v1/traces")
+	if err != nil {
+		return nil, err
+	}
+	return c.newSpanExporter()
+}
+
+// newSampler returns the sampler, the unsampled spans of the dyeing sampler are recorded for the deferred sampling
+func (s *SDK) newSampler(c *Sampler, deferred bool) (sdktrace.Sampler, error) {
+	switch {
+	case c == nil:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case c.AlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case c.AlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case c.TraceIDRatioBased != nil:
+		return sdktrace.TraceIDRatioBased(c.TraceIDRatioBased.Ratio), nil
+	case c.ParentBased != nil:
+		return s.newParentBasedSampler(c.ParentBased, deferred)
+	case c.Dyeing != nil:
+		tenantID := c.Dyeing.TenantID
+		if tenantID == "" {
+			tenantID = opentelemetry.DefaultTenantID
+		}
+		sampler := trace.NewSampler(tenantID, c.Dyeing.TraceConfig(), func(o *trace.SamplerOptions) {
+			if deferred {
+				o.DefaultSamplingDecision = sdktrace.RecordOnly
+			}
+		})
+		if ts, ok := sampler.(*trace.Sampler); ok {
+			s.samplers = append(s.samplers, ts)
+		}
+		return sampler, nil
+	}
+	return nil, errors.New("fileconfig: empty sampler")
+}
+
+func (s *SDK) newParentBasedSampler(c *ParentBased, deferred bool) (sdktrace.Sampler, error) {
+	root := sdktrace.AlwaysSample()
+	if c.Root != nil {
+		var err error
+		if root, err = s.newSampler(c.Root, deferred); err != nil {
+			return nil, err
+		}
+	}
+	var opts []sdktrace.ParentBasedSamplerOption
+	for _, p := range []struct {
+		sampler *Sampler
+		option  func(sdktrace.Sampler) sdktrace.ParentBasedSamplerOption
+	}{
+		{c.RemoteParentSampled, sdktrace.WithRemoteParentSampled},
+		{c.RemoteParentNotSampled, sdktrace.WithRemoteParentNotSampled},
+		{c.LocalParentSampled, sdktrace.WithLocalParentSampled},
+		{c.LocalParentNotSampled, sdktrace.WithLocalParentNotSampled},
+	} {
+		if p.sampler == nil {
+			continue
+		}
+		sampler, err := s.newSampler(p.sampler, deferred)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, p.option(sampler))
+	}
+	return sdktrace.ParentBased(root, opts...), nil
+}
+
+// spanLimits returns the limits of OTEL_SPAN_* or the defaults overridden by the positive limits
+func (c *SpanLimits) spanLimits() sdktrace.SpanLimits {
+	l := sdktrace.NewSpanLimits()
+	for _, v := range []struct {
+		limit int
+		field *int
+	}{
+		{c.AttributeValueLengthLimit, &l.AttributeValueLengthLimit},
+		{c.AttributeCountLimit, &l.AttributeCountLimit},
+		{c.EventCountLimit, &l.EventCountLimit},
+		{c.LinkCountLimit, &l.LinkCountLimit},
+		{c.EventAttributeCountLimit, &l.AttributePerEventCountLimit},
+		{c.LinkAttributeCountLimit, &l.AttributePerLinkCountLimit},
+	} {
+		if v.limit > 0 {
+			*v.field = v.limit
+		}
+	}
+	return l
+}
+
+func newMeterProvider(ctx context.Context, c *MeterProvider, res *resource.Resource,
+	views []sdkmetric.View) (*sdkmetric.MeterProvider, error) {
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	configViews := make([]sdkmetric.View, 0, len(c.Views))
+	for _, v := range c.Views {
+		view, err := v.newView()
+		if err != nil {
+			return nil, err
+		}
+		configViews = append(configViews, view)
+	}
+	opts = append(opts, sdkmetric.WithView(append(configViews, views...)...))
+	var exporters []sdkmetric.Exporter
+	for _, r := range c.Readers {
+		exp, err := newPeriodicReaderExporter(r)
+		if err != nil {
+			for _, exp := range exporters {
+				_ = exp.Shutdown(ctx)
+			}
+			return nil, err
+		}
+		exporters = append(exporters, exp)
+		var readerOpts []sdkmetric.PeriodicReaderOption
+		if r.Periodic.Interval > 0 {
+			readerOpts = append(readerOpts,
+				sdkmetric.WithInterval(time.Duration(r.Periodic.Interval)*time.Millisecond))
+		}
+		if r.Periodic.Timeout > 0 {
+			readerOpts = append(readerOpts, sdkmetric.WithTimeout(time.Duration(r.Periodic.Timeout)*time.Millisecond))
+		}
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp, readerOpts...)))
+	}
+	return sdkmetric.NewMeterProvider(opts...), nil
+}
+
+func newPeriodicReaderExporter(r MetricReader) (sdkmetric.Exporter, error) {
+	if r.Periodic == nil {
+		return nil, errors.New("fileconfig: only the periodic metric reader is supported")
+	}
+	c, err := newOTLPExporter(r.Periodic.Exporter, "/v1/metrics")
+	if err != nil {
+		return nil, err
+	}
+	return c.newMetricExporter()
+}
+
+var instrumentKinds = map[string]sdkmetric.InstrumentKind{
+	"counter":                    sdkmetric.InstrumentKindCounter,
+	"up_down_counter":            sdkmetric.InstrumentKindUpDownCounter,
+	"histogram":                  sdkmetric.InstrumentKindHistogram,
+	"observable_counter":         sdkmetric.InstrumentKindObservableCounter,
+	"observable_up_down_counter": sdkmetric.InstrumentKindObservableUpDownCounter,
+	"observable_gauge":           sdkmetric.InstrumentKindObservableGauge,
+}
+
+func (v View) newView() (sdkmetric.View, error) {
+	sel := v.Selector
+	if sel == (ViewSelector{}) {
+		return nil, errors.New("fileconfig: view selector is empty")
+	}
+	criteria := sdkmetric.Instrument{
+		Name: sel.InstrumentName,
+		Unit: sel.Unit,
+		Scope: instrumentation.Scope{
+			Name:      sel.MeterName,
+			Version:   sel.MeterVersion,
+			SchemaURL: sel.MeterSchemaURL,
+		},
+	}
+	if sel.InstrumentType != "" {
+		kind, ok := instrumentKinds[sel.InstrumentType]
+		if !ok {
+			return nil, fmt.Errorf("fileconfig: unknown instrument_type %q", sel.InstrumentType)
+		}
+		criteria.Kind = kind
+	}
+	if strings.ContainsAny(sel.InstrumentName, "*?") && v.Stream.Name != "" {
+		return nil, fmt.Errorf("fileconfig: view of the wildcard %q cannot rename the stream", sel.InstrumentName)
+	}
+	stream := sdkmetric.Stream{Name: v.Stream.Name, Description: v.Stream.Description}
+	if a := v.Stream.Aggregation; a != nil {
+		stream.Aggregation = a.aggregation()
+	}
+	if keys := v.Stream.AttributeKeys; keys != nil {
+		stream.AttributeFilter = keys.filter()
+	}
+	return sdkmetric.NewView(criteria, stream), nil
+}
+
+func (a *Aggregation) aggregation() aggregation.Aggregation {
+	switch {
+	case a.Drop:
+		return aggregation.Drop{}
+	case a.Sum:
+		return aggregation.Sum{}
+	case a.LastValue:
+		return aggregation.LastValue{}
+	case a.ExplicitBucketHistogram != nil:
+		h := a.ExplicitBucketHistogram
+		boundaries := h.Boundaries
+		if len(boundaries) == 0 {
+			def, _ := sdkmetric.DefaultAggregationSelector(sdkmetric.InstrumentKindHistogram).(aggregation.ExplicitBucketHistogram)
+			boundaries = def.Boundaries
+		}
+		return aggregation.ExplicitBucketHistogram{
+			Boundaries: boundaries,
+			NoMinMax:   h.RecordMinMax != nil && !*h.RecordMinMax,
+		}
+	}
+	return aggregation.Default{}
+}
+
+// filter keeps the included keys, all if empty, except the excluded ones
+func (k *IncludeExclude) filter() attribute.Filter {
+	included := make(map[attribute.Key]bool, len(k.Included))
+	for _, key := range k.Included {
+		included[attribute.Key(key)] = true
+	}
+	excluded := make(map[attribute.Key]bool, len(k.Excluded))
+	for _, key := range k.Excluded {
+		excluded[attribute.Key(key)] = true
+	}
+	return func(kv attribute.KeyValue) bool {
+		if len(included) > 0 && !included[kv.Key] {
+			return false
+		}
+		return !excluded[kv.Key]
+	}
+}
+
+func newLogger(c *LoggerProvider, res *resource.Resource) (*sdklog.Logger, error) {
+	if len(c.Processors) != 1 || c.Processors[0].Batch == nil {
+		return nil, errors.New("fileconfig: logger_provider requires exactly one batch processor")
+	}
+	level := opentelemetry.DefaultLogLevel
+	if c.Level != "" {
+		var err error
+		if level, err = apilog.ParseLevel(string(c.Level)); err != nil {
+			return nil, fmt.Errorf("fileconfig: %w", err)
+		}
+	}
+	b := c.Processors[0].Batch
+	oc, err := newOTLPExporter(b.Exporter, "/v1/logs")
+	if err != nil {
+		return nil, err
+	}
+	exp, err := oc.newLogExporter()
+	if err != nil {
+		return nil, err
+	}
+	var batchOpts []sdklog.BatchProcessorOption
+	if b.ScheduleDelay > 0 {
+		batchOpts = append(batchOpts, sdklog.WithBatchTimeout(time.Duration(b.ScheduleDelay)*time.Millisecond))
+	}
+	if b.ExportTimeout > 0 {
+		batchOpts = append(batchOpts, sdklog.WithExportTimeout(time.Duration(b.ExportTimeout)*time.Millisecond))
+	}
+	if b.MaxQueueSize > 0 {
+		batchOpts = append(batchOpts, sdklog.WithMaxQueueSize(b.MaxQueueSize))
+	}
+	if b.MaxExportBatchSize > 0 {
+		batchOpts = append(batchOpts, sdklog.WithMaxExportBatchSize(b.MaxExportBatchSize))
+	}
+	return sdklog.NewLogger(
+		sdklog.WithResource(res),
+		sdklog.WithBatcher(sdklog.NewBatchProcessor(exp, batchOpts...)),
+		sdklog.WithLevelEnable(level),
+		sdklog.WithEnableSampler(c.EnableSampler),
+		sdklog.WithEnableSamplerError(c.EnableSamplerError),
+	), nil
+}
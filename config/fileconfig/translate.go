@@ -0,0 +1,174 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package fileconfig
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"trpc-system/go-opentelemetry"
+	"trpc-system/go-opentelemetry/api"
+	"trpc-system/go-opentelemetry/config"
+	"trpc-system/go-opentelemetry/pkg/envconfig"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
+	"trpc-system/go-opentelemetry/sdk/metric"
+	"trpc-system/go-opentelemetry/sdk/propagators"
+)
+
+// NewFromConfig builds the SDK of the config of the tRPC plugin translated by FromConfig,
+// with the views of the RPC latency histograms if the metrics are exported by OTLP
+func NewFromConfig(ctx context.Context, cfg *config.Config, opts ...Option) (*SDK, error) {
+	if metricsEnabled(cfg) {
+		opts = append([]Option{WithMetricViews(metric.OpenTelemetryViews(cfg.Metrics.ServerHistogramBuckets,
+			cfg.Metrics.ClientHistogramBuckets)...)}, opts...)
+	}
+	return New(ctx, FromConfig(cfg), opts...)
+}
+
+// FromConfig translates the config of the tRPC plugin to the declarative configuration. As opentelemetry.Setup,
+// the empty address is resolved by signal from the OTEL_EXPORTER_OTLP_* environment variables, whose headers,
+// timeout and compression apply as well. The features of the plugin only are not translated: the tail sampling,
+// the span metrics, the service graph, the spool, the zpage, the remote config, the Prometheus metrics and
+// the additional exporters of the traces and the logs, whose filters have no declarative equivalent.
+func FromConfig(cfg *config.Config) *Configuration {
+	env := envconfig.Load()
+	export := cfg.Traces.ExportConfig
+	c := &Configuration{
+		FileFormat: FileFormat,
+		Disabled:   env.Disabled,
+		Resource:   resourceFromConfig(cfg),
+		Propagator: &Propagator{Composite: cfg.Traces.Propagators, Inject: cfg.Traces.InjectPropagators},
+		TracerProvider: &TracerProvider{
+			Processors: []SpanProcessor{{Batch: &BatchProcessor{
+				ScheduleDelay:      millis(export.BatchTimeout),
+				ExportTimeout:      millis(export.ExportTimeout),
+				MaxQueueSize:       export.MaxQueueSize,
+				MaxExportBatchSize: export.MaxExportBatchSize,
+				Exporter:           exporterFromConfig(cfg, cfg.Addr, cfg.TLS, env.Traces, "/v1/traces"),
+			}}},
+			Sampler: &Sampler{Dyeing: &DyeingSampler{TenantID: cfg.TenantID, SamplerConfig: cfg.Sampler}},
+			DeferredSample: &DeferredSample{
+				Enabled:            cfg.Traces.EnableDeferredSample,
+				SampleError:        cfg.Traces.DeferredSampleError,
+				SampleSlowDuration: millis(cfg.Traces.DeferredSampleSlowDuration),
+			},
+		},
+	}
+	if len(c.Propagator.Composite) == 0 && len(c.Propagator.Inject) == 0 {
+		c.Propagator.Composite = []string{propagators.NameTraceContext, propagators.NameBaggage}
+	}
+	if metricsEnabled(cfg) {
+		c.MeterProvider = &MeterProvider{Readers: []MetricReader{{Periodic: &PeriodicReader{
+			Exporter: exporterFromConfig(cfg, cfg.Addr, cfg.TLS, env.Metrics, "/v1/metrics"),
+		}}}}
+	}
+	if cfg.Logs.Enabled {
+		c.LoggerProvider = loggerProviderFromConfig(cfg, env.Logs)
+	}
+	return c
+}
+
+func metricsEnabled(cfg *config.Config) bool {
+	return cfg.Metrics.Enabled && cfg.Metrics.Backend == metric.BackendOpenTelemetry
+}
+
+// resourceFromConfig returns the resource of the detectors, none by default, the tenant, the owner and the attributes
+func resourceFromConfig(cfg *config.Config) *Resource {
+	r := &Resource{Detectors: cfg.Resource.Detectors, PodInfoDir: cfg.Resource.PodInfoDir}
+	if len(r.Detectors) == 1 && r.Detectors[0] == "none" {
+		r.Detectors = nil
+	}
+	r.Attributes = append(r.Attributes, Attribute{Name: string(api.TpsTenantIDKey), Value: cfg.TenantID})
+	if cfg.Metrics.ServerOwner != "" {
+		r.Attributes = append(r.Attributes, Attribute{Name: string(api.TpsOwnerKey), Value: cfg.Metrics.ServerOwner})
+	}
+	for _, a := range cfg.Attributes {
+		r.Attributes = append(r.Attributes, Attribute{Name: a.Key, Value: a.Value})
+	}
+	return r
+}
+
+// loggerProviderFromConfig returns the logger provider, logs.addr and logs.tls take precedence
+// and the logs are exported over gRPC only
+func loggerProviderFromConfig(cfg *config.Config, env envconfig.Exporter) *LoggerProvider {
+	addr, tlsCfg := cfg.Addr, cfg.TLS
+	if cfg.Logs.Addr != "" {
+		addr = cfg.Logs.Addr
+	}
+	if cfg.Logs.TLS.Enabled {
+		tlsCfg = tlsconfig.Config{Enabled: true, InsecureSkipVerify: cfg.Logs.TLS.InsecureSkipVeriry}
+	}
+	env.Protocol = envconfig.ProtocolGRPC
+	exporter := exporterFromConfig(cfg, addr, tlsCfg, env, "/v1/logs")
+	exporter.OTLP.Protocol = ProtocolGRPC
+	export := cfg.Logs.ExportOption
+	return &LoggerProvider{
+		Processors: []LogRecordProcessor{{Batch: &BatchProcessor{
+			ScheduleDelay:      millis(export.BatchTimeout),
+			MaxQueueSize:       export.QueueSize,
+			MaxExportBatchSize: export.BatchSize,
+			Exporter:           exporter,
+		}}},
+		Level:              cfg.Logs.Level,
+		EnableSampler:      cfg.Logs.EnableSampler,
+		EnableSamplerError: cfg.Logs.EnableSamplerError,
+	}
+}
+
+// exporterFromConfig returns the OTLP exporter of the address, http:// and https:// are of http/protobuf and the
+// others are of grpc. The environment variables of the signal are used for the empty address.
+func exporterFromConfig(cfg *config.Config, addr string, tlsCfg tlsconfig.Config, env envconfig.Exporter,
+	signalPath string) Exporter {
+	o := &OTLP{Compression: "gzip", Timeout: millis(env.Timeout)}
+	if env.Compression == envconfig.CompressionNone {
+		o.Compression = "none"
+	}
+	switch {
+	case addr == "" && env.Endpoint != "":
+		scheme := "https://"
+		if env.Insecure {
+			scheme = "http://"
+		}
+		o.Protocol, o.Endpoint = ProtocolGRPC, scheme+env.Endpoint
+		if env.Protocol == envconfig.ProtocolHTTPProtobuf {
+			o.Protocol, o.Endpoint = ProtocolHTTPProtobuf, o.Endpoint+env.URLPath
+		}
+	case strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://"):
+		o.Protocol, o.Endpoint = ProtocolHTTPProtobuf, strings.TrimSuffix(addr, "/")+signalPath
+	default:
+		if addr == "" {
+			addr = opentelemetry.DefaultExporterAddr
+		}
+		o.Protocol, o.Endpoint, o.Insecure = ProtocolGRPC, addr, !tlsCfg.Enabled
+	}
+	if tlsCfg.Enabled {
+		o.TLS = &tlsCfg
+	}
+	names := make([]string, 0, len(env.Headers))
+	for name := range env.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o.Headers = append(o.Headers, NameValue{Name: name, Value: env.Headers[name]})
+	}
+	o.Headers = append(o.Headers, NameValue{Name: api.TenantHeaderKey, Value: cfg.TenantID})
+	return Exporter{OTLP: o}
+}
+
+func millis(d time.Duration) int {
+	return int(d / time.Millisecond)
+}
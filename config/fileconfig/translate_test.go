@@ -0,0 +1,117 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package fileconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc-system/go-opentelemetry/config"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
+	"trpc-system/go-opentelemetry/sdk/metric"
+)
+
+func TestFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Addr = "http://collector:4318/"
+	cfg.TenantID = "tenant"
+	cfg.TLS = tlsconfig.Config{Enabled: true, ServerName: "collector"}
+	cfg.Traces.EnableDeferredSample = true
+	cfg.Traces.DeferredSampleSlowDuration = time.Second
+	cfg.Traces.ExportConfig.BatchTimeout = 2 * time.Second
+	cfg.Traces.Propagators = []string{"b3"}
+	cfg.Metrics.Backend = metric.BackendOpenTelemetry
+	cfg.Metrics.ServerOwner = "owner"
+	cfg.Attributes = []*config.Attribute{{Key: "env", Value: "test"}}
+	cfg.Resource.Detectors = []string{"none"}
+	cfg.Logs.Enabled = true
+	cfg.Logs.Addr = "logs:4317"
+	cfg.Logs.Level = "ERROR"
+	cfg.Logs.TLS = config.TLSConfig{Enabled: true, InsecureSkipVeriry: true}
+
+	c := FromConfig(&cfg)
+	assert.Equal(t, FileFormat, c.FileFormat)
+	assert.Equal(t, &Resource{Attributes: []Attribute{
+		{Name: "tps.tenant.id", Value: "tenant"},
+		{Name: "server.owner", Value: "owner"},
+		{Name: "env", Value: "test"},
+	}}, c.Resource)
+	assert.Equal(t, &Propagator{Composite: []string{"b3"}}, c.Propagator)
+
+	tp := c.TracerProvider
+	assert.Equal(t, &DeferredSample{Enabled: true, SampleSlowDuration: 1000}, tp.DeferredSample)
+	assert.Equal(t, &DyeingSampler{TenantID: "tenant", SamplerConfig: cfg.Sampler}, tp.Sampler.Dyeing)
+	batch := tp.Processors[0].Batch
+	assert.Equal(t, 2000, batch.ScheduleDelay)
+	assert.Equal(t, &OTLP{
+		Protocol:    ProtocolHTTPProtobuf,
+		Endpoint:    "http://collector:4318/v1/traces",
+		Headers:     []NameValue{{Name: "X-Tps-TenantID", Value: "tenant"}},
+		Compression: "gzip",
+		TLS:         &cfg.TLS,
+	}, batch.Exporter.OTLP)
+	assert.Equal(t, "http://collector:4318/v1/metrics",
+		c.MeterProvider.Readers[0].Periodic.Exporter.OTLP.Endpoint)
+
+	lp := c.LoggerProvider
+	assert.EqualValues(t, "ERROR", lp.Level)
+	logsOTLP := lp.Processors[0].Batch.Exporter.OTLP
+	assert.Equal(t, ProtocolGRPC, logsOTLP.Protocol)
+	assert.Equal(t, "logs:4317", logsOTLP.Endpoint)
+	assert.False(t, logsOTLP.Insecure)
+	assert.Equal(t, &tlsconfig.Config{Enabled: true, InsecureSkipVerify: true}, logsOTLP.TLS)
+}
+
+func TestFromConfig_Env(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "https://traces:4318/custom")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "b=2,a=1")
+	t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "none")
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "3000")
+
+	cfg := config.DefaultConfig()
+	cfg.Addr = ""
+	c := FromConfig(&cfg)
+	assert.Equal(t, &OTLP{
+		Protocol:    ProtocolHTTPProtobuf,
+		Endpoint:    "https://traces:4318/custom",
+		Headers:     []NameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}, {Name: "X-Tps-TenantID", Value: "default"}},
+		Compression: "none",
+		Timeout:     3000,
+	}, c.TracerProvider.Processors[0].Batch.Exporter.OTLP)
+	assert.Empty(t, c.Resource.Detectors, "the detectors are opt-in")
+	assert.Equal(t, []string{"tracecontext", "baggage"}, c.Propagator.Composite)
+	assert.Nil(t, c.MeterProvider, "the Prometheus metrics are not translated")
+	assert.Nil(t, c.LoggerProvider)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Addr = "localhost:4317"
+	cfg.Sampler.SamplerServerAddr = ""
+	cfg.Metrics.Backend = metric.BackendOpenTelemetry
+	cfg.Resource.Detectors = []string{"none"}
+
+	sdk, err := NewFromConfig(context.Background(), &cfg)
+	require.NoError(t, err)
+	require.NotNil(t, sdk.MeterProvider)
+	require.Len(t, sdk.samplers, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = sdk.Shutdown(ctx)
+}
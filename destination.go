@@ -0,0 +1,184 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package opentelemetry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"trpc-system/go-opentelemetry/api"
+	"trpc-system/go-opentelemetry/exporter/retry"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
+	sdklog "trpc-system/go-opentelemetry/sdk/log"
+	"trpc-system/go-opentelemetry/sdk/trace"
+)
+
+// DefaultDestination the destination name of the address of Setup, once the spans or the logs fan out
+const DefaultDestination = "default"
+
+// Destination the exporter of an additional destination of the spans or the logs besides the address of Setup
+type Destination struct {
+	// Name the destination label of the metrics, required and unique among the destinations of a signal
+	Name string
+	// Addr http:// or https:// for OTLP/HTTP, gRPC otherwise, the logs are exported over gRPC only
+	Addr string
+	// TenantID the tenant header of the destination, the tenant of Setup if empty
+	TenantID string
+	// Headers the additional headers of the exports
+	Headers map[string]string
+	// TLS the TLS of the destination, the TLS of WithTLSConfig is not inherited
+	TLS *tlsconfig.Config
+	// Retry the retry of the failed exports, retry.DefaultConfig if nil
+	Retry *retry.Config
+}
+
+// SpanDestination an additional destination of the spans, with its own batcher, queue and filter.
+// The destinations are not spooled and do not inherit the options of WithBatchSpanProcessorOption.
+type SpanDestination struct {
+	Destination
+	// BatchOptions the options of the batch span processor of the destination
+	BatchOptions []trace.BatchSpanProcessorOption
+	// Filter the spans exported to the destination, all if nil
+	Filter trace.SpanFilter
+}
+
+// LogDestination an additional destination of the logs, with its own batcher, queue and filter.
+// The destinations are not spooled.
+type LogDestination struct {
+	Destination
+	// BatchOptions the options of the batch processor of the destination
+	BatchOptions []sdklog.BatchProcessorOption
+	// Filter the log records exported to the destination, all if nil
+	Filter sdklog.Filter
+}
+
+// WithSpanDestinations exports the spans to the destinations as well, see SpanDestination
+func WithSpanDestinations(destinations ...SpanDestination) SetupOption {
+	return func(options *setupOptions) {
+		options.spanDestinations = append(options.spanDestinations, destinations...)
+	}
+}
+
+// WithLogDestinations exports the logs to the destinations as well, see LogDestination
+func WithLogDestinations(destinations ...LogDestination) SetupOption {
+	return func(options *setupOptions) {
+		options.logDestinations = append(options.logDestinations, destinations...)
+	}
+}
+
+// exporterConfig returns the exporter of the destination, of the tenant if TenantID is empty
+func (d Destination) exporterConfig(tenantID string) exporterConfig {
+	c := exporterConfig{
+		addr:        d.Addr,
+		http:        strings.HasPrefix(d.Addr, "http://") || strings.HasPrefix(d.Addr, "https://"),
+		headers:     make(map[string]string, len(d.Headers)+1),
+		compression: true,
+		tls:         d.TLS,
+		retry:       retry.DefaultConfig,
+		destination: d.Name,
+	}
+	if d.Retry != nil {
+		c.retry = *d.Retry
+	}
+	for k, v := range d.Headers {
+		c.headers[k] = v
+	}
+	if d.TenantID != "" {
+		tenantID = d.TenantID
+	}
+	c.headers[api.TenantHeaderKey] = tenantID
+	return c
+}
+
+// checkDestinations checks the names and the addresses of the destinations
+func checkDestinations(destinations []Destination) error {
+	names := map[string]bool{DefaultDestination: true}
+	for _, d := range destinations {
+		if d.Name == "" {
+			return errors.New("opentelemetry: destination name is required")
+		}
+		if names[d.Name] {
+			return fmt.Errorf("opentelemetry: duplicate destination %s", d.Name)
+		}
+		if d.Addr == "" {
+			return fmt.Errorf("opentelemetry: destination %s addr is required", d.Name)
+		}
+		names[d.Name] = true
+	}
+	return nil
+}
+
+// newFanOutSpanProcessor returns the processor fanning out to the processor of the address of Setup
+// and the batch span processors of the destinations
+func newFanOutSpanProcessor(next sdktrace.SpanProcessor, o *setupOptions) (sdktrace.SpanProcessor, error) {
+	checked := make([]Destination, 0, len(o.spanDestinations))
+	for _, d := range o.spanDestinations {
+		checked = append(checked, d.Destination)
+	}
+	if err := checkDestinations(checked); err != nil {
+		return nil, err
+	}
+	destinations := []trace.Destination{{Name: DefaultDestination, Processor: next}}
+	for _, d := range o.spanDestinations {
+		exp, err := newExporter(d.exporterConfig(o.tenantID), o)
+		if err != nil {
+			return nil, err
+		}
+		opts := append([]trace.BatchSpanProcessorOption{}, d.BatchOptions...)
+		destinations = append(destinations, trace.Destination{
+			Name:      d.Name,
+			Processor: trace.NewBatchSpanProcessor(exp, append(opts, trace.WithDestination(d.Name))...),
+			Filter:    d.Filter,
+		})
+	}
+	return trace.NewFanOutSpanProcessor(destinations...), nil
+}
+
+// NewLogFanOutProcessor returns the processor fanning out to the processor of the address of Setup and the
+// batch processors of the destinations, of the tenant if their TenantID is empty. next is nil if the logs of the
+// address are batched otherwise, e.g. by the batch syncer of otelzap, see otelzap.WithProcessor.
+func NewLogFanOutProcessor(next sdklog.Processor, tenantID string,
+	destinations ...LogDestination) (*sdklog.FanOutProcessor, error) {
+	checked := make([]Destination, 0, len(destinations))
+	for _, d := range destinations {
+		checked = append(checked, d.Destination)
+	}
+	if err := checkDestinations(checked); err != nil {
+		return nil, err
+	}
+	fanOut := make([]sdklog.Destination, 0, len(destinations)+1)
+	if next != nil {
+		fanOut = append(fanOut, sdklog.Destination{Name: DefaultDestination, Processor: next})
+	}
+	for _, d := range destinations {
+		c := d.exporterConfig(tenantID)
+		if c.http {
+			return nil, fmt.Errorf("opentelemetry: log destination %s supports gRPC only", d.Name)
+		}
+		exp, err := newLogExporter(c)
+		if err != nil {
+			return nil, err
+		}
+		opts := append([]sdklog.BatchProcessorOption{}, d.BatchOptions...)
+		fanOut = append(fanOut, sdklog.Destination{
+			Name:      d.Name,
+			Processor: sdklog.NewBatchProcessor(exp, append(opts, sdklog.WithDestination(d.Name))...),
+			Filter:    d.Filter,
+		})
+	}
+	return sdklog.NewFanOutProcessor(fanOut...), nil
+}
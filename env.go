@@ -0,0 +1,116 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package opentelemetry
+
+import (
+	"crypto/tls"
+	"time"
+
+	"trpc-system/go-opentelemetry/api"
+	"trpc-system/go-opentelemetry/exporter/retry"
+	"trpc-system/go-opentelemetry/pkg/envconfig"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
+	sdklog "trpc-system/go-opentelemetry/sdk/log"
+	"trpc-system/go-opentelemetry/sdk/trace"
+)
+
+// exporterConfig the OTLP exporter of a signal. The address of Setup and the options take precedence over
+// the OTEL_EXPORTER_OTLP_* environment variables, whose endpoint and protocol are used only if the address is empty.
+type exporterConfig struct {
+	addr        string
+	http        bool
+	urlPath     string
+	headers     map[string]string
+	timeout     time.Duration
+	compression bool
+	tls         *tlsconfig.Config
+	retry       retry.Config
+	// destination the name of the additional destination, which is not spooled, empty for the address of Setup
+	destination string
+}
+
+func newExporterConfig(addr string, env envconfig.Exporter, o *setupOptions) exporterConfig {
+	c := exporterConfig{
+		addr:        addr,
+		headers:     make(map[string]string, len(env.Headers)+1),
+		timeout:     env.Timeout,
+		compression: env.Compression != envconfig.CompressionNone,
+		tls:         o.tlsConfig,
+		retry:       retry.DefaultConfig,
+	}
+	if c.addr == "" && env.Endpoint != "" {
+		c.addr, c.urlPath = env.Addr(), env.URLPath
+		c.http = env.Protocol == envconfig.ProtocolHTTPProtobuf
+		if c.tls == nil && !c.http && !env.Insecure {
+			// the https endpoint of gRPC is verified by the system roots
+			c.tls = &tlsconfig.Config{Enabled: true}
+		}
+	}
+	if c.addr == "" {
+		c.addr = DefaultExporterAddr
+	}
+	if o.httpEnabled != nil {
+		c.http = *o.httpEnabled
+	}
+	for k, v := range env.Headers {
+		c.headers[k] = v
+	}
+	c.headers[api.TenantHeaderKey] = o.tenantID
+	return c
+}
+
+// newTLSConfig returns the client TLS config of the exporter, nil if TLS is not configured
+func (c exporterConfig) newTLSConfig() (*tls.Config, error) {
+	if c.tls == nil || !c.tls.Enabled {
+		return nil, nil
+	}
+	return tlsconfig.New(*c.tls, c.addr)
+}
+
+// applyEnv applies the environment variables to the default options, before the options of Setup
+func applyEnv(o *setupOptions, env *envconfig.Config) {
+	if sampler := env.NewSampler(); sampler != nil {
+		o.sampler = sampler
+	}
+	if env.BSP.ScheduleDelay > 0 {
+		o.batchSpanOption = append(o.batchSpanOption, trace.WithBatchTimeout(env.BSP.ScheduleDelay))
+	}
+	if env.BSP.ExportTimeout > 0 {
+		o.batchSpanOption = append(o.batchSpanOption, trace.WithExportTimeout(env.BSP.ExportTimeout))
+	}
+	if env.BSP.MaxQueueSize > 0 {
+		o.batchSpanOption = append(o.batchSpanOption, trace.WithMaxQueueSize(env.BSP.MaxQueueSize))
+	}
+	if env.BSP.MaxExportBatchSize > 0 {
+		o.batchSpanOption = append(o.batchSpanOption, trace.WithMaxExportBatchSize(env.BSP.MaxExportBatchSize))
+	}
+}
+
+// logBatchOptions returns the options of the log batch processor of OTEL_BLRP_*
+func logBatchOptions(batch envconfig.Batch) []sdklog.BatchProcessorOption {
+	var opts []sdklog.BatchProcessorOption
+	if batch.ScheduleDelay > 0 {
+		opts = append(opts, sdklog.WithBatchTimeout(batch.ScheduleDelay))
+	}
+	if batch.ExportTimeout > 0 {
+		opts = append(opts, sdklog.WithExportTimeout(batch.ExportTimeout))
+	}
+	if batch.MaxQueueSize > 0 {
+		opts = append(opts, sdklog.WithMaxQueueSize(batch.MaxQueueSize))
+	}
+	if batch.MaxExportBatchSize > 0 {
+		opts = append(opts, sdklog.WithMaxExportBatchSize(batch.MaxExportBatchSize))
+	}
+	return opts
+}
@@ -22,6 +22,7 @@ import (
 
 	"trpc-system/go-opentelemetry/api"
 	"trpc-system/go-opentelemetry/exporter/retry"
+	"trpc-system/go-opentelemetry/exporter/spool"
 )
 
 const (
@@ -92,6 +93,8 @@ type config struct {
 	numWorkers         uint
 	concurrency        int
 	requestFunc        retry.RequestFunc
+	spool              *spool.Spool
+	timeout            time.Duration
 }
 
 // WorkerCount sets the number of Goroutines to use when processing telemetry.
@@ -158,6 +161,13 @@ func WithTenantID(tenantID string) ExporterOption {
 	}
 }
 
+// WithTimeout sets the max duration of each export
+func WithTimeout(timeout time.Duration) ExporterOption {
+	return func(cfg *config) {
+		cfg.timeout = timeout
+	}
+}
+
 // WithTLSCredentials allows the connection to use TLS credentials
 // when talking to the server. It takes in grpc.TransportCredentials instead
 // of say a Certificate file or a tls.Certificate, because the retrieving
@@ -191,3 +201,11 @@ func WithGRPCDialOption(opts ...grpc.DialOption) ExporterOption {
 		cfg.grpcDialOptions = opts
 	}
 }
+
+// WithSpool writes the logs failed to export to the spool, and replays them when the exporter reconnects.
+// The spool is closed when the exporter is shut down.
+func WithSpool(s *spool.Spool) ExporterOption {
+	return func(cfg *config) {
+		cfg.spool = s
+	}
+}
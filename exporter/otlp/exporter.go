@@ -30,6 +30,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"trpc-system/go-opentelemetry/exporter/retry"
 	"trpc-system/go-opentelemetry/sdk/log"
@@ -108,7 +109,49 @@ func (e *Exporter) lastConnectError() error {
 
 // ExportLogs export log
 func (e *Exporter) ExportLogs(parent context.Context, logs []*logsproto.ResourceLogs) error {
-	ctx, cancel := context.WithCancel(parent)
+	err := e.exportLogs(parent, logs)
+	if e.c.spool == nil {
+		return err
+	}
+	if err != nil {
+		return e.spoolLogs(logs, err)
+	}
+	e.c.spool.ReplayAsync(e.replayLogs)
+	return nil
+}
+
+// spoolLogs writes the logs failed to export to the spool.
+func (e *Exporter) spoolLogs(logs []*logsproto.ResourceLogs, err error) error {
+	b, mErr := proto.Marshal(&collectorlogspb.ExportLogsServiceRequest{ResourceLogs: logs})
+	if mErr != nil {
+		return err
+	}
+	if wErr := e.c.spool.Write(b); wErr != nil {
+		return fmt.Errorf("%w, spool: %v", err, wErr)
+	}
+	return fmt.Errorf("%w, logs are spooled", err)
+}
+
+// replayLogs exports the logs read from the spool.
+func (e *Exporter) replayLogs(ctx context.Context, b []byte) error {
+	req := &collectorlogspb.ExportLogsServiceRequest{}
+	if err := proto.Unmarshal(b, req); err != nil {
+		// a record which can not be decoded is dropped
+		return nil
+	}
+	return e.exportLogs(ctx, req.ResourceLogs)
+}
+
+func (e *Exporter) exportLogs(parent context.Context, logs []*logsproto.ResourceLogs) error {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if e.c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, e.c.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
 	defer cancel()
 	go func(ctx context.Context, cancel context.CancelFunc) {
 		select {
@@ -218,6 +261,11 @@ func (e *Exporter) Shutdown(ctx context.Context) error {
 	e.started = false
 	e.mu.Unlock()
 	closeStopCh(e.stopCh)
+	if e.c.spool != nil {
+		if spoolErr := e.c.spool.Close(); err == nil {
+			err = spoolErr
+		}
+	}
 
 	// Ensure that the backgroundConnector returns
 	select {
@@ -282,6 +330,9 @@ func (e *Exporter) indefiniteBackgroundConnection() {
 
 func (e *Exporter) setStateConnected() {
 	e.saveLastConnectError(nil)
+	if e.c.spool != nil {
+		e.c.spool.ReplayAsync(e.replayLogs)
+	}
 }
 
 func (e *Exporter) setStateDisconnected(err error) {
@@ -25,6 +25,7 @@ import (
 
 	"trpc-system/go-opentelemetry/api"
 	"trpc-system/go-opentelemetry/exporter/retry"
+	"trpc-system/go-opentelemetry/exporter/spool"
 )
 
 const (
@@ -86,6 +87,8 @@ type config struct {
 	clientCredentials  credentials.TransportCredentials
 	numWorkers         uint
 	requestFunc        retry.RequestFunc
+	spool              *spool.Spool
+	timeout            time.Duration
 }
 
 // WorkerCount sets the number of Goroutines to use when processing telemetry.
@@ -153,6 +156,13 @@ func WithTenantID(tenantID string) ExporterOption {
 	}
 }
 
+// WithTimeout sets the max duration of each export, including the retries
+func WithTimeout(timeout time.Duration) ExporterOption {
+	return func(cfg *config) {
+		cfg.timeout = timeout
+	}
+}
+
 // WithTLSCredentials allows the connection to use TLS credentials
 // when talking to the server. It takes in grpc.TransportCredentials instead
 // of say a Certificate file or a tls.Certificate, because the retrieving
@@ -216,3 +226,11 @@ func throttleDelay(s *status.Status) time.Duration {
 	}
 	return 0
 }
+
+// WithSpool writes the logs failed to export to the spool, and replays them when the exporter reconnects.
+// The spool is closed when the exporter is shut down.
+func WithSpool(s *spool.Spool) ExporterOption {
+	return func(cfg *config) {
+		cfg.spool = s
+	}
+}
@@ -0,0 +1,507 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package spool persists batches that failed to export in an on-disk write-ahead spool,
+// and replays them oldest-first when the exporter reconnects.
+package spool
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"trpc-system/go-opentelemetry/pkg/metrics"
+)
+
+// Defaults for Config.
+const (
+	// DefaultMaxBytes default max size of the spool directory
+	DefaultMaxBytes = 256 * 1024 * 1024
+	// DefaultSegmentBytes default max size of a segment file
+	DefaultSegmentBytes = 8 * 1024 * 1024
+
+	segmentSuffix = ".seg"
+	cursorFile    = "cursor"
+	lockFile      = "lock"
+	// recordHeaderSize length(4 bytes) + crc32(4 bytes)
+	recordHeaderSize = 8
+)
+
+var (
+	// ErrLocked is returned by New when the spool directory is used by another process.
+	ErrLocked = errors.New("spool dir is locked by another process")
+
+	errClosed         = errors.New("spool closed")
+	errRecordTooLarge = errors.New("spool record too large")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	// openSpools the spools opened in this process by directory, so that they are shared
+	openSpools   = make(map[string]*Spool)
+	openSpoolsMu sync.Mutex
+)
+
+// Config spool configuration
+type Config struct {
+	// Dir is the directory of segment files, it is created if not exists.
+	Dir string
+	// MaxBytes is the maximum size of all segment files. When it is exceeded,
+	// the oldest segment is evicted.
+	// The default value of MaxBytes is 256M (in bytes).
+	MaxBytes int64
+	// SegmentBytes is the maximum size of a segment file.
+	// The default value of SegmentBytes is 8M (in bytes).
+	SegmentBytes int64
+}
+
+type segment struct {
+	seq     uint64
+	size    int64
+	records int
+}
+
+// Spool is an on-disk FIFO of records. Records are appended to segment files,
+// each record is prefixed by its length and CRC, so that a torn or corrupted
+// record is detected and skipped on replay. Delivery is at least once: records
+// being replayed when the process exits may be replayed again on next start.
+type Spool struct {
+	cfg       Config
+	telemetry string
+	// refs the number of New calls sharing this spool, guarded by openSpoolsMu
+	refs int
+	lock *os.File
+
+	mu       sync.Mutex
+	segments []*segment // oldest first, the last one is being written
+	tail     *os.File
+	size     int64
+	depth    int
+	// readSeq and readOff is the position of the next record to replay
+	readSeq  uint64
+	readOff  int64
+	replayMu sync.Mutex
+	closed   bool
+
+	replaying  chan struct{}
+	replayWg   sync.WaitGroup
+	stopCtx    context.Context
+	stopCancel context.CancelFunc
+
+	depthGauge       prometheus.Gauge
+	bytesGauge       prometheus.Gauge
+	writtenCounter   prometheus.Counter
+	replayedCounter  prometheus.Counter
+	failedCounter    prometheus.Counter
+	evictedCounter   prometheus.Counter
+	corruptedCounter prometheus.Counter
+}
+
+// New opens or creates a spool in cfg.Dir, telemetry is the label of spool metrics, e.g. traces, logs.
+// Calls with the same directory in one process share the spool, which is closed by the last Close.
+// The directory is locked while the spool is open, ErrLocked is returned if another process holds it.
+func New(telemetry string, cfg Config) (*Spool, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("spool dir is empty")
+	}
+	dir, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("spool dir: %w", err)
+	}
+	cfg.Dir = dir
+	openSpoolsMu.Lock()
+	defer openSpoolsMu.Unlock()
+	if s, ok := openSpools[dir]; ok {
+		s.refs++
+		return s, nil
+	}
+	s, err := open(telemetry, cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.refs = 1
+	openSpools[dir] = s
+	return s, nil
+}
+
+func open(telemetry string, cfg Config) (*Spool, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.SegmentBytes <= 0 {
+		cfg.SegmentBytes = DefaultSegmentBytes
+	}
+	if cfg.SegmentBytes > cfg.MaxBytes {
+		cfg.SegmentBytes = cfg.MaxBytes
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+	lock, err := lockDir(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &Spool{
+		lock:             lock,
+		cfg:              cfg,
+		telemetry:        telemetry,
+		replaying:        make(chan struct{}, 1),
+		depthGauge:       metrics.SpoolGauge.WithLabelValues("depth", telemetry),
+		bytesGauge:       metrics.SpoolGauge.WithLabelValues("bytes", telemetry),
+		writtenCounter:   metrics.SpoolCounter.WithLabelValues("written", telemetry),
+		replayedCounter:  metrics.SpoolCounter.WithLabelValues("replayed", telemetry),
+		failedCounter:    metrics.SpoolCounter.WithLabelValues("replay_failed", telemetry),
+		evictedCounter:   metrics.SpoolCounter.WithLabelValues("evicted", telemetry),
+		corruptedCounter: metrics.SpoolCounter.WithLabelValues("corrupted", telemetry),
+	}
+	s.stopCtx, s.stopCancel = context.WithCancel(context.Background())
+	if err := s.load(); err != nil {
+		_ = unlockDir(lock)
+		return nil, err
+	}
+	if err := s.rotateLocked(); err != nil {
+		_ = unlockDir(lock)
+		return nil, err
+	}
+	s.updateGaugesLocked()
+	return s, nil
+}
+
+// load scans existing segment files and the replay cursor.
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("read spool dir: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 16, 64)
+		if err != nil {
+			continue
+		}
+		seg := &segment{seq: seq}
+		records, _, _, err := s.readSegment(seg)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		seg.size = info.Size()
+		seg.records = len(records)
+		s.segments = append(s.segments, seg)
+		s.size += seg.size
+		s.depth += seg.records
+	}
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].seq < s.segments[j].seq
+	})
+	s.loadCursor()
+	return nil
+}
+
+func (s *Spool) loadCursor() {
+	b, err := os.ReadFile(filepath.Join(s.cfg.Dir, cursorFile))
+	if err != nil || len(s.segments) == 0 {
+		return
+	}
+	var seq uint64
+	var off int64
+	if _, err := fmt.Sscanf(string(b), "%x %d", &seq, &off); err != nil {
+		return
+	}
+	head := s.segments[0]
+	if seq != head.seq {
+		return
+	}
+	records, offsets, _, err := s.readSegment(head)
+	if err != nil {
+		return
+	}
+	for i := range records {
+		if offsets[i] >= off {
+			break
+		}
+		head.records--
+		s.depth--
+	}
+	s.readSeq, s.readOff = seq, off
+}
+
+func (s *Spool) segmentPath(seq uint64) string {
+	return filepath.Join(s.cfg.Dir, fmt.Sprintf("%016x%s", seq, segmentSuffix))
+}
+
+// rotateLocked closes the segment being written and starts a new one.
+func (s *Spool) rotateLocked() error {
+	if s.tail != nil {
+		_ = s.tail.Close()
+		s.tail = nil
+	}
+	var seq uint64
+	if n := len(s.segments); n > 0 {
+		seq = s.segments[n-1].seq + 1
+	}
+	f, err := os.OpenFile(s.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("create spool segment: %w", err)
+	}
+	s.tail = f
+	s.segments = append(s.segments, &segment{seq: seq})
+	return nil
+}
+
+// Write appends a record to the spool. The oldest segments are evicted if the spool is full.
+func (s *Spool) Write(p []byte) error {
+	recordSize := int64(recordHeaderSize + len(p))
+	if recordSize > s.cfg.SegmentBytes {
+		return errRecordTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errClosed
+	}
+	tail := s.segments[len(s.segments)-1]
+	if tail.size > 0 && tail.size+recordSize > s.cfg.SegmentBytes {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+		tail = s.segments[len(s.segments)-1]
+	}
+	for s.size+recordSize > s.cfg.MaxBytes && len(s.segments) > 1 {
+		s.evictLocked()
+	}
+
+	buf := make([]byte, recordSize)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(p)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.Checksum(p, crcTable))
+	copy(buf[recordHeaderSize:], p)
+	if _, err := s.tail.Write(buf); err != nil {
+		return fmt.Errorf("write spool segment: %w", err)
+	}
+	tail.size += recordSize
+	tail.records++
+	s.size += recordSize
+	s.depth++
+	s.writtenCounter.Inc()
+	s.updateGaugesLocked()
+	return nil
+}
+
+// evictLocked removes the oldest segment.
+func (s *Spool) evictLocked() {
+	head := s.segments[0]
+	s.segments = s.segments[1:]
+	s.size -= head.size
+	s.depth -= head.records
+	s.evictedCounter.Add(float64(head.records))
+	_ = os.Remove(s.segmentPath(head.seq))
+}
+
+// readSegment reads all valid records of a segment and their offsets.
+// Reading stops at the first torn or corrupted record, and corrupted is true.
+func (s *Spool) readSegment(seg *segment) (records [][]byte, offsets []int64, corrupted bool, err error) {
+	b, err := os.ReadFile(s.segmentPath(seg.seq))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var off int64
+	for off < int64(len(b)) {
+		if int64(len(b))-off < recordHeaderSize {
+			return records, offsets, true, nil
+		}
+		n := int64(binary.LittleEndian.Uint32(b[off : off+4]))
+		crc := binary.LittleEndian.Uint32(b[off+4 : off+8])
+		end := off + recordHeaderSize + n
+		if end > int64(len(b)) || crc32.Checksum(b[off+recordHeaderSize:end], crcTable) != crc {
+			return records, offsets, true, nil
+		}
+		records = append(records, b[off+recordHeaderSize:end])
+		offsets = append(offsets, off)
+		off = end
+	}
+	return records, offsets, false, nil
+}
+
+// Replay sends the spooled records to fn oldest-first, and removes them once fn succeeds.
+// It stops at the first error returned by fn, the failed record is kept for next replay.
+func (s *Spool) Replay(ctx context.Context, fn func(context.Context, []byte) error) error {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return errClosed
+		}
+		head := s.segments[0]
+		if len(s.segments) == 1 {
+			if head.records == 0 {
+				s.mu.Unlock()
+				return nil
+			}
+			// do not replay the segment being written
+			if err := s.rotateLocked(); err != nil {
+				s.mu.Unlock()
+				return err
+			}
+		}
+		s.mu.Unlock()
+
+		if err := s.replaySegment(ctx, head, fn); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Spool) replaySegment(ctx context.Context, head *segment, fn func(context.Context, []byte) error) error {
+	records, offsets, corrupted, err := s.readSegment(head)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if corrupted {
+		s.corruptedCounter.Inc()
+	}
+	for i, record := range records {
+		if head.seq == s.readSeq && offsets[i] < s.readOff {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(ctx, record); err != nil {
+			s.failedCounter.Inc()
+			return err
+		}
+		s.replayedCounter.Inc()
+
+		s.mu.Lock()
+		if len(s.segments) == 0 || s.segments[0] != head {
+			// evicted while replaying
+			s.mu.Unlock()
+			return nil
+		}
+		head.records--
+		s.depth--
+		s.readSeq, s.readOff = head.seq, offsets[i]+int64(recordHeaderSize+len(record))
+		s.saveCursorLocked()
+		s.updateGaugesLocked()
+		s.mu.Unlock()
+	}
+
+	// the whole segment is replayed, corrupted records are dropped with it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.segments) > 1 && s.segments[0] == head {
+		s.segments = s.segments[1:]
+		s.size -= head.size
+		s.depth -= head.records
+		_ = os.Remove(s.segmentPath(head.seq))
+		s.updateGaugesLocked()
+	}
+	return nil
+}
+
+func (s *Spool) saveCursorLocked() {
+	_ = os.WriteFile(filepath.Join(s.cfg.Dir, cursorFile),
+		[]byte(fmt.Sprintf("%x %d", s.readSeq, s.readOff)), 0o644)
+}
+
+// ReplayAsync replays the spool in background if the spool is not empty and no replay is running.
+func (s *Spool) ReplayAsync(fn func(context.Context, []byte) error) {
+	if s.Depth() == 0 {
+		return
+	}
+	select {
+	case s.replaying <- struct{}{}:
+	default:
+		return
+	}
+	s.replayWg.Add(1)
+	go func() {
+		defer func() {
+			<-s.replaying
+			s.replayWg.Done()
+		}()
+		_ = s.Replay(s.stopCtx, fn)
+	}()
+}
+
+// Depth returns the number of records waiting for replay.
+func (s *Spool) Depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.depth
+}
+
+// Size returns the size of all segment files.
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+func (s *Spool) updateGaugesLocked() {
+	s.depthGauge.Set(float64(s.depth))
+	s.bytesGauge.Set(float64(s.size))
+}
+
+// Close stops replaying and closes the segment being written, spooled records are kept on disk.
+// A shared spool is closed when all its users have closed it.
+func (s *Spool) Close() error {
+	openSpoolsMu.Lock()
+	if s.refs > 1 {
+		s.refs--
+		openSpoolsMu.Unlock()
+		return nil
+	}
+	if s.refs == 1 {
+		s.refs = 0
+		delete(openSpools, s.cfg.Dir)
+	}
+	openSpoolsMu.Unlock()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.stopCancel()
+	var err error
+	if s.tail != nil {
+		err = s.tail.Close()
+		s.tail = nil
+	}
+	s.mu.Unlock()
+	s.replayWg.Wait()
+	if uErr := unlockDir(s.lock); err == nil {
+		err = uErr
+	}
+	return err
+}
@@ -0,0 +1,239 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package spool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func collect(t *testing.T, s *Spool) []string {
+	var got []string
+	require.NoError(t, s.Replay(context.Background(), func(_ context.Context, b []byte) error {
+		got = append(got, string(b))
+		return nil
+	}))
+	return got
+}
+
+func TestSpool_WriteReplay(t *testing.T) {
+	s, err := New("test", Config{Dir: t.TempDir(), SegmentBytes: 32})
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Write([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	assert.Equal(t, 5, s.Depth())
+	assert.Equal(t, []string{"record-0", "record-1", "record-2", "record-3", "record-4"}, collect(t, s))
+	assert.Equal(t, 0, s.Depth())
+	assert.Empty(t, collect(t, s))
+}
+
+func TestSpool_ReplayStopsOnError(t *testing.T) {
+	s, err := New("test", Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Write([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	var sent int
+	err = s.Replay(context.Background(), func(_ context.Context, b []byte) error {
+		if sent == 1 {
+			return errors.New("unavailable")
+		}
+		sent++
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 2, s.Depth())
+	assert.Equal(t, []string{"record-1", "record-2"}, collect(t, s))
+}
+
+func TestSpool_EvictOldest(t *testing.T) {
+	s, err := New("test", Config{Dir: t.TempDir(), MaxBytes: 64, SegmentBytes: 32})
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := 0; i < 6; i++ {
+		require.NoError(t, s.Write([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	assert.LessOrEqual(t, s.Size(), int64(64))
+	// each record is 16 bytes, two segments hold the newest four records
+	assert.Equal(t, []string{"record-2", "record-3", "record-4", "record-5"}, collect(t, s))
+}
+
+func TestSpool_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Write([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	var sent int
+	_ = s.Replay(context.Background(), func(_ context.Context, b []byte) error {
+		if sent == 1 {
+			return errors.New("unavailable")
+		}
+		sent++
+		return nil
+	})
+	require.NoError(t, s.Close())
+
+	s, err = New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, 2, s.Depth())
+	assert.Equal(t, []string{"record-1", "record-2"}, collect(t, s))
+}
+
+func TestSpool_Shared(t *testing.T) {
+	dir := t.TempDir()
+	s1, err := New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	s2, err := New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	assert.Same(t, s1, s2)
+
+	require.NoError(t, s1.Close())
+	// still open for the other user
+	require.NoError(t, s2.Write([]byte("record-0")))
+	require.NoError(t, s2.Close())
+	assert.Error(t, s2.Write([]byte("record-1")))
+}
+
+func TestSpool_Locked(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	// another process holding the directory
+	_, err = lockDir(dir)
+	assert.ErrorIs(t, err, ErrLocked)
+	require.NoError(t, s.Close())
+
+	lock, err := lockDir(dir)
+	require.NoError(t, err)
+	_, err = New("test", Config{Dir: dir})
+	assert.ErrorIs(t, err, ErrLocked)
+	require.NoError(t, unlockDir(lock))
+}
+
+func TestSpool_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	require.NoError(t, s.Write([]byte("record-0")))
+	require.NoError(t, s.Write([]byte("record-1")))
+	require.NoError(t, s.Close())
+
+	// corrupt the payload of the second record
+	path := filepath.Join(dir, fmt.Sprintf("%016x%s", 0, segmentSuffix))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	b[len(b)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, b, 0o644))
+
+	s, err = New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, []string{"record-0"}, collect(t, s))
+}
+
+type fakeTraceClient struct {
+	mu       sync.Mutex
+	fail     bool
+	uploaded []string
+}
+
+func (c *fakeTraceClient) Start(context.Context) error { return nil }
+
+func (c *fakeTraceClient) Stop(context.Context) error { return nil }
+
+func (c *fakeTraceClient) UploadTraces(_ context.Context, rs []*tracepb.ResourceSpans) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail {
+		return errors.New("unavailable")
+	}
+	for _, r := range rs {
+		c.uploaded = append(c.uploaded, r.SchemaUrl)
+	}
+	return nil
+}
+
+func (c *fakeTraceClient) get() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.uploaded...)
+}
+
+func TestTraceClient(t *testing.T) {
+	s, err := New("test", Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	fake := &fakeTraceClient{fail: true}
+	client := NewTraceClient(fake, s)
+
+	assert.Error(t, client.UploadTraces(context.Background(), []*tracepb.ResourceSpans{{SchemaUrl: "1"}}))
+	assert.Error(t, client.UploadTraces(context.Background(), []*tracepb.ResourceSpans{{SchemaUrl: "2"}}))
+	assert.Equal(t, 2, s.Depth())
+
+	fake.mu.Lock()
+	fake.fail = false
+	fake.mu.Unlock()
+	assert.NoError(t, client.UploadTraces(context.Background(), []*tracepb.ResourceSpans{{SchemaUrl: "3"}}))
+	assert.Eventually(t, func() bool {
+		return len(fake.get()) == 3 && s.Depth() == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"3", "1", "2"}, fake.get())
+	assert.NoError(t, client.Stop(context.Background()))
+}
+
+func TestTraceClient_ReplayOnStart(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	require.NoError(t, s.Write(mustMarshalTraces(t, "1")))
+	require.NoError(t, s.Close())
+
+	s, err = New("test", Config{Dir: dir})
+	require.NoError(t, err)
+	fake := &fakeTraceClient{}
+	client := NewTraceClient(fake, s)
+	require.NoError(t, client.Start(context.Background()))
+	assert.Eventually(t, func() bool {
+		return len(fake.get()) == 1 && s.Depth() == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, client.Stop(context.Background()))
+}
+
+func mustMarshalTraces(t *testing.T, schemaURL string) []byte {
+	b, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{SchemaUrl: schemaURL}},
+	})
+	require.NoError(t, err)
+	return b
+}
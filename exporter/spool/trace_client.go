@@ -0,0 +1,82 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package spool
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ otlptrace.Client = (*traceClient)(nil)
+
+// traceClient writes the failed uploads to the spool, and replays them when the client starts
+// and after an upload succeeds.
+type traceClient struct {
+	otlptrace.Client
+	spool *Spool
+}
+
+// NewTraceClient wraps an otlptrace.Client with the spool.
+func NewTraceClient(client otlptrace.Client, s *Spool) otlptrace.Client {
+	return &traceClient{Client: client, spool: s}
+}
+
+// Start starts the client and replays the spans spooled before, e.g. by the last run.
+func (c *traceClient) Start(ctx context.Context) error {
+	if err := c.Client.Start(ctx); err != nil {
+		return err
+	}
+	c.spool.ReplayAsync(c.replay)
+	return nil
+}
+
+// UploadTraces uploads the spans, the spans are spooled if the upload fails.
+func (c *traceClient) UploadTraces(ctx context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	err := c.Client.UploadTraces(ctx, protoSpans)
+	if err == nil {
+		c.spool.ReplayAsync(c.replay)
+		return nil
+	}
+	b, mErr := proto.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: protoSpans})
+	if mErr != nil {
+		return err
+	}
+	if wErr := c.spool.Write(b); wErr != nil {
+		return fmt.Errorf("%w, spool: %v", err, wErr)
+	}
+	return fmt.Errorf("%w, spans are spooled", err)
+}
+
+func (c *traceClient) replay(ctx context.Context, b []byte) error {
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(b, req); err != nil {
+		// a record which can not be decoded is dropped
+		return nil
+	}
+	return c.Client.UploadTraces(ctx, req.ResourceSpans)
+}
+
+// Stop stops the client and closes the spool.
+func (c *traceClient) Stop(ctx context.Context) error {
+	err := c.Client.Stop(ctx)
+	if sErr := c.spool.Close(); err == nil {
+		err = sErr
+	}
+	return err
+}
@@ -15,12 +15,18 @@ package opentelemetry
 
 import (
 	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
@@ -30,13 +36,18 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	apitrace "go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"trpc-system/go-opentelemetry/api"
 	apilog "trpc-system/go-opentelemetry/api/log"
 	ecosystemotlp "trpc-system/go-opentelemetry/exporter/otlp"
-	"trpc-system/go-opentelemetry/exporter/retry"
+	"trpc-system/go-opentelemetry/exporter/spool"
+	"trpc-system/go-opentelemetry/pkg/envconfig"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
 	"trpc-system/go-opentelemetry/pkg/zpage"
 	sdklog "trpc-system/go-opentelemetry/sdk/log"
+	"trpc-system/go-opentelemetry/sdk/remote"
+	ecosystemresource "trpc-system/go-opentelemetry/sdk/resource"
 	"trpc-system/go-opentelemetry/sdk/trace"
 
 	_ "google.golang.org/grpc/encoding/gzip" // open gzip
@@ -51,6 +62,7 @@ var (
 	DefaultExporterAddr = "localhost:12520"
 	DefaultLogLevel     = apilog.InfoLevel
 	MaxSendMessageSize  = 4194304
+	DefaultSpoolDir     = filepath.Join(os.TempDir(), "opentelemetry-spool")
 )
 
 // GlobalTracer global tracer
@@ -58,6 +70,12 @@ func GlobalTracer() apitrace.Tracer {
 	return globalTracer
 }
 
+// SetTracerProvider sets the global tracer provider, whose tracer is used by Start and WithSpan
+func SetTracerProvider(tp apitrace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	globalTracer = tp.Tracer("")
+}
+
 // SetGlobalIDGenerator set global id generator
 func SetGlobalIDGenerator(gen sdktrace.IDGenerator) {
 	globalIDGenerator = gen
@@ -91,17 +109,16 @@ func Setup(addr string, opts ...SetupOption) error {
 	return setup(addr, opts...)
 }
 
-func newTraceHTTPExporter(addr string, o *setupOptions) (sdktrace.SpanExporter, error) {
+func newTraceHTTPExporter(c exporterConfig, o *setupOptions) (sdktrace.SpanExporter, error) {
+	addr := c.addr
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
 	otlpTraceOpts := []otlptracehttp.Option{
-		otlptracehttp.WithEndpoint(addr),
-		otlptracehttp.WithCompression(otlptracehttp.GzipCompression),
-		otlptracehttp.WithHeaders(map[string]string{api.TenantHeaderKey: o.tenantID}),
-		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
-			Enabled:         true,
-			InitialInterval: retry.DefaultConfig.InitialInterval,
-			MaxInterval:     retry.DefaultConfig.MaxInterval,
-			MaxElapsedTime:  retry.DefaultConfig.MaxElapsedTime,
-		}),
+		otlptracehttp.WithCompression(otlptracehttp.NoCompression),
+		otlptracehttp.WithHeaders(c.headers),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig(c.retry)),
 	}
 	switch {
 	case strings.HasPrefix(addr, "http://"):
@@ -112,64 +129,165 @@ func newTraceHTTPExporter(addr string, o *setupOptions) (sdktrace.SpanExporter,
 	default:
 		otlpTraceOpts = append(otlpTraceOpts, otlptracehttp.WithEndpoint(addr))
 	}
-	exporter, err := otlptracehttp.New(context.Background(), otlpTraceOpts...)
-	if err != nil {
-		return nil, err
+	if tlsConfig != nil && !strings.HasPrefix(addr, "http://") {
+		otlpTraceOpts = append(otlpTraceOpts, otlptracehttp.WithTLSClientConfig(tlsConfig))
 	}
-	return exporter, nil
+	if c.compression {
+		otlpTraceOpts[0] = otlptracehttp.WithCompression(otlptracehttp.GzipCompression)
+	}
+	if c.urlPath != "" {
+		otlpTraceOpts = append(otlpTraceOpts, otlptracehttp.WithURLPath(c.urlPath))
+	}
+	if c.timeout > 0 {
+		otlpTraceOpts = append(otlpTraceOpts, otlptracehttp.WithTimeout(c.timeout))
+	}
+	return newTraceExporter(otlptracehttp.NewClient(otlpTraceOpts...), c, o)
 }
 
-func newTraceGRPCExporter(addr string, o *setupOptions) (sdktrace.SpanExporter, error) {
+func newTraceGRPCExporter(c exporterConfig, o *setupOptions) (sdktrace.SpanExporter, error) {
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
 	otlpTraceOpts := []otlptracegrpc.Option{
 		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(addr),
-		otlptracegrpc.WithCompressor("gzip"),
-		otlptracegrpc.WithHeaders(map[string]string{api.TenantHeaderKey: o.tenantID}),
+		otlptracegrpc.WithEndpoint(c.addr),
+		otlptracegrpc.WithHeaders(c.headers),
 		otlptracegrpc.WithDialOption(grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(MaxSendMessageSize))),
-		otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
-			Enabled:         true,
-			InitialInterval: retry.DefaultConfig.InitialInterval,
-			MaxInterval:     retry.DefaultConfig.MaxInterval,
-			MaxElapsedTime:  retry.DefaultConfig.MaxElapsedTime,
-		}),
+		otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(c.retry)),
+	}
+	if tlsConfig != nil {
+		otlpTraceOpts[0] = otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig))
+	}
+	if c.compression {
+		otlpTraceOpts = append(otlpTraceOpts, otlptracegrpc.WithCompressor("gzip"))
+	}
+	if c.timeout > 0 {
+		otlpTraceOpts = append(otlpTraceOpts, otlptracegrpc.WithTimeout(c.timeout))
 	}
 	if len(o.grpcDialOptions) > 0 {
 		otlpTraceOpts = append(otlpTraceOpts, otlptracegrpc.WithDialOption(o.grpcDialOptions...))
 	}
-	exporter, err := otlptracegrpc.New(context.Background(), otlpTraceOpts...)
+	return newTraceExporter(otlptracegrpc.NewClient(otlpTraceOpts...), c, o)
+}
+
+func newTraceExporter(client otlptrace.Client, c exporterConfig, o *setupOptions) (sdktrace.SpanExporter, error) {
+	if o.spoolConfig != nil && c.destination == "" {
+		s, err := openSpool("traces", o)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			client = spool.NewTraceClient(client, s)
+		}
+	}
+	exporter, err := otlptrace.New(context.Background(), client)
 	if err != nil {
 		return nil, err
 	}
 	return exporter, nil
 }
 
-func newExporter(addr string, o *setupOptions) (sdktrace.SpanExporter, error) {
-	if o.httpEnabled {
-		return newTraceHTTPExporter(addr, o)
+// openSpool opens the spool of the telemetry. The spool is disabled if its directory is
+// locked by another process, e.g. another instance of the service on the same host.
+func openSpool(telemetry string, o *setupOptions) (*spool.Spool, error) {
+	cfg := *o.spoolConfig
+	cfg.Dir = SpoolDir(cfg.Dir, o.serviceName, telemetry)
+	s, err := spool.New(telemetry, cfg)
+	if errors.Is(err, spool.ErrLocked) {
+		log.Printf("opentelemetry: %s spool disabled: %v", telemetry, err)
+		return nil, nil
+	}
+	return s, err
+}
+
+// SpoolDir returns the spool directory of the telemetry, each telemetry uses its own subdirectory of dir.
+// If dir is empty, the subdirectory of the service in DefaultSpoolDir is used, so that services on the
+// same host do not share a spool.
+func SpoolDir(dir, serviceName, telemetry string) string {
+	if dir == "" {
+		if serviceName == "" {
+			serviceName = "default"
+		}
+		dir = filepath.Join(DefaultSpoolDir, serviceName)
+	}
+	return filepath.Join(dir, telemetry)
+}
+
+func newExporter(c exporterConfig, o *setupOptions) (sdktrace.SpanExporter, error) {
+	if c.http {
+		return newTraceHTTPExporter(c, o)
+	}
+	return newTraceGRPCExporter(c, o)
+}
+
+// newSpanProcessor returns the processor of the exported spans, which fans out to the destinations if any.
+// The adaptive sampler observes the spans exported to the address of Setup only.
+func newSpanProcessor(exp sdktrace.SpanExporter, o *setupOptions) (sdktrace.SpanProcessor, error) {
+	batchSpanOption := o.batchSpanOption
+	if sampler, ok := o.sampler.(*trace.Sampler); ok && sampler.AdaptiveEnabled() {
+		batchSpanOption = append([]trace.BatchSpanProcessorOption{
+			trace.WithExportObserver(sampler.ObserveExportedSpan)}, batchSpanOption...)
+	}
+	if len(o.spanDestinations) > 0 {
+		batchSpanOption = append(batchSpanOption, trace.WithDestination(DefaultDestination))
+	}
+	var next sdktrace.SpanProcessor = trace.NewBatchSpanProcessor(exp, batchSpanOption...)
+	if len(o.spanDestinations) > 0 {
+		var err error
+		if next, err = newFanOutSpanProcessor(next, o); err != nil {
+			return nil, err
+		}
+	}
+	if o.tailSampleConfig != nil {
+		return trace.NewTailSampleProcessor(next, o.deferredSampler, *o.tailSampleConfig), nil
 	}
-	return newTraceGRPCExporter(addr, o)
+	return trace.NewDeferredSampleProcessor(next, o.deferredSampler), nil
 }
 
 func setup(addr string, options ...SetupOption) error {
+	env := envconfig.Load()
+	if env.Disabled {
+		log.Printf("opentelemetry: setup skipped by OTEL_SDK_DISABLED")
+		return nil
+	}
 	o := defaultSetupOptions()
+	applyEnv(o, env)
 	for _, opt := range options {
 		opt(o)
 	}
 
-	exp, err := newExporter(addr, o)
+	exp, err := newExporter(newExporterConfig(addr, env.Traces, o), o)
+	if err != nil {
+		return err
+	}
+	sp, err := newSpanProcessor(exp, o)
 	if err != nil {
 		return err
 	}
 
 	var opts []sdktrace.TracerProviderOption
 	opts = append(opts, sdktrace.WithSampler(o.sampler))
-	opts = append(opts, sdktrace.WithSpanProcessor(
-		trace.NewDeferredSampleProcessor(
-			trace.NewBatchSpanProcessor(exp, o.batchSpanOption...), o.deferredSampler)))
+	opts = append(opts, sdktrace.WithSpanProcessor(sp))
 
 	if o.zPageEnabled {
 		opts = append(opts, sdktrace.WithSpanProcessor(zpage.GetZPageProcessor()))
 	}
+	if o.spanMetrics != nil {
+		// added besides the deferred sampling processor to count the unsampled spans as well
+		p, err := trace.NewSpanMetricsProcessor(*o.spanMetrics)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, sdktrace.WithSpanProcessor(p))
+	}
+	if o.serviceGraph != nil {
+		p, err := trace.NewServiceGraphProcessor(*o.serviceGraph)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, sdktrace.WithSpanProcessor(p))
+	}
 
 	kvs := []attribute.KeyValue{
 		api.TpsTenantIDKey.String(o.tenantID),
@@ -186,16 +304,16 @@ func setup(addr string, options ...SetupOption) error {
 		kvs = append(kvs, semconv.ServiceNamespaceKey.String(o.serviceNamespace))
 	}
 
+	res := newResource(o, kvs)
+
 	if o.logEnabled {
-		if err = setupLog(addr, o, kvs); err != nil {
+		if err = setupLog(newExporterConfig(addr, env.Logs, o), env.BLRP, o, res); err != nil {
 			return err
 		}
 	}
 
-	res := resource.NewWithAttributes(semconv.SchemaURL, kvs...)
-
 	if o.metricEnabled {
-		if err = setupMetric(addr, res, o); err != nil {
+		if err = setupMetric(newExporterConfig(addr, env.Metrics, o), res, o); err != nil {
 			return err
 		}
 	}
@@ -204,46 +322,87 @@ func setup(addr string, options ...SetupOption) error {
 		opts = append(opts, sdktrace.WithIDGenerator(o.idGenerator))
 	}
 
+	if sampler, ok := o.sampler.(*trace.Sampler); ok && o.configurator != nil {
+		o.configurator.RegisterConfigPrepareFunc(sampler.PrepareRemoteConfig)
+	}
 	traceProvider := sdktrace.NewTracerProvider(opts...)
-	otel.SetTracerProvider(traceProvider)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{},
-		propagation.Baggage{}))
-	globalTracer = otel.Tracer("")
+	SetTracerProvider(traceProvider)
+	otel.SetTextMapPropagator(o.propagator)
 	return nil
 }
 
+// newResource returns the resource shared by traces, metrics and logs, merged in ascending precedence from
+// the detectors, OTEL_RESOURCE_ATTRIBUTES, the resource of WithResource and the attributes of the options
+func newResource(o *setupOptions, kvs []attribute.KeyValue) *resource.Resource {
+	res := ecosystemresource.Detect(context.Background(), o.resourceDetectors...)
+	res = ecosystemresource.Merge(res, ecosystemresource.Detect(context.Background(), ecosystemresource.EnvDetector{}))
+	res = ecosystemresource.Merge(res, o.resourceLabels)
+	res = ecosystemresource.Merge(res, resource.NewSchemaless(kvs...))
+	return resource.NewWithAttributes(semconv.SchemaURL, res.Attributes()...)
+}
+
 var meterProvider *sdkmetric.MeterProvider
 
-func newMetricHTTPExporter(addr string, o *setupOptions) (*sdkmetric.Exporter, error) {
+// newMetricHTTPExporter returns the OTLP/HTTP metric exporter, which is insecure for the address
+// of http:// or without scheme unless TLS is configured
+func newMetricHTTPExporter(c exporterConfig) (*sdkmetric.Exporter, error) {
+	addr := c.addr
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
 	otlpMetricOpts := []otlpmetrichttp.Option{
-		otlpmetrichttp.WithInsecure(),
-		otlpmetrichttp.WithEndpoint(addr),
-		otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression),
-		otlpmetrichttp.WithHeaders(map[string]string{api.TenantHeaderKey: o.tenantID}),
-		otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig{
-			Enabled:         true,
-			InitialInterval: retry.DefaultConfig.InitialInterval,
-			MaxInterval:     retry.DefaultConfig.MaxInterval,
-			MaxElapsedTime:  retry.DefaultConfig.MaxElapsedTime,
-		}),
+		otlpmetrichttp.WithCompression(otlpmetrichttp.NoCompression),
+		otlpmetrichttp.WithHeaders(c.headers),
+		otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(c.retry)),
+	}
+	switch {
+	case strings.HasPrefix(addr, "http://"):
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetrichttp.WithInsecure(),
+			otlpmetrichttp.WithEndpoint(strings.TrimPrefix(addr, "http://")))
+	case strings.HasPrefix(addr, "https://"):
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetrichttp.WithEndpoint(strings.TrimPrefix(addr, "https://")))
+	case tlsConfig != nil:
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetrichttp.WithEndpoint(addr))
+	default:
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetrichttp.WithInsecure(), otlpmetrichttp.WithEndpoint(addr))
+	}
+	if tlsConfig != nil && !strings.HasPrefix(addr, "http://") {
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+	}
+	if c.compression {
+		otlpMetricOpts[0] = otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression)
+	}
+	if c.urlPath != "" {
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetrichttp.WithURLPath(c.urlPath))
+	}
+	if c.timeout > 0 {
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetrichttp.WithTimeout(c.timeout))
 	}
 	exp, err := otlpmetrichttp.New(context.Background(), otlpMetricOpts...)
 	return &exp, err
 }
 
-func newMetricGrpcExporter(addr string, o *setupOptions) (*sdkmetric.Exporter, error) {
+func newMetricGrpcExporter(c exporterConfig, o *setupOptions) (*sdkmetric.Exporter, error) {
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
 	otlpMetricOpts := []otlpmetricgrpc.Option{
 		otlpmetricgrpc.WithInsecure(),
-		otlpmetricgrpc.WithEndpoint(addr),
-		otlpmetricgrpc.WithCompressor("gzip"),
-		otlpmetricgrpc.WithHeaders(map[string]string{api.TenantHeaderKey: o.tenantID}),
+		otlpmetricgrpc.WithEndpoint(c.addr),
+		otlpmetricgrpc.WithHeaders(c.headers),
 		otlpmetricgrpc.WithDialOption(grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(MaxSendMessageSize))),
-		otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig{
-			Enabled:         true,
-			InitialInterval: retry.DefaultConfig.InitialInterval,
-			MaxInterval:     retry.DefaultConfig.MaxInterval,
-			MaxElapsedTime:  retry.DefaultConfig.MaxElapsedTime,
-		}),
+		otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(c.retry)),
+	}
+	if tlsConfig != nil {
+		otlpMetricOpts[0] = otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig))
+	}
+	if c.compression {
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetricgrpc.WithCompressor("gzip"))
+	}
+	if c.timeout > 0 {
+		otlpMetricOpts = append(otlpMetricOpts, otlpmetricgrpc.WithTimeout(c.timeout))
 	}
 	if len(o.grpcDialOptions) > 0 {
 		otlpMetricOpts = append(otlpMetricOpts, otlpmetricgrpc.WithDialOption(o.grpcDialOptions...))
@@ -252,61 +411,115 @@ func newMetricGrpcExporter(addr string, o *setupOptions) (*sdkmetric.Exporter, e
 	return &exp, err
 }
 
-func setupMetric(addr string, res *resource.Resource, o *setupOptions) (err error) {
+func setupMetric(c exporterConfig, res *resource.Resource, o *setupOptions) (err error) {
 	var exporter *sdkmetric.Exporter
-	if o.httpEnabled {
-		exporter, err = newMetricHTTPExporter(addr, o)
+	if c.http {
+		exporter, err = newMetricHTTPExporter(c)
 	} else {
-		exporter, err = newMetricGrpcExporter(addr, o)
+		exporter, err = newMetricGrpcExporter(c, o)
 	}
 	if err != nil {
 		return err
 	}
 	meterProvider = sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(*exporter)), sdkmetric.WithResource(res))
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(*exporter)), sdkmetric.WithResource(res),
+		sdkmetric.WithView(o.metricViews...))
 	otel.SetMeterProvider(meterProvider)
 	return nil
 }
 
-func setupLog(addr string, o *setupOptions, kvs []attribute.KeyValue) (err error) {
-	exporter, err := ecosystemotlp.NewExporter(
-		ecosystemotlp.WithInsecure(),
-		ecosystemotlp.WithAddress(addr),
-		ecosystemotlp.WithTenantID(o.tenantID),
-		ecosystemotlp.WithCompressor("gzip"),
-		ecosystemotlp.WithHeaders(map[string]string{api.TenantHeaderKey: o.tenantID}),
-		ecosystemotlp.WithRetryConfig(retry.DefaultConfig),
-	)
+// setupLog sets up the global logger, which exports the logs over gRPC only
+func setupLog(c exporterConfig, batch envconfig.Batch, o *setupOptions, res *resource.Resource) (err error) {
+	if c.http {
+		log.Printf("opentelemetry: logs are exported over gRPC to %s, the HTTP protocol is not supported", c.addr)
+	}
+	exporterOpts := []ecosystemotlp.ExporterOption{ecosystemotlp.WithTenantID(o.tenantID)}
+	if o.spoolConfig != nil {
+		s, err := openSpool("logs", o)
+		if err != nil {
+			return err
+		}
+		if s != nil {
+			exporterOpts = append(exporterOpts, ecosystemotlp.WithSpool(s))
+		}
+	}
+	exporter, err := newLogExporter(c, exporterOpts...)
 	if err != nil {
 		return err
 	}
+	batchOpts := logBatchOptions(batch)
+	if len(o.logDestinations) > 0 {
+		batchOpts = append(batchOpts, sdklog.WithDestination(DefaultDestination))
+	}
+	var processor sdklog.Processor = sdklog.NewBatchProcessor(exporter, batchOpts...)
+	if len(o.logDestinations) > 0 {
+		if processor, err = NewLogFanOutProcessor(processor, o.tenantID, o.logDestinations...); err != nil {
+			return err
+		}
+	}
 	logger := sdklog.NewLogger(
-		sdklog.WithResource(resource.NewWithAttributes(semconv.SchemaURL, kvs...)),
-		sdklog.WithBatcher(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(processor),
 		sdklog.WithLevelEnable(o.enabledLogLevel),
 	)
+	if o.configurator != nil {
+		o.configurator.RegisterConfigPrepareFunc(logger.PrepareRemoteConfig)
+	}
 	apilog.SetGlobalLogger(logger)
 	return nil
 }
 
+// newLogExporter returns the gRPC log exporter, opts are applied after the options of c
+func newLogExporter(c exporterConfig, opts ...ecosystemotlp.ExporterOption) (*ecosystemotlp.Exporter, error) {
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	exporterOpts := []ecosystemotlp.ExporterOption{
+		ecosystemotlp.WithInsecure(),
+		ecosystemotlp.WithAddress(c.addr),
+		ecosystemotlp.WithHeaders(c.headers),
+		ecosystemotlp.WithRetryConfig(c.retry),
+		ecosystemotlp.WithTimeout(c.timeout),
+	}
+	if tlsConfig != nil {
+		exporterOpts[0] = ecosystemotlp.WithTLSCredentials(credentials.NewTLS(tlsConfig))
+	}
+	if c.compression {
+		exporterOpts = append(exporterOpts, ecosystemotlp.WithCompressor("gzip"))
+	}
+	return ecosystemotlp.NewExporter(append(exporterOpts, opts...)...)
+}
+
 type setupOptions struct {
-	tenantID         string
-	sampler          sdktrace.Sampler
-	serviceName      string
-	serviceNamespace string
-	grpcDialOptions  []grpc.DialOption
-	resourceLabels   *resource.Resource
-	logEnabled       bool
-	enabledLogLevel  apilog.Level
-	metricEnabled    bool
-	httpEnabled      bool
-	zPageEnabled     bool
-	ServerOwner      string
-	CmdbID           string
-	additionalLabels []attribute.KeyValue
-	deferredSampler  trace.DeferredSampler
-	batchSpanOption  []trace.BatchSpanProcessorOption
-	idGenerator      sdktrace.IDGenerator
+	tenantID          string
+	sampler           sdktrace.Sampler
+	serviceName       string
+	serviceNamespace  string
+	grpcDialOptions   []grpc.DialOption
+	resourceLabels    *resource.Resource
+	resourceDetectors []resource.Detector
+	tlsConfig         *tlsconfig.Config
+	logEnabled        bool
+	enabledLogLevel   apilog.Level
+	metricEnabled     bool
+	httpEnabled       *bool
+	zPageEnabled      bool
+	ServerOwner       string
+	CmdbID            string
+	additionalLabels  []attribute.KeyValue
+	deferredSampler   trace.DeferredSampler
+	tailSampleConfig  *trace.TailSampleConfig
+	spanMetrics       *trace.SpanMetricsConfig
+	serviceGraph      *trace.ServiceGraphConfig
+	spoolConfig       *spool.Config
+	batchSpanOption   []trace.BatchSpanProcessorOption
+	idGenerator       sdktrace.IDGenerator
+	configurator      remote.Configurator
+	propagator        propagation.TextMapPropagator
+	metricViews       []sdkmetric.View
+	spanDestinations  []SpanDestination
+	logDestinations   []LogDestination
 }
 
 func defaultSetupOptions() *setupOptions {
@@ -316,6 +529,8 @@ func defaultSetupOptions() *setupOptions {
 		logEnabled:      false,
 		enabledLogLevel: apilog.InfoLevel,
 		deferredSampler: trace.NewDeferredSampler(trace.DeferredSampleConfig{}),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{},
+			propagation.Baggage{}),
 	}
 }
 
@@ -357,13 +572,29 @@ func WithGRPCDialOption(opts ...grpc.DialOption) SetupOption {
 	}
 }
 
-// WithResource with resource
+// WithResource with resource, merged over the detected resource and OTEL_RESOURCE_ATTRIBUTES
 func WithResource(rs *resource.Resource) SetupOption {
 	return func(options *setupOptions) {
 		options.resourceLabels = rs
 	}
 }
 
+// WithResourceDetectors sets the resource detectors run in order, e.g. DefaultDetectors of sdk/resource
+// of the host, process, container, Kubernetes pod and quota, no detector is run by default
+func WithResourceDetectors(detectors ...resource.Detector) SetupOption {
+	return func(options *setupOptions) {
+		options.resourceDetectors = detectors
+	}
+}
+
+// WithTLSConfig with the TLS of the trace, metric and log exporters over gRPC and HTTP,
+// the exporters are insecure if cfg.Enabled is false
+func WithTLSConfig(cfg tlsconfig.Config) SetupOption {
+	return func(options *setupOptions) {
+		options.tlsConfig = &cfg
+	}
+}
+
 // WithSampler with sampler
 func WithSampler(sampler sdktrace.Sampler) SetupOption {
 	return func(options *setupOptions) {
@@ -399,10 +630,42 @@ func WithDeferredSampler(deferredSampler trace.DeferredSampler) SetupOption {
 	}
 }
 
-// WithBatchSpanProcessorOption sets the options to configure a BatchSpanProcessor.
+// WithTailSampler enables trace aware deferred sampling, spans are buffered by trace and
+// the whole local trace is kept or dropped by the deferred sampler
+func WithTailSampler(tailSampleConfig trace.TailSampleConfig) SetupOption {
+	return func(cfg *setupOptions) {
+		cfg.tailSampleConfig = &tailSampleConfig
+	}
+}
+
+// WithSpanMetrics enables the RED metrics aggregated from the ended spans, including the unsampled ones
+func WithSpanMetrics(cfg trace.SpanMetricsConfig) SetupOption {
+	return func(options *setupOptions) {
+		options.spanMetrics = &cfg
+	}
+}
+
+// WithServiceGraph enables the service graph metrics aggregated from the client and server spans
+func WithServiceGraph(cfg trace.ServiceGraphConfig) SetupOption {
+	return func(options *setupOptions) {
+		options.serviceGraph = &cfg
+	}
+}
+
+// WithSpool enables the on-disk spool, batches failed to export are written to the spool
+// and replayed when the exporter reconnects. Traces and logs use their own subdirectory of cfg.Dir,
+// the subdirectory of the service in DefaultSpoolDir is used if cfg.Dir is empty.
+func WithSpool(cfg spool.Config) SetupOption {
+	return func(options *setupOptions) {
+		options.spoolConfig = &cfg
+	}
+}
+
+// WithBatchSpanProcessorOption sets the options to configure a BatchSpanProcessor,
+// which take precedence over the earlier ones and OTEL_BSP_*.
 func WithBatchSpanProcessorOption(opts ...trace.BatchSpanProcessorOption) SetupOption {
 	return func(cfg *setupOptions) {
-		cfg.batchSpanOption = opts
+		cfg.batchSpanOption = append(cfg.batchSpanOption, opts...)
 	}
 }
 
@@ -413,10 +676,17 @@ func WithMetricEnabled(enabled bool) SetupOption {
 	}
 }
 
-// WithHTTPEnabled enabled http protocol, default is grpc
+// WithMetricViews sets the views of the meter provider, e.g. metric.OpenTelemetryViews for the RPC metrics
+func WithMetricViews(views ...sdkmetric.View) SetupOption {
+	return func(cfg *setupOptions) {
+		cfg.metricViews = append(cfg.metricViews, views...)
+	}
+}
+
+// WithHTTPEnabled enabled http protocol, default is grpc, or OTEL_EXPORTER_OTLP_PROTOCOL if the address is empty
 func WithHTTPEnabled(enabled bool) SetupOption {
 	return func(cfg *setupOptions) {
-		cfg.httpEnabled = enabled
+		cfg.httpEnabled = &enabled
 	}
 }
 
@@ -434,8 +704,54 @@ func WithZPageSpanProcessor(enable bool) SetupOption {
 	}
 }
 
-// Shutdown report all data before process exit
+// WithConfigurator applies the sampler and log level config pushed by the remote configurator
+func WithConfigurator(configurator remote.Configurator) SetupOption {
+	return func(cfg *setupOptions) {
+		cfg.configurator = configurator
+	}
+}
+
+// WithPropagators sets the global propagator, default is W3C trace context and baggage.
+// Use propagators.New to extract and inject the B3, Jaeger or OT formats.
+func WithPropagators(propagator propagation.TextMapPropagator) SetupOption {
+	return func(options *setupOptions) {
+		if propagator != nil {
+			options.propagator = propagator
+		}
+	}
+}
+
+var (
+	shutdownHooksMu sync.Mutex
+	shutdownHooks   []func(context.Context) error
+)
+
+// RegisterShutdownHook registers the hook called by Shutdown before the providers are shut down,
+// e.g. the deregistration and the last Prometheus push of the metrics
+func RegisterShutdownHook(hook func(ctx context.Context) error) {
+	shutdownHooksMu.Lock()
+	defer shutdownHooksMu.Unlock()
+	shutdownHooks = append(shutdownHooks, hook)
+}
+
+// runShutdownHooks calls all the shutdown hooks, the first error is returned
+func runShutdownHooks(ctx context.Context) error {
+	shutdownHooksMu.Lock()
+	hooks := append([]func(context.Context) error{}, shutdownHooks...)
+	shutdownHooksMu.Unlock()
+	var err error
+	for _, hook := range hooks {
+		if e := hook(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Shutdown report all data before process exit, the shutdown hooks are called first
+// and their error is returned once the providers are shut down
 func Shutdown(ctx context.Context) error {
+	hookErr := runShutdownHooks(ctx)
 	if meterProvider != nil {
 		if err := meterProvider.Shutdown(ctx); err != nil {
 			return err
@@ -451,5 +767,5 @@ func Shutdown(ctx context.Context) error {
 			return err
 		}
 	}
-	return nil
+	return hookErr
 }
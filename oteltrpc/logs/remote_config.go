@@ -0,0 +1,74 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package logs
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	logtps "trpc-system/go-opentelemetry/api/log"
+	"trpc-system/go-opentelemetry/otelzap"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// trackedLevel level of a log writer with its local level.
+type trackedLevel struct {
+	level zap.AtomicLevel
+	local zapcore.Level
+}
+
+var (
+	levelsMu    sync.Mutex
+	levels      []trackedLevel
+	remoteLevel *zapcore.Level // nil if the remote level is unset
+)
+
+// trackLevel lets the remote config change the level of a log writer.
+func trackLevel(level zap.AtomicLevel) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	levels = append(levels, trackedLevel{level: level, local: level.Level()})
+	if remoteLevel != nil {
+		level.SetLevel(*remoteLevel)
+	}
+}
+
+// PrepareRemoteConfig validates the log level of the remote config, the returned function
+// applies it to all opentelemetry log writers. The local levels are restored if the remote level is empty.
+func PrepareRemoteConfig(c *operation.Operation) (func(), error) {
+	var level *zapcore.Level
+	if text := c.GetLog().GetLevel(); text != "" {
+		l, err := logtps.ParseLevel(text)
+		if err != nil {
+			return nil, fmt.Errorf("log level: %w", err)
+		}
+		zl := otelzap.ToZapLevel(l)
+		level = &zl
+	}
+	return func() {
+		levelsMu.Lock()
+		defer levelsMu.Unlock()
+		remoteLevel = level
+		for _, l := range levels {
+			if level != nil {
+				l.level.SetLevel(*level)
+			} else {
+				l.level.SetLevel(l.local)
+			}
+		}
+	}, nil
+}
@@ -38,9 +38,12 @@ import (
 	"trpc-system/go-opentelemetry/config"
 	"trpc-system/go-opentelemetry/exporter/asyncexporter"
 	otlplog "trpc-system/go-opentelemetry/exporter/otlp"
+	"trpc-system/go-opentelemetry/exporter/spool"
 	"trpc-system/go-opentelemetry/oteltrpc/consts"
 	otelprometheus "trpc-system/go-opentelemetry/oteltrpc/metrics/prometheus"
 	"trpc-system/go-opentelemetry/otelzap"
+	"trpc-system/go-opentelemetry/pkg/envconfig"
+	"trpc-system/go-opentelemetry/pkg/tlsconfig"
 	sdklog "trpc-system/go-opentelemetry/sdk/log"
 )
 
@@ -74,7 +77,7 @@ func (w writer) Setup(name string, configDec plugin.Decoder) error {
 		return err
 	}
 
-	if !cfg.Logs.Enabled {
+	if !cfg.Logs.Enabled || envconfig.Load().Disabled {
 		decoder.Core = zapcore.NewNopCore()
 		return nil
 	}
@@ -87,6 +90,18 @@ func (w writer) Setup(name string, configDec plugin.Decoder) error {
 	if err != nil {
 		return errors.New("opentelemetry log exporter create fail: " + err.Error())
 	}
+	syncerOpts := getBatchSyncerOptions(cfg.Logs)
+	if len(cfg.Logs.Exporters) > 0 {
+		destinations := make([]opentelemetry.LogDestination, 0, len(cfg.Logs.Exporters))
+		for _, e := range cfg.Logs.Exporters {
+			destinations = append(destinations, e.LogDestination())
+		}
+		fanOut, err := opentelemetry.NewLogFanOutProcessor(nil, cfg.TenantID, destinations...)
+		if err != nil {
+			return errors.New("opentelemetry log exporter create fail: " + err.Error())
+		}
+		syncerOpts = append(syncerOpts, otelzap.WithProcessor(fanOut))
+	}
 
 	kvs := []attribute.KeyValue{
 		api.TpsTenantIDKey.String(cfg.TenantID),
@@ -104,10 +119,11 @@ func (w writer) Setup(name string, configDec plugin.Decoder) error {
 		otelzap.NewBatchWriteSyncer(
 			exp,
 			resource.NewWithAttributes(semconv.SchemaURL, kvs...),
-			getBatchSyncerOptions(cfg.Logs)...,
+			syncerOpts...,
 		),
 		opts...,
 	)
+	trackLevel(decoder.ZapLevel)
 
 	if enableLogRateLimit(cfg) {
 		decoder.Core = zapcore.NewSamplerWithOptions(decoder.Core,
@@ -157,7 +173,7 @@ func enableLogRateLimit(cfg *config.Config) bool {
 	return cfg.Logs.RateLimit.First != 0 && cfg.Logs.RateLimit.Thereafter != 0 && cfg.Logs.RateLimit.Tick != 0
 }
 
-// ParseConfig parse config from decoder
+// ParseConfig parse config from decoder, the YAML takes precedence over the OTEL_* environment variables
 var ParseConfig = func(decoder *log.Decoder) (*config.Config, error) {
 	cfg := &config.Config{
 		Addr:     opentelemetry.DefaultExporterAddr,
@@ -167,6 +183,8 @@ var ParseConfig = func(decoder *log.Decoder) (*config.Config, error) {
 			Level:   opentelemetry.DefaultLogLevel,
 		},
 	}
+	env := envconfig.Load()
+	cfg.ApplyEnv(env)
 
 	if err := loadConfig(cfg); err != nil {
 		return nil, err
@@ -184,9 +202,44 @@ var ParseConfig = func(decoder *log.Decoder) (*config.Config, error) {
 	if cfg.Logs.Addr != "" {
 		cfg.Addr = cfg.Logs.Addr
 	}
+	if cfg.Addr == "" {
+		cfg.Addr = logsAddr(cfg, env.Logs)
+	}
 	return cfg, nil
 }
 
+// logsAddr returns the gRPC address of the logs endpoint from the environment, TLS is enabled for https
+func logsAddr(cfg *config.Config, env envconfig.Exporter) string {
+	if env.Endpoint == "" {
+		return opentelemetry.DefaultExporterAddr
+	}
+	if env.Protocol == envconfig.ProtocolHTTPProtobuf {
+		log.Warnf("opentelemetry: logs are exported over gRPC to %s, the HTTP protocol is not supported", env.Endpoint)
+	}
+	if !env.Insecure && !cfg.Logs.TLS.Enabled && !cfg.TLS.Enabled {
+		cfg.TLS.Enabled = true
+	}
+	return env.Endpoint
+}
+
+// logsHeaders returns the headers of the log exporter, the OTEL_EXPORTER_OTLP_LOGS_HEADERS and the tenant
+func logsHeaders(cfg *config.Config, env envconfig.Exporter) map[string]string {
+	headers := make(map[string]string, len(env.Headers)+1)
+	for k, v := range env.Headers {
+		headers[k] = v
+	}
+	headers[api.TenantHeaderKey] = cfg.TenantID
+	return headers
+}
+
+// logsCompressor returns the compressor of the log exporter, gzip unless OTEL_EXPORTER_OTLP_LOGS_COMPRESSION is none
+func logsCompressor(env envconfig.Exporter) string {
+	if env.Compression == envconfig.CompressionNone {
+		return ""
+	}
+	return "gzip"
+}
+
 func loadConfig(cfg *config.Config) error {
 	telemetrys, ok := trpc.GlobalConfig().Plugins["telemetry"]
 	if ok {
@@ -200,50 +253,106 @@ func loadConfig(cfg *config.Config) error {
 }
 
 func newOtlpExporter(cfg *config.Config) (*otlplog.Exporter, error) {
-	return otlplog.NewExporter(otlpTLSOption(&cfg.Logs),
+	tlsOption, err := otlpTLSOption(cfg)
+	if err != nil {
+		return nil, err
+	}
+	env := envconfig.Load().Logs
+	opts := []otlplog.ExporterOption{
+		tlsOption,
 		otlplog.WithAddress(cfg.Addr),
-		otlplog.WithCompressor("gzip"),
-		otlplog.WithHeaders(map[string]string{api.TenantHeaderKey: cfg.TenantID}),
+		otlplog.WithCompressor(logsCompressor(env)),
+		otlplog.WithHeaders(logsHeaders(cfg, env)),
+		otlplog.WithTimeout(env.Timeout),
 		otlplog.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(
 			grpc_prometheus.UnaryClientInterceptor,
 			packetLogSizeMetric(),
-		)))
+		)),
+	}
+	if cfg.Logs.ExportOption.Spool.Enabled {
+		s, err := newLogSpool(cfg.Logs.ExportOption.Spool)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			opts = append(opts, otlplog.WithSpool(s))
+		}
+	}
+	return otlplog.NewExporter(opts...)
 }
 
 func newAsyncExporter(cfg *config.Config, concurrency int) (*asyncexporter.Exporter, error) {
-	return asyncexporter.NewExporter(asyncTLSOption(&cfg.Logs),
+	tlsOption, err := asyncTLSOption(cfg)
+	if err != nil {
+		return nil, err
+	}
+	env := envconfig.Load().Logs
+	opts := []asyncexporter.ExporterOption{
+		tlsOption,
 		asyncexporter.WithAddress(cfg.Addr),
-		asyncexporter.WithCompressor("gzip"),
+		asyncexporter.WithCompressor(logsCompressor(env)),
 		asyncexporter.WithConcurrency(concurrency),
-		asyncexporter.WithHeaders(map[string]string{api.TenantHeaderKey: cfg.TenantID}),
+		asyncexporter.WithHeaders(logsHeaders(cfg, env)),
+		asyncexporter.WithTimeout(env.Timeout),
 		asyncexporter.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(
 			grpc_prometheus.UnaryClientInterceptor,
 			packetLogSizeMetric(),
-		)))
+		)),
+	}
+	if cfg.Logs.ExportOption.Spool.Enabled {
+		s, err := newLogSpool(cfg.Logs.ExportOption.Spool)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			opts = append(opts, asyncexporter.WithSpool(s))
+		}
+	}
+	return asyncexporter.NewExporter(opts...)
 }
 
-func otlpTLSOption(cfg *config.LogsConfig) otlplog.ExporterOption {
-	if !cfg.TLS.Enabled {
-		return otlplog.WithInsecure()
+// newLogSpool opens the log spool in the logs subdirectory, the same layout as opentelemetry.WithSpool.
+// All loggers of the process share the spool, it is disabled if another process holds the directory.
+func newLogSpool(cfg config.SpoolConfig) (*spool.Spool, error) {
+	serviceName := trpc.GlobalConfig().Server.App + "." + trpc.GlobalConfig().Server.Server
+	s, err := spool.New("logs", spool.Config{
+		Dir:          opentelemetry.SpoolDir(cfg.Dir, serviceName, "logs"),
+		MaxBytes:     cfg.MaxBytes,
+		SegmentBytes: cfg.SegmentBytes,
+	})
+	if errors.Is(err, spool.ErrLocked) {
+		log.Warnf("opentelemetry: logs spool disabled: %v", err)
+		return nil, nil
 	}
-	tlsConfig := &tls.Config{}
-	if cfg.TLS.InsecureSkipVeriry {
-		tlsConfig.InsecureSkipVerify = true
+	return s, err
+}
+
+// logTLSConfig returns the client TLS config of the log exporter, logs.tls takes precedence over the top level tls,
+// nil if neither is enabled
+func logTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if cfg.Logs.TLS.Enabled {
+		return &tls.Config{InsecureSkipVerify: cfg.Logs.TLS.InsecureSkipVeriry}, nil
+	}
+	if cfg.TLS.Enabled {
+		return tlsconfig.New(cfg.TLS, cfg.Addr)
 	}
-	cred := credentials.NewTLS(tlsConfig)
-	return otlplog.WithTLSCredentials(cred)
+	return nil, nil
 }
 
-func asyncTLSOption(cfg *config.LogsConfig) asyncexporter.ExporterOption {
-	if !cfg.TLS.Enabled {
-		return asyncexporter.WithInsecure()
+func otlpTLSOption(cfg *config.Config) (otlplog.ExporterOption, error) {
+	tlsConfig, err := logTLSConfig(cfg)
+	if err != nil || tlsConfig == nil {
+		return otlplog.WithInsecure(), err
 	}
-	tlsConfig := &tls.Config{}
-	if cfg.TLS.InsecureSkipVeriry {
-		tlsConfig.InsecureSkipVerify = true
+	return otlplog.WithTLSCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+func asyncTLSOption(cfg *config.Config) (asyncexporter.ExporterOption, error) {
+	tlsConfig, err := logTLSConfig(cfg)
+	if err != nil || tlsConfig == nil {
+		return asyncexporter.WithInsecure(), err
 	}
-	cred := credentials.NewTLS(tlsConfig)
-	return asyncexporter.WithTLSCredentials(cred)
+	return asyncexporter.WithTLSCredentials(credentials.NewTLS(tlsConfig)), nil
 }
 
 // packetLogSizeMetric metric for log pakcet isze
@@ -27,6 +27,7 @@ import (
 
 	trpccodes "trpc-system/go-opentelemetry/oteltrpc/codes"
 	"trpc-system/go-opentelemetry/sdk/metric"
+	ecosystemtrace "trpc-system/go-opentelemetry/sdk/trace"
 )
 
 // ServerFilter with prometheus metric
@@ -42,9 +43,11 @@ func ServerFilter(options ...ServerFilterOption) filter.ServerFilter {
 			calleeMethod = fmt.Sprintf("[%s]%s", head.Request.Method, metric.CleanRPCMethod(head.Request.URL.Path))
 		}
 
+		traceConfig := filterConfig.traceConfig()
 		r := metric.NewServerReporter("trpc", msg.CallerServiceName(), msg.CallerMethod(),
-			msg.CalleeServiceName(), calleeMethod, metric.WithServerTraceConfig(filterConfig.enableDeferredSample,
-				filterConfig.deferredSampleError, filterConfig.deferredSampleSlowDuration))
+			msg.CalleeServiceName(), calleeMethod, metric.WithServerTraceConfig(traceConfig.Enabled,
+				traceConfig.SampleError, traceConfig.SampleSlowDuration),
+			metric.WithServerDyeingKey(msg.DyeingKey()))
 		rsp, err = handle(ctx, req)
 		code, _ := trpccodes.GetDefaultGetCodeFunc()(ctx, rsp, err)
 		r.Handled(ctx, code)
@@ -63,9 +66,11 @@ func ClientFilter(options ...ClientFilterOption) filter.ClientFilter {
 		md := msg.ClientMetaData()
 		monitorRequestSize(req, md)
 
+		traceConfig := filterConfig.traceConfig()
 		r := metric.NewClientReporter("trpc", msg.CallerServiceName(), msg.CallerMethod(),
-			msg.CalleeServiceName(), msg.CalleeMethod(), metric.WithClientTraceConfig(filterConfig.enableDeferredSample,
-				filterConfig.deferredSampleError, filterConfig.deferredSampleSlowDuration))
+			msg.CalleeServiceName(), msg.CalleeMethod(), metric.WithClientTraceConfig(traceConfig.Enabled,
+				traceConfig.SampleError, traceConfig.SampleSlowDuration),
+			metric.WithClientDyeingKey(msg.DyeingKey()))
 
 		err = handle(ctx, req, rsp)
 
@@ -102,6 +107,22 @@ func WithClientFilterTraceConfig(enableDeferredSample, deferredSampleError bool,
 	}
 }
 
+// WithServerFilterDeferredSampleConfig return Option which follows the changes of the deferred sampling
+// configuration, it takes precedence over WithServerFilterTraceConfig.
+func WithServerFilterDeferredSampleConfig(cfg *ecosystemtrace.DynamicDeferredSampleConfig) ServerFilterOption {
+	return func(opt *serverFilterOption) {
+		opt.deferredSampleConfig = cfg
+	}
+}
+
+// WithClientFilterDeferredSampleConfig return Option which follows the changes of the deferred sampling
+// configuration, it takes precedence over WithClientFilterTraceConfig.
+func WithClientFilterDeferredSampleConfig(cfg *ecosystemtrace.DynamicDeferredSampleConfig) ClientFilterOption {
+	return func(opt *clientFilterOption) {
+		opt.deferredSampleConfig = cfg
+	}
+}
+
 // calcBodySize calc proto request size
 func calcBodySize(body interface{}) int {
 	switch req := body.(type) {
@@ -135,10 +156,34 @@ type serverFilterOption struct {
 	enableDeferredSample       bool
 	deferredSampleError        bool
 	deferredSampleSlowDuration time.Duration
+	deferredSampleConfig       *ecosystemtrace.DynamicDeferredSampleConfig
+}
+
+func (o *serverFilterOption) traceConfig() ecosystemtrace.DeferredSampleConfig {
+	return loadTraceConfig(o.deferredSampleConfig, o.enableDeferredSample,
+		o.deferredSampleError, o.deferredSampleSlowDuration)
 }
 
 type clientFilterOption struct {
 	enableDeferredSample       bool
 	deferredSampleError        bool
 	deferredSampleSlowDuration time.Duration
+	deferredSampleConfig       *ecosystemtrace.DynamicDeferredSampleConfig
+}
+
+func (o *clientFilterOption) traceConfig() ecosystemtrace.DeferredSampleConfig {
+	return loadTraceConfig(o.deferredSampleConfig, o.enableDeferredSample,
+		o.deferredSampleError, o.deferredSampleSlowDuration)
+}
+
+func loadTraceConfig(dynamic *ecosystemtrace.DynamicDeferredSampleConfig, enabled, sampleError bool,
+	sampleSlowDuration time.Duration) ecosystemtrace.DeferredSampleConfig {
+	if dynamic != nil {
+		return dynamic.Load()
+	}
+	return ecosystemtrace.DeferredSampleConfig{
+		Enabled:            enabled,
+		SampleError:        sampleError,
+		SampleSlowDuration: sampleSlowDuration,
+	}
 }
@@ -39,7 +39,8 @@ func StreamServerFilter() server.StreamFilter {
 			msg.CalleeServiceName(),
 			msg.CalleeMethod(),
 			metric.WithServerMetrics(metric.DefaultServerMetrics),
-			metric.WithServerRPCType(serverStreamType(info)))
+			metric.WithServerRPCType(serverStreamType(info)),
+			metric.WithServerDyeingKey(msg.DyeingKey()))
 		err := handler(&monitoredServerStream{Stream: ss, monitor: sr})
 		code, _ := trpccodes.GetDefaultGetCodeFunc()(ctx, nil, err)
 		sr.Handled(ctx, code)
@@ -63,6 +64,7 @@ func StreamClientFilter() client.StreamFilter {
 			msg.CalleeMethod(),
 			metric.WithClientMetrics(metric.DefaultClientMetrics),
 			metric.WithClientRPCType(clientStreamType(desc)),
+			metric.WithClientDyeingKey(msg.DyeingKey()),
 		)
 
 		cs, err := streamer(ctx, desc)
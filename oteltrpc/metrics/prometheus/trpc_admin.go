@@ -34,6 +34,8 @@ import (
 func Setup(tenantID string, etcdEndpoints []string, opts ...metric.SetupOption) {
 	initSink()
 	admin.HandleFunc("/metrics", metric.LimitMetricsHandler().ServeHTTP)
+	admin.HandleFunc("/metrics/http_sd", metric.HTTPSDHandler().ServeHTTP)
+	admin.HandleFunc("/metrics/cardinality", metric.CardinalityHandler().ServeHTTP)
 	if tenantID == "" {
 		tenantID = "default"
 	}
@@ -51,6 +53,7 @@ func Setup(tenantID string, etcdEndpoints []string, opts ...metric.SetupOption)
 			oteladmin.WithEnablePrometheus(true),
 			oteladmin.WithEnableHotSwitch(true),
 			oteladmin.WithEnableZPage(cfg.EnabledZPage),
+			oteladmin.WithEnableServiceGraph(cfg.EnabledServiceGraph),
 		)
 		if err != nil {
 			log.Errorf("failed to new admin server: %v", err)
@@ -87,7 +90,7 @@ func Setup(tenantID string, etcdEndpoints []string, opts ...metric.SetupOption)
 		setupOpts = append(setupOpts, opts...)
 		err := metric.Setup(setupOpts...)
 		if err != nil {
-			log.Errorf("opentelemetry: metrics endpoint register etcd err:%v, endpoints:%v", err, etcdEndpoints)
+			log.Errorf("opentelemetry: metrics endpoint register err:%v, endpoints:%v", err, etcdEndpoints)
 			return
 		}
 	}()
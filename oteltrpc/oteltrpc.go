@@ -19,32 +19,40 @@ import (
 	"log"
 	"runtime"
 	"strings"
+	"time"
 
 	v1proto "github.com/golang/protobuf/proto"
 	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
 
 	"trpc.group/trpc-go/trpc-go"
 	"trpc.group/trpc-go/trpc-go/admin"
+	"trpc.group/trpc-go/trpc-go/client"
 	"trpc.group/trpc-go/trpc-go/codec"
 	"trpc.group/trpc-go/trpc-go/filter"
 	"trpc.group/trpc-go/trpc-go/plugin"
+	"trpc.group/trpc-go/trpc-go/server"
 
 	"trpc-system/go-opentelemetry"
 	"trpc-system/go-opentelemetry/config"
 	"trpc-system/go-opentelemetry/config/codes"
+	"trpc-system/go-opentelemetry/exporter/spool"
 	trpccodes "trpc-system/go-opentelemetry/oteltrpc/codes"
 	"trpc-system/go-opentelemetry/oteltrpc/consts"
 	"trpc-system/go-opentelemetry/oteltrpc/logs"
 	"trpc-system/go-opentelemetry/oteltrpc/metrics/prometheus"
 	"trpc-system/go-opentelemetry/oteltrpc/traces"
+	"trpc-system/go-opentelemetry/pkg/envconfig"
 	"trpc-system/go-opentelemetry/pkg/zpage"
 	"trpc-system/go-opentelemetry/sdk/metric"
+	"trpc-system/go-opentelemetry/sdk/propagators"
 	"trpc-system/go-opentelemetry/sdk/remote"
+	ecosystemresource "trpc-system/go-opentelemetry/sdk/resource"
 	ecosystemtrace "trpc-system/go-opentelemetry/sdk/trace"
 )
 
@@ -67,6 +75,35 @@ func (f factory) Type() string {
 	return consts.PluginType
 }
 
+var (
+	// remoteConfigurator watches the remote config, set by Setup
+	remoteConfigurator remote.Configurator
+	// dyeingSampler the sampler created by Setup, nil if DefaultSampler is set by user
+	dyeingSampler *ecosystemtrace.Sampler
+)
+
+// metricShutdownTimeout bounds the deregistration and the final push on Close
+const metricShutdownTimeout = 5 * time.Second
+
+// Close deregisters the metrics, stops the Prometheus push, and stops watching the remote config and the dyeing
+// metadata, it is called when the trpc server exits.
+func (f factory) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), metricShutdownTimeout)
+	defer cancel()
+	if err := metric.Shutdown(ctx); err != nil {
+		log.Printf("opentelemetry: metric shutdown err: %v", err)
+	}
+	if dyeingSampler != nil {
+		if err := dyeingSampler.Close(); err != nil {
+			return err
+		}
+	}
+	if remoteConfigurator != nil {
+		return remoteConfigurator.Close()
+	}
+	return nil
+}
+
 func packetSizeMetric() func(ctx context.Context, method string,
 	req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 	return func(ctx context.Context, method string, req, reply interface{},
@@ -118,36 +155,50 @@ func (f factory) Setup(name string, configDec plugin.Decoder) error {
 	if err != nil {
 		return err
 	}
+	if envconfig.Load().Disabled {
+		log.Printf("opentelemetry: setup skipped by OTEL_SDK_DISABLED, the filters only recover the panics")
+		setupNoopFilters(cfg)
+		return nil
+	}
+	propagator, err := getPropagator(cfg.Traces)
+	if err != nil {
+		return err
+	}
 	ecosystemtrace.DefaultGetCalleeMethodInfo = getCalleeMethodInfoFunc()
+	ecosystemtrace.DefaultGetDyeingMetadata = getDyeingMetadata
 	if DefaultSampler == nil {
 		DefaultSampler = ecosystemtrace.NewSampler(
 			cfg.TenantID,
-			ecosystemtrace.SamplerConfig{
-				Fraction:           cfg.Sampler.Fraction,
-				SpecialFractions:   getSpecialFractions(cfg.Sampler.SpecialFractions),
-				SamplerServiceAddr: cfg.Sampler.SamplerServerAddr,
-				SyncInterval:       cfg.Sampler.SyncInterval,
-			},
+			cfg.Sampler.TraceConfig(),
 			func(opt *ecosystemtrace.SamplerOptions) {
 				if cfg.Traces.EnableDeferredSample {
 					opt.DefaultSamplingDecision = sdktrace.RecordOnly
 				}
 			})
+		dyeingSampler, _ = DefaultSampler.(*ecosystemtrace.Sampler)
 	}
-	DeferredSampler := ecosystemtrace.NewDeferredSampler(ecosystemtrace.DeferredSampleConfig{
+	configurator := remote.NewRemoteConfigurator(cfg.Sampler.SamplerServerAddr, 0,
+		cfg.TenantID, trpc.GlobalConfig().Server.App, trpc.GlobalConfig().Server.Server,
+	)
+	remoteConfigurator = configurator
+	deferredSampleConfig := ecosystemtrace.NewDynamicDeferredSampleConfig(ecosystemtrace.DeferredSampleConfig{
 		Enabled:            cfg.Traces.EnableDeferredSample,
 		SampleError:        cfg.Traces.DeferredSampleError,
 		SampleSlowDuration: cfg.Traces.DeferredSampleSlowDuration,
 	})
-	var isHTTPEnabled bool
-	if strings.HasPrefix(cfg.Addr, "http://") || strings.HasPrefix(cfg.Addr, "https://") {
-		isHTTPEnabled = true
+	configurator.RegisterConfigPrepareFunc(deferredSampleConfig.PrepareRemoteConfig)
+	configurator.RegisterConfigPrepareFunc(traces.PrepareRemoteConfig)
+	configurator.RegisterConfigPrepareFunc(logs.PrepareRemoteConfig)
+	DeferredSampler := ecosystemtrace.NewDynamicDeferredSampler(deferredSampleConfig)
+	tailSampleEnabled := cfg.Traces.EnableDeferredSample && cfg.Traces.TailSample.Enabled
+	if tailSampleEnabled {
+		DeferredSampler = buildTailDeferredSampler(DeferredSampler, cfg.Traces.TailSample)
 	}
 	serviceName := trpc.GlobalConfig().Server.App + "." + trpc.GlobalConfig().Server.Server
 	if cfg.Traces.EnableZPage {
 		admin.HandleFunc("/debug/tracez", zpage.GetZPageHandlerFunc())
 	}
-	err = opentelemetry.Setup(cfg.Addr,
+	setupOpts := []opentelemetry.SetupOption{
 		opentelemetry.WithTenantID(cfg.TenantID),
 		opentelemetry.WithSampler(DefaultSampler),
 		opentelemetry.WithDeferredSampler(DeferredSampler),
@@ -158,52 +209,134 @@ func (f factory) Setup(name string, configDec plugin.Decoder) error {
 			grpcprometheus.UnaryClientInterceptor,
 			packetSizeMetric())),
 		opentelemetry.WithServerOwner(cfg.Metrics.ServerOwner),
-		opentelemetry.WithHTTPEnabled(isHTTPEnabled),
 		opentelemetry.WithBatchSpanProcessorOption(buildBatchSpanProcessorOptions(cfg.Traces.ExportConfig)...),
 		opentelemetry.WithIDGenerator(opentelemetry.GlobalIDGenerator()),
 		opentelemetry.WithZPageSpanProcessor(cfg.Traces.EnableZPage),
-	)
+		opentelemetry.WithConfigurator(configurator),
+		opentelemetry.WithPropagators(propagator),
+		opentelemetry.WithTLSConfig(cfg.TLS),
+	}
+	if cfg.Addr != "" {
+		// the protocol of the empty address is resolved from the environment by Setup
+		setupOpts = append(setupOpts, opentelemetry.WithHTTPEnabled(
+			strings.HasPrefix(cfg.Addr, "http://") || strings.HasPrefix(cfg.Addr, "https://")))
+	}
+	detectors, err := resourceDetectors(cfg.Resource)
+	if err != nil {
+		return err
+	}
+	setupOpts = append(setupOpts, opentelemetry.WithResourceDetectors(detectors...))
+	if cfg.Metrics.Enabled && cfg.Metrics.Backend == metric.BackendOpenTelemetry {
+		setupOpts = append(setupOpts, opentelemetry.WithMetricEnabled(true), opentelemetry.WithMetricViews(
+			metric.OpenTelemetryViews(cfg.Metrics.ServerHistogramBuckets, cfg.Metrics.ClientHistogramBuckets)...))
+	}
+	if tailSampleEnabled {
+		setupOpts = append(setupOpts, opentelemetry.WithTailSampler(ecosystemtrace.TailSampleConfig{
+			DecisionWait: cfg.Traces.TailSample.DecisionWait,
+			MaxSpans:     cfg.Traces.TailSample.MaxSpans,
+			MaxBytes:     cfg.Traces.TailSample.MaxBytes,
+		}))
+	}
+	if c := cfg.Traces.SpanMetrics; c.Enabled {
+		setupOpts = append(setupOpts, opentelemetry.WithSpanMetrics(ecosystemtrace.SpanMetricsConfig{
+			Attributes: c.Attributes,
+			Buckets:    c.Buckets,
+			MaxSeries:  c.MaxSeries,
+		}))
+	}
+	if c := cfg.Traces.ServiceGraph; c.Enabled {
+		admin.HandleFunc("/debug/servicegraph", ecosystemtrace.ServiceGraphHandler().ServeHTTP)
+		setupOpts = append(setupOpts, opentelemetry.WithServiceGraph(ecosystemtrace.ServiceGraphConfig{
+			Buckets:    c.Buckets,
+			WaitTime:   c.WaitTime,
+			MaxPending: c.MaxPending,
+			MaxEdges:   c.MaxEdges,
+		}))
+	}
+	if spoolCfg := cfg.Traces.ExportConfig.Spool; spoolCfg.Enabled {
+		setupOpts = append(setupOpts, opentelemetry.WithSpool(spool.Config{
+			Dir:          spoolCfg.Dir,
+			MaxBytes:     spoolCfg.MaxBytes,
+			SegmentBytes: spoolCfg.SegmentBytes,
+		}))
+	}
+	for _, e := range cfg.Traces.Exporters {
+		setupOpts = append(setupOpts, opentelemetry.WithSpanDestinations(e.SpanDestination()))
+	}
+	err = opentelemetry.Setup(cfg.Addr, setupOpts...)
 	if err != nil {
 		return err
 	}
-	configurator := remote.NewRemoteConfigurator(cfg.Sampler.SamplerServerAddr, 0,
-		cfg.TenantID, trpc.GlobalConfig().Server.App, trpc.GlobalConfig().Server.Server,
-	)
 	if cfg.Metrics.Enabled {
 		prometheus.Setup(cfg.TenantID, cfg.Metrics.RegistryEndpoints,
 			metric.WithEnabledZPage(cfg.Traces.EnableZPage),
+			metric.WithEnabledServiceGraph(cfg.Traces.ServiceGraph.Enabled),
 			metric.WithConfigurator(configurator),
 			metric.WithClientHistogramBuckets(cfg.Metrics.ClientHistogramBuckets),
 			metric.WithServerHistogramBuckets(cfg.Metrics.ServerHistogramBuckets),
 			metric.WithTLSCert(cfg.Metrics.TLSCert),
 			metric.WithEnabled(true),
-			metric.WithEnabledRegister(cfg.Metrics.EnabledRegister),
+			// the metrics are pushed by OTLP, no scrape target to register
+			metric.WithEnabledRegister(cfg.Metrics.EnabledRegister && cfg.Metrics.Backend != metric.BackendOpenTelemetry),
 			metric.WithMetricsPrometheusPush(cfg.Metrics.PrometheusPush),
+			metric.WithRegistryType(cfg.Metrics.RegistryType),
+			metric.WithFileSDPath(cfg.Metrics.FileSDPath),
+			metric.WithCardinalityLimit(cfg.Metrics.CardinalityLimit),
+			metric.WithExemplar(cfg.Metrics.Exemplar),
+			metric.WithBackend(cfg.Metrics.Backend),
 		)
 	}
 	setupCodes(cfg, configurator)
-	setupFilters(cfg)
+	if err := setupMethodTemplates(cfg); err != nil {
+		return err
+	}
+	setupFilters(cfg, deferredSampleConfig)
 	return nil
 }
 
-func getSpecialFractions(fractions []config.SpecialFraction) map[string]ecosystemtrace.SpecialFraction {
-	result := make(map[string]ecosystemtrace.SpecialFraction)
-	for _, f := range fractions {
-		result[f.CalleeService] = ecosystemtrace.SpecialFraction{
-			DefaultFraction: f.DefaultFraction,
-			Methods:         getSpecialFractionMethods(f.CalleeMethods),
+// resourceDetectors returns the resource detectors of the config, none by default
+func resourceDetectors(cfg config.ResourceConfig) ([]resource.Detector, error) {
+	names := cfg.Detectors
+	if len(names) == 0 || len(names) == 1 && names[0] == "none" {
+		return nil, nil
+	}
+	return ecosystemresource.NamedDetectors(names, cfg.PodInfoDir)
+}
+
+// setupMethodTemplates registers the route templates shared by the metrics and the span names
+func setupMethodTemplates(cfg *config.Config) error {
+	for _, template := range cfg.Metrics.MethodTemplates {
+		if err := metric.RegisterRouteTemplate(template); err != nil {
+			return err
 		}
 	}
+	metric.SetAutoMethodTemplate(cfg.Metrics.AutoMethodTemplate)
+	return nil
+}
 
-	return result
+func buildTailDeferredSampler(deferredSampler ecosystemtrace.DeferredSampler,
+	c config.TailSampleConfig) ecosystemtrace.DeferredSampler {
+	samplers := []ecosystemtrace.DeferredSampler{deferredSampler, ecosystemtrace.NewDyeingDeferredSampler()}
+	for _, attr := range c.SampleAttributes {
+		if attr.Value == "" {
+			samplers = append(samplers, ecosystemtrace.NewAttributeDeferredSampler(attribute.Key(attr.Key)))
+			continue
+		}
+		samplers = append(samplers, ecosystemtrace.NewAttributeDeferredSampler(attribute.Key(attr.Key), attr.Value))
+	}
+	return ecosystemtrace.AnyDeferredSampler(samplers...)
 }
 
-func getSpecialFractionMethods(methods []config.MethodFraction) map[string]ecosystemtrace.MethodFraction {
-	result := make(map[string]ecosystemtrace.MethodFraction)
-	for _, m := range methods {
-		result[m.Method] = ecosystemtrace.MethodFraction{Fraction: m.Fraction}
+// getPropagator returns nil to use the default propagator if the propagators are not configured
+func getPropagator(cfg config.TracesConfig) (propagation.TextMapPropagator, error) {
+	if len(cfg.Propagators) == 0 && len(cfg.InjectPropagators) == 0 {
+		return nil, nil
 	}
-	return result
+	extract := cfg.Propagators
+	if len(extract) == 0 {
+		extract = []string{propagators.NameTraceContext, propagators.NameBaggage}
+	}
+	return propagators.New(extract, cfg.InjectPropagators)
 }
 
 func getCalleeMethodInfoFunc() ecosystemtrace.GetCalleeMethodInfo {
@@ -216,6 +349,18 @@ func getCalleeMethodInfoFunc() ecosystemtrace.GetCalleeMethodInfo {
 	}
 }
 
+// getDyeingMetadata returns the value of the key in the server metadata or the client metadata
+func getDyeingMetadata(ctx context.Context, key string) (string, bool) {
+	msg := trpc.Message(ctx)
+	if v, ok := msg.ServerMetaData()[key]; ok {
+		return string(v), true
+	}
+	if v, ok := msg.ClientMetaData()[key]; ok {
+		return string(v), true
+	}
+	return "", false
+}
+
 func buildBatchSpanProcessorOptions(c config.TraceExporterOption) (options []ecosystemtrace.BatchSpanProcessorOption) {
 	if c.BlockOnQueueFull {
 		options = append(options, ecosystemtrace.WithBlocking())
@@ -260,7 +405,7 @@ func getResourceAttributes(cfg *config.Config) []attribute.KeyValue {
 	return attrs
 }
 
-func setupFilters(cfg *config.Config) {
+func setupFilters(cfg *config.Config, deferredSampleConfig *ecosystemtrace.DynamicDeferredSampleConfig) {
 	filterOpts := func(o *traces.FilterOptions) {
 		o.TraceLogMode = cfg.Logs.TraceLogMode
 		o.TraceLogOption = cfg.Logs.TraceLogOption
@@ -280,10 +425,10 @@ func setupFilters(cfg *config.Config) {
 				return strings.ToValidUTF8(s, "")
 			})
 		}
-		serverFilterChain = append(serverFilterChain, prometheus.ServerFilter(prometheus.WithServerFilterTraceConfig(
-			cfg.Traces.EnableDeferredSample, cfg.Traces.DeferredSampleError, cfg.Traces.DeferredSampleSlowDuration)))
-		clientFilterChain = append(clientFilterChain, prometheus.ClientFilter(prometheus.WithClientFilterTraceConfig(
-			cfg.Traces.EnableDeferredSample, cfg.Traces.DeferredSampleError, cfg.Traces.DeferredSampleSlowDuration)))
+		serverFilterChain = append(serverFilterChain, prometheus.ServerFilter(
+			prometheus.WithServerFilterDeferredSampleConfig(deferredSampleConfig)))
+		clientFilterChain = append(clientFilterChain, prometheus.ClientFilter(
+			prometheus.WithClientFilterDeferredSampleConfig(deferredSampleConfig)))
 	}
 	serverFilterChain = append(serverFilterChain, logs.LogRecoveryFilter(logFilterOpts))
 	serverFilter := serverFilterChain.Filter
@@ -296,12 +441,44 @@ func setupFilters(cfg *config.Config) {
 	*sf = serverFilter
 	cf := &ClientFilter
 	*cf = clientFilter
+
+	streamServerFilterChain := server.StreamFilterChain{traces.StreamServerFilter(filterOpts)}
+	streamClientFilterChain := client.StreamFilterChain{traces.StreamClientFilter(filterOpts)}
+	if cfg.Metrics.Enabled {
+		streamServerFilterChain = append(streamServerFilterChain, prometheus.StreamServerFilter())
+		streamClientFilterChain = append(streamClientFilterChain, prometheus.StreamClientFilter())
+	}
+	StreamServerFilter = streamServerFilterChain.Filter
+	StreamClientFilter = streamClientFilterChain.Filter
+	server.RegisterStreamFilter(consts.PluginName, StreamServerFilter)
+	client.RegisterStreamFilter(consts.PluginName, StreamClientFilter)
+}
+
+// setupNoopFilters registers the filters recording nothing when the SDK is disabled, the server filter
+// still recovers the panics unless DisableRecovery
+func setupNoopFilters(cfg *config.Config) {
+	ServerFilter = logs.LogRecoveryFilter(func(o *logs.FilterOptions) {
+		o.DisableRecovery = cfg.Logs.DisableRecovery
+	})
+	ClientFilter = filter.NoopClientFilter
+	filter.Register(consts.PluginName, ServerFilter, ClientFilter)
+	StreamServerFilter = func(ss server.Stream, _ *server.StreamServerInfo, handler server.StreamHandler) error {
+		return handler(ss)
+	}
+	StreamClientFilter = func(ctx context.Context, desc *client.ClientStreamDesc,
+		streamer client.Streamer) (client.ClientStream, error) {
+		return streamer(ctx, desc)
+	}
+	server.RegisterStreamFilter(consts.PluginName, StreamServerFilter)
+	client.RegisterStreamFilter(consts.PluginName, StreamClientFilter)
 }
 
-// ParseConfig can be set by the user to override the config
+// ParseConfig can be set by the user to override the config,
+// the YAML takes precedence over the OTEL_* environment variables and the defaults
 var ParseConfig = func(configDec plugin.Decoder) (*config.Config, error) {
 	cfg := &config.Config{}
 	*cfg = config.DefaultConfig()
+	cfg.ApplyEnv(envconfig.Load())
 	if err := configDec.Decode(cfg); err != nil {
 		return nil, err
 	}
@@ -311,8 +488,16 @@ var ParseConfig = func(configDec plugin.Decoder) (*config.Config, error) {
 // Register plugin and filter
 func Register() {
 	filter.Register(consts.PluginName, ServerFilter, ClientFilter)
+	server.RegisterStreamFilter(consts.PluginName, StreamServerFilter)
+	client.RegisterStreamFilter(consts.PluginName, StreamClientFilter)
 	plugin.Register(consts.PluginName, &factory{})
 }
 
 var ServerFilter = filter.ServerChain{traces.ServerFilter(), prometheus.ServerFilter(), logs.LogRecoveryFilter()}.Filter
 var ClientFilter = filter.ClientChain{traces.ClientFilter(), prometheus.ClientFilter()}.Filter
+
+// StreamServerFilter the server stream filter, traces the stream by a span lasting for its lifetime
+var StreamServerFilter = server.StreamFilterChain{traces.StreamServerFilter(), prometheus.StreamServerFilter()}.Filter
+
+// StreamClientFilter the client stream filter, traces the stream by a span lasting for its lifetime
+var StreamClientFilter = client.StreamFilterChain{traces.StreamClientFilter(), prometheus.StreamClientFilter()}.Filter
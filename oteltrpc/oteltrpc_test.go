@@ -23,9 +23,7 @@ import (
 	"trpc.group/trpc-go/trpc-go/plugin"
 	pb "trpc.group/trpc-go/trpc-go/testdata/trpc/helloworld"
 
-	"trpc-system/go-opentelemetry/config"
 	"trpc-system/go-opentelemetry/oteltrpc/consts"
-	ecosystemtrace "trpc-system/go-opentelemetry/sdk/trace"
 )
 
 // language: yaml
@@ -136,46 +134,3 @@ func Test_custom_sampler(t *testing.T) {
 
 	assert.Equal(t, sdktrace.AlwaysSample().Description(), DefaultSampler.Description())
 }
-
-func Test_getSpecialFractions(t *testing.T) {
-	type args struct {
-		fractions []config.SpecialFraction
-	}
-	tests := []struct {
-		name string
-		args args
-		want map[string]ecosystemtrace.SpecialFraction
-	}{
-		{
-			name: "test config",
-			args: args{fractions: []config.SpecialFraction{
-				{
-					CalleeService:   "service1",
-					DefaultFraction: 1,
-					CalleeMethods: []config.MethodFraction{
-						{
-							Method:   "method1",
-							Fraction: 0.1,
-						},
-					},
-				},
-			}},
-			want: map[string]ecosystemtrace.SpecialFraction{
-				"service1": {
-					DefaultFraction: 1,
-					Methods: map[string]ecosystemtrace.MethodFraction{
-						"method1": {
-							Fraction: 0.1,
-						},
-					},
-				},
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, getSpecialFractions(tt.args.fractions), "getSpecialFractions(%v)",
-				tt.args.fractions)
-		})
-	}
-}
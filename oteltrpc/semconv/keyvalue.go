@@ -39,6 +39,10 @@ const (
 	CallerMethodKey  = attribute.Key("trpc.caller_method")
 	CalleeServiceKey = attribute.Key("trpc.callee_service")
 	CalleeMethodKey  = attribute.Key("trpc.callee_method")
+
+	StreamTypeKey             = attribute.Key("trpc.stream_type")
+	StreamSentMessagesKey     = attribute.Key("trpc.stream_sent_messages")
+	StreamReceivedMessagesKey = attribute.Key("trpc.stream_received_messages")
 )
 
 var once sync.Once
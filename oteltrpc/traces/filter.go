@@ -95,6 +95,7 @@ func ServerFilter(opts ...FilterOption) filter.ServerFilter {
 		if oteladmin.TraceDisabled() {
 			return f(ctx, req)
 		}
+		opt := withRemoteOptions(opt)
 
 		start := time.Now()
 		msg := trpc.Message(ctx)
@@ -200,12 +201,25 @@ func startServerSpan(ctx context.Context,
 		spanContext = spanContext.WithTraceFlags(spanContext.TraceFlags() &^ trace.FlagsSampled)
 	}
 
+	spanName := msg.ServerRPCName()
+	if route, ok := httpRoute(msg.CalleeMethod()); ok {
+		spanName = route
+	}
 	return getDefaultTracer().Start(
 		trace.ContextWithRemoteSpanContext(ctx, spanContext),
-		msg.ServerRPCName(),
+		spanName,
 		spanStartOptions...)
 }
 
+// httpRoute returns the pattern of the http path registered or learned by the metric package,
+// so that the spans are grouped identically with the metrics
+func httpRoute(method string) (string, bool) {
+	if !strings.HasPrefix(method, "/") || strings.HasPrefix(method, "/0x") {
+		return "", false
+	}
+	return metric.MethodPattern(method)
+}
+
 func needToTraceBody(span trace.Span, opt FilterOptions, err error) bool {
 	if opt.DisableTraceBody {
 		return false
@@ -267,6 +281,7 @@ func ClientFilter(opts ...FilterOption) filter.ClientFilter {
 		if oteladmin.TraceDisabled() {
 			return f(ctx, req, rsp)
 		}
+		opt := withRemoteOptions(opt)
 
 		start := time.Now()
 		msg := trpc.Message(ctx)
@@ -313,9 +328,13 @@ func startClientSpan(ctx context.Context, req interface{}, msg codec.Msg) (conte
 	if kind, ok := msg.CommonMeta()[SpanKindClient].(trace.SpanKind); ok {
 		spanKind = kind
 	}
+	method := msg.CalleeMethod()
+	if route, ok := httpRoute(method); ok {
+		method = route
+	}
 	return getDefaultTracer().Start(ctx,
 		// msg.ClientRPCName(),
-		msg.CalleeServiceName()+"/"+strings.TrimLeft(msg.CalleeMethod(), "/"),
+		msg.CalleeServiceName()+"/"+strings.TrimLeft(method, "/"),
 		trace.WithSpanKind(spanKind),
 		trace.WithAttributes(trpcsemconv.CallerServiceKey.String(msg.CallerServiceName())),
 		trace.WithAttributes(trpcsemconv.CallerMethodKey.String(metric.CleanRPCMethod(msg.CallerMethod()))),
@@ -0,0 +1,102 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package traces
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"trpc.group/trpc-go/trpc-go/codec"
+
+	"trpc-system/go-opentelemetry/sdk/propagators"
+)
+
+const (
+	// TRPCPropagatorName name of the tRPC-native propagator in propagators
+	TRPCPropagatorName = "trpc"
+	// trpcTraceContextKey the metadata key of the binary trace context:
+	// version(1) | trace id(16) | span id(8) | flags(1) | tracestate
+	trpcTraceContextKey     = "trpc-trace-context"
+	trpcTraceContextVersion = 0
+	trpcTraceContextSize    = 26
+)
+
+func init() {
+	propagators.Register(TRPCPropagatorName, TRPCPropagator{})
+}
+
+// MetaDataCarrier the carrier backed by the tRPC metadata
+type MetaDataCarrier interface {
+	MetaData() codec.MetaData
+}
+
+// TRPCPropagator propagates the trace context as binary tRPC metadata, the carrier must implement
+// MetaDataCarrier, so it is not sent as HTTP headers.
+type TRPCPropagator struct{}
+
+var _ propagation.TextMapPropagator = TRPCPropagator{}
+
+// Inject injects the span context of ctx into the metadata
+func (TRPCPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	md := metaDataOf(carrier)
+	if !sc.IsValid() || md == nil {
+		return
+	}
+	ts := sc.TraceState().String()
+	buf := make([]byte, trpcTraceContextSize, trpcTraceContextSize+len(ts))
+	buf[0] = trpcTraceContextVersion
+	traceID, spanID := sc.TraceID(), sc.SpanID()
+	copy(buf[1:17], traceID[:])
+	copy(buf[17:25], spanID[:])
+	buf[25] = byte(sc.TraceFlags())
+	md[trpcTraceContextKey] = append(buf, ts...)
+}
+
+// Extract extracts the span context from the metadata into ctx
+func (TRPCPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	md := metaDataOf(carrier)
+	buf := md[trpcTraceContextKey]
+	if len(buf) < trpcTraceContextSize || buf[0] != trpcTraceContextVersion {
+		return ctx
+	}
+	cfg := trace.SpanContextConfig{
+		TraceFlags: trace.TraceFlags(buf[25]) & trace.FlagsSampled,
+		Remote:     true,
+	}
+	copy(cfg.TraceID[:], buf[1:17])
+	copy(cfg.SpanID[:], buf[17:25])
+	if ts, err := trace.ParseTraceState(string(buf[trpcTraceContextSize:])); err == nil {
+		cfg.TraceState = ts
+	}
+	sc := trace.NewSpanContext(cfg)
+	if !sc.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the metadata key
+func (TRPCPropagator) Fields() []string {
+	return []string{trpcTraceContextKey}
+}
+
+func metaDataOf(carrier propagation.TextMapCarrier) codec.MetaData {
+	if c, ok := carrier.(MetaDataCarrier); ok {
+		return c.MetaData()
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package traces
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"trpc.group/trpc-go/trpc-go"
+	"trpc.group/trpc-go/trpc-go/codec"
+
+	"trpc-system/go-opentelemetry/sdk/propagators"
+)
+
+func TestTRPCPropagator(t *testing.T) {
+	p, ok := propagators.Get(TRPCPropagatorName)
+	require.True(t, ok)
+
+	ts, err := trace.ParseTraceState("ot=th:8")
+	require.NoError(t, err)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 15: 2},
+		SpanID:     trace.SpanID{3, 7: 4},
+		TraceFlags: trace.FlagsSampled,
+		TraceState: ts,
+	})
+	md := codec.MetaData{}
+	carrier := GetTextMapCarriers(md, trpc.Message(context.Background()))
+	p.Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+	assert.Len(t, md[trpcTraceContextKey], trpcTraceContextSize+len("ot=th:8"))
+	assert.Empty(t, carrier.Keys(), "not set as text")
+
+	got := trace.SpanContextFromContext(p.Extract(context.Background(), carrier))
+	assert.True(t, got.IsRemote())
+	assert.Equal(t, sc.TraceID(), got.TraceID())
+	assert.Equal(t, sc.SpanID(), got.SpanID())
+	assert.True(t, got.IsSampled())
+	assert.Equal(t, "th:8", got.TraceState().Get("ot"))
+
+	md[trpcTraceContextKey] = []byte{1}
+	assert.False(t, trace.SpanContextFromContext(p.Extract(context.Background(), carrier)).IsValid())
+}
@@ -0,0 +1,66 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package traces
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"trpc-system/go-opentelemetry/config"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// remoteOptions filter options pushed by the remote config, nil if unset.
+type remoteOptions struct {
+	disableTraceBody bool
+	traceLogMode     config.LogMode // LogModeDefault keeps the local mode
+}
+
+var remoteFilterOptions atomic.Value // *remoteOptions
+
+// withRemoteOptions returns the local filter options overridden by the remote config.
+func withRemoteOptions(opt FilterOptions) FilterOptions {
+	r, _ := remoteFilterOptions.Load().(*remoteOptions)
+	if r == nil {
+		return opt
+	}
+	opt.DisableTraceBody = r.disableTraceBody
+	if r.traceLogMode != config.LogModeDefault {
+		opt.TraceLogMode = r.traceLogMode
+	}
+	return opt
+}
+
+// PrepareRemoteConfig validates the trace body and trace log mode of the remote config,
+// the returned function applies them to all trace filters. The local options are restored
+// if the remote trace config is unset.
+func PrepareRemoteConfig(c *operation.Operation) (func(), error) {
+	t := c.GetTrace()
+	if t == nil {
+		return func() { remoteFilterOptions.Store((*remoteOptions)(nil)) }, nil
+	}
+	r := &remoteOptions{disableTraceBody: t.GetDisableTraceBody()}
+	if mode := t.GetTraceLogMode(); mode != "" {
+		switch strings.ToLower(mode) {
+		case "disable", "oneline", "verbose", "multiline":
+		default:
+			return nil, fmt.Errorf("unknown trace log mode %q", mode)
+		}
+		if err := r.traceLogMode.UnmarshalText([]byte(mode)); err != nil {
+			return nil, fmt.Errorf("trace log mode: %w", err)
+		}
+	}
+	return func() { remoteFilterOptions.Store(r) }, nil
+}
@@ -0,0 +1,275 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package traces
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelsemconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"trpc.group/trpc-go/trpc-go"
+	"trpc.group/trpc-go/trpc-go/client"
+	"trpc.group/trpc-go/trpc-go/codec"
+	"trpc.group/trpc-go/trpc-go/log"
+	"trpc.group/trpc-go/trpc-go/server"
+
+	trpccodes "trpc-system/go-opentelemetry/oteltrpc/codes"
+	trpcsemconv "trpc-system/go-opentelemetry/oteltrpc/semconv"
+	oteladmin "trpc-system/go-opentelemetry/pkg/admin"
+)
+
+// maxStreamMessageEvents the max message events of a stream span, the later messages are only counted
+var maxStreamMessageEvents int64 = 128
+
+// SetMaxStreamMessageEvents sets the max message events of a stream span
+func SetMaxStreamMessageEvents(limit int) {
+	atomic.StoreInt64(&maxStreamMessageEvents, int64(limit))
+}
+
+// StreamServerFilter opentelemetry server stream filter in trpc, the span lasts for the lifetime of the stream
+func StreamServerFilter(opts ...FilterOption) server.StreamFilter {
+	opt := defaultFilterOptions
+	for _, v := range opts {
+		v(&opt)
+	}
+	return func(ss server.Stream, info *server.StreamServerInfo, handler server.StreamHandler) error {
+		if oteladmin.TraceDisabled() {
+			return handler(ss)
+		}
+		opt := withRemoteOptions(opt)
+
+		start := time.Now()
+		ctx := ss.Context()
+		msg := trpc.Message(ctx)
+		md := msg.ServerMetaData()
+		if md == nil {
+			md = codec.MetaData{}
+		}
+		ctx, span := startServerSpan(ctx, nil, msg, md, opt)
+		defer span.End()
+
+		log.WithContextFields(ctx, "traceID", span.SpanContext().TraceID().String(),
+			"spanID", span.SpanContext().SpanID().String(),
+			"sampled", strconv.FormatBool(span.SpanContext().IsSampled()))
+		span.SetAttributes(trpcsemconv.StreamTypeKey.String(streamType(info.IsClientStream, info.IsServerStream)))
+
+		st := &tracedStream{ctx: ctx, span: span, opt: opt}
+		err := handler(&tracedServerStream{Stream: ss, tracedStream: st})
+
+		var code int
+		codeStr, err1 := trpccodes.GetDefaultGetCodeFunc()(ctx, nil, err)
+		if c, e := strconv.Atoi(codeStr); e == nil {
+			code = c
+		}
+		flow := buildFlowLog(msg, trace.SpanKindServer)
+		handleError(code, err1, span, flow)
+		span.SetAttributes(st.messageAttributes()...)
+		flow.Cost = time.Since(start).String()
+		doFlowLog(ctx, flow, opt)
+		return err
+	}
+}
+
+// StreamClientFilter opentelemetry client stream filter in trpc, the span lasts until the stream
+// is finished by io.EOF or an error, or the response of a client streaming RPC is received
+func StreamClientFilter(opts ...FilterOption) client.StreamFilter {
+	opt := defaultFilterOptions
+	for _, v := range opts {
+		v(&opt)
+	}
+	return func(ctx context.Context, desc *client.ClientStreamDesc,
+		streamer client.Streamer) (client.ClientStream, error) {
+		if oteladmin.TraceDisabled() {
+			return streamer(ctx, desc)
+		}
+		opt := withRemoteOptions(opt)
+
+		msg := trpc.Message(ctx)
+		md := msg.ClientMetaData()
+		if md == nil {
+			md = codec.MetaData{}
+		}
+		suppliers := GetTextMapCarriers(md, msg)
+		ctx, span := startClientSpan(ctx, nil, msg)
+		span.SetAttributes(trpcsemconv.StreamTypeKey.String(streamType(desc.ClientStreams, desc.ServerStreams)))
+		otel.GetTextMapPropagator().Inject(ctx, suppliers)
+		msg.WithClientMetaData(md)
+
+		cs := &tracedClientStream{
+			tracedStream:  &tracedStream{ctx: ctx, span: span, opt: opt},
+			msg:           msg,
+			start:         time.Now(),
+			serverStreams: desc.ServerStreams,
+			done:          make(chan struct{}),
+		}
+		s, err := streamer(ctx, desc)
+		if err != nil {
+			cs.finish(err)
+			return nil, err
+		}
+		cs.ClientStream = s
+		go func() {
+			select {
+			case <-ctx.Done():
+				cs.finish(ctx.Err())
+			case <-cs.done:
+			}
+		}()
+		return cs, nil
+	}
+}
+
+func streamType(clientStream, serverStream bool) string {
+	switch {
+	case clientStream && serverStream:
+		return "bidi_stream"
+	case clientStream:
+		return "client_stream"
+	case serverStream:
+		return "server_stream"
+	default:
+		return "unary"
+	}
+}
+
+// tracedStream records the message events of a stream span
+type tracedStream struct {
+	ctx      context.Context
+	span     trace.Span
+	opt      FilterOptions
+	sent     int64
+	received int64
+}
+
+func (s *tracedStream) sentMessage(m interface{}) {
+	s.addEvent(m, atomic.AddInt64(&s.sent, 1), otelsemconv.MessageTypeSent)
+}
+
+func (s *tracedStream) receivedMessage(m interface{}) {
+	s.addEvent(m, atomic.AddInt64(&s.received, 1), otelsemconv.MessageTypeReceived)
+}
+
+func (s *tracedStream) addEvent(m interface{}, id int64, messageType attribute.KeyValue) {
+	if id > atomic.LoadInt64(&maxStreamMessageEvents) || !needToTraceBody(s.span, s.opt, nil) {
+		return
+	}
+	addEvent(s.ctx, m, messageType, getDeadline(s.ctx), time.Now())
+}
+
+func (s *tracedStream) messageAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		trpcsemconv.StreamSentMessagesKey.Int64(atomic.LoadInt64(&s.sent)),
+		trpcsemconv.StreamReceivedMessagesKey.Int64(atomic.LoadInt64(&s.received)),
+	}
+}
+
+type tracedServerStream struct {
+	server.Stream
+	*tracedStream
+}
+
+// Context returns the context with the stream span
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg rewrite server.Stream/SendMsg method
+func (s *tracedServerStream) SendMsg(m interface{}) error {
+	if err := s.Stream.SendMsg(m); err != nil {
+		return err
+	}
+	s.sentMessage(m)
+	return nil
+}
+
+// RecvMsg rewrite server.Stream/RecvMsg method
+func (s *tracedServerStream) RecvMsg(m interface{}) error {
+	if err := s.Stream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.receivedMessage(m)
+	return nil
+}
+
+type tracedClientStream struct {
+	client.ClientStream
+	*tracedStream
+	msg           codec.Msg
+	start         time.Time
+	serverStreams bool
+	once          sync.Once
+	done          chan struct{}
+}
+
+// Context returns the context with the stream span
+func (s *tracedClientStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg rewrite client.ClientStream/SendMsg method
+func (s *tracedClientStream) SendMsg(m interface{}) error {
+	if err := s.ClientStream.SendMsg(m); err != nil {
+		s.finish(err)
+		return err
+	}
+	s.sentMessage(m)
+	return nil
+}
+
+// RecvMsg rewrite client.ClientStream/RecvMsg method
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	default:
+		s.receivedMessage(m)
+		if !s.serverStreams {
+			// the only response of a client streaming RPC
+			s.finish(nil)
+		}
+	}
+	return err
+}
+
+// finish sets the status and ends the span once
+func (s *tracedClientStream) finish(err error) {
+	s.once.Do(func() {
+		close(s.done)
+		var code int
+		codeStr, err1 := trpccodes.GetDefaultGetCodeFunc()(s.ctx, nil, err)
+		if c, e := strconv.Atoi(codeStr); e == nil {
+			code = c
+		}
+		flow := buildFlowLog(s.msg, trace.SpanKindClient)
+		handleError(code, err1, s.span, flow)
+		handleComponent(s.msg, s.span)
+		s.span.SetAttributes(s.messageAttributes()...)
+		s.span.SetAttributes(peerInfo(s.msg.RemoteAddr())...)
+		s.span.SetAttributes(hostInfo(s.msg.LocalAddr())...)
+		flow.Cost = time.Since(s.start).String()
+		doFlowLog(s.ctx, flow, s.opt)
+		s.span.End()
+	})
+}
@@ -0,0 +1,136 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package traces
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"trpc.group/trpc-go/trpc-go"
+	"trpc.group/trpc-go/trpc-go/client"
+	"trpc.group/trpc-go/trpc-go/codec"
+	pb "trpc.group/trpc-go/trpc-go/testdata/trpc/helloworld"
+
+	trpcsemconv "trpc-system/go-opentelemetry/oteltrpc/semconv"
+)
+
+type fakeClientStream struct {
+	client.ClientStream
+	ctx  context.Context
+	recv []error
+}
+
+func (s *fakeClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeClientStream) SendMsg(interface{}) error {
+	return nil
+}
+
+func (s *fakeClientStream) RecvMsg(interface{}) error {
+	err := s.recv[0]
+	s.recv = s.recv[1:]
+	return err
+}
+
+// newRecordedTracer sets the default tracer recording the ended spans
+func newRecordedTracer() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defaultTracerOnce = sync.Once{}
+	return recorder
+}
+
+func TestStreamClientFilter(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	tests := []struct {
+		name          string
+		serverStreams bool
+		recv          []error
+		wantCode      codes.Code
+	}{
+		{"server stream ends with io.EOF", true, []error{nil, nil, io.EOF}, codes.Ok},
+		{"server stream ends with error", true, []error{nil, errors.New("broken")}, codes.Error},
+		{"client stream ends with response", false, []error{nil}, codes.Ok},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newRecordedTracer()
+			ctx, msg := codec.WithNewMessage(trpc.BackgroundContext())
+			msg.WithCalleeServiceName("trpc.test.helloworld.Greeter")
+			msg.WithCalleeMethod("SayHello")
+			fake := &fakeClientStream{recv: tt.recv}
+			streamer := func(ctx context.Context, desc *client.ClientStreamDesc) (client.ClientStream, error) {
+				fake.ctx = ctx
+				return fake, nil
+			}
+			cs, err := StreamClientFilter()(ctx,
+				&client.ClientStreamDesc{ClientStreams: true, ServerStreams: tt.serverStreams}, streamer)
+			require.Nil(t, err)
+			assert.NotEmpty(t, msg.ClientMetaData()["traceparent"])
+
+			require.Nil(t, cs.SendMsg(&pb.HelloRequest{Msg: "hello"}))
+			for range tt.recv {
+				_ = cs.RecvMsg(&pb.HelloReply{})
+			}
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tt.wantCode, spans[0].Status().Code)
+			var sent, received int64
+			for _, kv := range spans[0].Attributes() {
+				switch kv.Key {
+				case trpcsemconv.StreamSentMessagesKey:
+					sent = kv.Value.AsInt64()
+				case trpcsemconv.StreamReceivedMessagesKey:
+					received = kv.Value.AsInt64()
+				}
+			}
+			assert.Equal(t, int64(1), sent)
+			assert.Equal(t, int64(len(tt.recv)-1), received)
+		})
+	}
+}
+
+func TestStreamClientFilter_StreamerError(t *testing.T) {
+	recorder := newRecordedTracer()
+	streamer := func(ctx context.Context, desc *client.ClientStreamDesc) (client.ClientStream, error) {
+		return nil, errors.New("connect failed")
+	}
+	cs, err := StreamClientFilter()(trpc.BackgroundContext(), &client.ClientStreamDesc{}, streamer)
+	assert.NotNil(t, err)
+	assert.Nil(t, cs)
+	require.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, codes.Error, recorder.Ended()[0].Status().Code)
+}
+
+func Test_streamType(t *testing.T) {
+	assert.Equal(t, "bidi_stream", streamType(true, true))
+	assert.Equal(t, "client_stream", streamType(true, false))
+	assert.Equal(t, "server_stream", streamType(false, true))
+	assert.Equal(t, "unary", streamType(false, false))
+}
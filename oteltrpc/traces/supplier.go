@@ -92,6 +92,11 @@ func (s *supplier) Keys() []string {
 	return s.keys
 }
 
+// MetaData implement MetaDataCarrier interface
+func (s *supplier) MetaData() codec.MetaData {
+	return s.md
+}
+
 type compositeTextMapCarrier []propagation.TextMapCarrier
 
 // Get implement TextMapCarrier Get interface
@@ -128,3 +133,13 @@ func (c *compositeTextMapCarrier) Keys() []string {
 	}
 	return list
 }
+
+// MetaData implement MetaDataCarrier interface, returns the metadata of the first carrier backed by it
+func (c *compositeTextMapCarrier) MetaData() codec.MetaData {
+	for _, carrier := range *c {
+		if mc, ok := carrier.(MetaDataCarrier); ok && mc.MetaData() != nil {
+			return mc.MetaData()
+		}
+	}
+	return nil
+}
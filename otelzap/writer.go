@@ -251,6 +251,12 @@ func (bp *BatchWriteSyncer) Write(p []byte) (n int, err error) {
 		return 0, nil
 	}
 	metrics.LogsLevelTotal.WithLabelValues(l.SeverityText).Inc()
+	if bp.opt.Processor != nil {
+		bp.opt.Processor.Enqueue(&logsproto.ResourceLogs{
+			Resource:  bp.rspb,
+			ScopeLogs: []*logsproto.ScopeLogs{{LogRecords: []*logsproto.LogRecord{l}}},
+		})
+	}
 	sl := &logsproto.ScopeLogs{
 		LogRecords: []*logsproto.LogRecord{l},
 	}
@@ -511,6 +517,10 @@ type BatchSyncerOptions struct {
 	// MaxPacketSize is the maximum number of packet size that will forcefully trigger a batch process.
 	// The default value of MaxPacketSize is 2M (in bytes) .
 	MaxPacketSize int
+
+	// Processor enqueues the kept log records as well, before they are batched by the syncer, e.g. the
+	// fan-out processor of the additional destinations of the logs.
+	Processor sdklog.Processor
 }
 
 // WithMaxPacketSize WithMaxPacketSize
@@ -561,3 +571,10 @@ func WithEnableSamplerError(enableSamplerError bool) BatchSyncerOption {
 		o.EnableSamplerError = enableSamplerError
 	}
 }
+
+// WithProcessor return BatchSyncerOption which to set Processor
+func WithProcessor(processor sdklog.Processor) BatchSyncerOption {
+	return func(o *BatchSyncerOptions) {
+		o.Processor = processor
+	}
+}
@@ -14,10 +14,14 @@
 package otelzap
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/resource"
+	logsproto "go.opentelemetry.io/proto/otlp/logs/v1"
 )
 
 /*
@@ -52,6 +56,42 @@ func TestReadSampledAndLevel(t *testing.T) {
 	assert.Equal(t, "debug", level)
 }
 
+// unavailableExporter fails all the exports
+type unavailableExporter struct{}
+
+func (unavailableExporter) ExportLogs(context.Context, []*logsproto.ResourceLogs) error {
+	return errors.New("unavailable")
+}
+
+func (unavailableExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// recordProcessor records the enqueued logs
+type recordProcessor struct {
+	logs []*logsproto.ResourceLogs
+}
+
+func (p *recordProcessor) Enqueue(rl *logsproto.ResourceLogs) {
+	p.logs = append(p.logs, rl)
+}
+
+func (p *recordProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestBatchWriteSyncer_Processor(t *testing.T) {
+	p := &recordProcessor{}
+	syncer := NewBatchWriteSyncer(unavailableExporter{}, resource.Empty(), WithProcessor(p))
+	n, err := syncer.Write(testData)
+	assert.NoError(t, err)
+	assert.Equal(t, len(testData), n)
+	if assert.Len(t, p.logs, 1) {
+		assert.Equal(t, "maxprocs: Leaving GOMAXPROCS=12: CPU quota undefined",
+			p.logs[0].ScopeLogs[0].LogRecords[0].Body.GetStringValue())
+	}
+}
+
 func BenchmarkConvertToRecordV1(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
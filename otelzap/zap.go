@@ -362,7 +362,7 @@ func (jw *jsonWriteSyncer) Write(p []byte) (n int, err error) {
 
 type writeSyncer struct {
 	rs        *resource.Resource
-	processor *sdklog.BatchProcessor
+	processor sdklog.Processor
 }
 
 func (w *writeSyncer) Write(p []byte) (n int, err error) {
@@ -399,7 +399,7 @@ func (w *writeSyncer) Sync() error {
 }
 
 // NewWriteSyncer create writeSyncer instance
-func NewWriteSyncer(p *sdklog.BatchProcessor, rs *resource.Resource) zapcore.WriteSyncer {
+func NewWriteSyncer(p sdklog.Processor, rs *resource.Resource) zapcore.WriteSyncer {
 	return &writeSyncer{
 		processor: p,
 		rs:        rs,
@@ -460,6 +460,11 @@ func NewJSONCore() zapcore.Core {
 		NewJSONWriteSyncer(), zapcore.DebugLevel)
 }
 
+// ToZapLevel converts the opentelemetry log level to the zap level.
+func ToZapLevel(level apilog.Level) zapcore.Level {
+	return toLevelEnabler(level)
+}
+
 func toLevelEnabler(level apilog.Level) zapcore.Level {
 	switch level {
 	case apilog.TraceLevel:
@@ -20,6 +20,7 @@ import (
 
 	"trpc-system/go-opentelemetry/pkg/zpage"
 	"trpc-system/go-opentelemetry/sdk/metric"
+	"trpc-system/go-opentelemetry/sdk/trace"
 )
 
 // Server is admin server, wrap http.Server
@@ -60,6 +61,8 @@ func newRouter(o *Options) *http.ServeMux {
 	mux := http.NewServeMux()
 	if o.enablePrometheus {
 		mux.Handle("/metrics", metric.LimitMetricsHandler())
+		mux.Handle("/metrics/http_sd", metric.HTTPSDHandler())
+		mux.Handle("/metrics/cardinality", metric.CardinalityHandler())
 	}
 	if o.enablePprof {
 		mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -78,6 +81,9 @@ func newRouter(o *Options) *http.ServeMux {
 	if o.enableZPage {
 		mux.HandleFunc("/debug/tracez", zpage.GetZPageHandlerFunc())
 	}
+	if o.enableServiceGraph {
+		mux.Handle("/debug/servicegraph", trace.ServiceGraphHandler())
+	}
 
 	return mux
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -62,3 +63,12 @@ func TestServer_Serve(t *testing.T) {
 	require.NoError(t, err)
 	require.Greater(t, len(mf), 0)
 }
+
+func Test_newRouter_ServiceGraph(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		o := defaultOptions()
+		WithEnableServiceGraph(enabled)(o)
+		_, pattern := newRouter(o).Handler(httptest.NewRequest(http.MethodGet, "/debug/servicegraph", nil))
+		require.Equal(t, enabled, pattern == "/debug/servicegraph")
+	}
+}
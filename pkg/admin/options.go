@@ -22,11 +22,12 @@ type Option func(o *Options)
 
 // Options represents config options for open telemetry http admin server
 type Options struct {
-	addr             string
-	enablePrometheus bool
-	enablePprof      bool
-	enableHotSwitch  bool
-	enableZPage      bool
+	addr               string
+	enablePrometheus   bool
+	enablePprof        bool
+	enableHotSwitch    bool
+	enableZPage        bool
+	enableServiceGraph bool
 }
 
 func (o Options) validate() error {
@@ -71,6 +72,13 @@ func WithEnableZPage(enable bool) Option {
 	}
 }
 
+// WithEnableServiceGraph set whether to serve the graph of the service graph processor
+func WithEnableServiceGraph(enable bool) Option {
+	return func(o *Options) {
+		o.enableServiceGraph = enable
+	}
+}
+
 func defaultOptions() *Options {
 	return new(Options)
 }
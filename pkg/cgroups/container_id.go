@@ -0,0 +1,56 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// _containerIDPattern matches the 64 hex container ID at the end of a cgroup path, e.g. /docker/<id>,
+// /kubepods/.../<id>, docker-<id>.scope, cri-containerd-<id>.scope and the cri-containerd:<id> of systemd
+var _containerIDPattern = regexp.MustCompile(`([0-9a-f]{64})(?:\.scope)?$`)
+
+// ContainerID returns the container ID parsed from procPathCGroup (usually at `/proc/$PID/cgroup`),
+// both cgroup v1 and v2 are supported. The ID is empty if the process is not in a container.
+func ContainerID(procPathCGroup string) (string, error) {
+	cgroupFile, err := os.Open(procPathCGroup)
+	if err != nil {
+		return "", err
+	}
+	defer cgroupFile.Close()
+
+	scanner := bufio.NewScanner(cgroupFile)
+	for scanner.Scan() {
+		// the path may contain the separator, e.g. the cri-containerd:<id> of systemd
+		fields := strings.SplitN(scanner.Text(), _cgroupSep, _csFieldCount)
+		if len(fields) != _csFieldCount {
+			return "", cgroupSubsysFormatInvalidError{scanner.Text()}
+		}
+		if m := _containerIDPattern.FindStringSubmatch(fields[_csFieldIDName]); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// ContainerIDForCurrentProcess returns the container ID of the current process.
+func ContainerIDForCurrentProcess() (string, error) {
+	return ContainerID(_procPathCGroup)
+}
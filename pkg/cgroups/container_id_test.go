@@ -0,0 +1,64 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestContainerID
+func TestContainerID(t *testing.T) {
+	testTable := []struct {
+		name            string
+		expectedID      string
+		shouldHaveError bool
+	}{
+		{
+			name:       "container",
+			expectedID: "3f2b1c0d9e8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c",
+		},
+		{
+			name:       "container-v2",
+			expectedID: "9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c3f2b1c0d9e8a7b6c5d4e3f2a1b0c",
+		},
+		{
+			name:       "no-container",
+			expectedID: "",
+		},
+		{
+			name:            "invalid-cgroup",
+			shouldHaveError: true,
+		},
+		{
+			name:            "nonexistent",
+			shouldHaveError: true,
+		},
+	}
+
+	for _, tt := range testTable {
+		id, err := ContainerID(filepath.Join(testDataProcPath, tt.name, "cgroup"))
+		if tt.shouldHaveError {
+			assert.NotNil(t, err, tt.name)
+			continue
+		}
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.expectedID, id, tt.name)
+	}
+}
@@ -0,0 +1,297 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package envconfig reads the standard OTEL_* environment variables of the OpenTelemetry specification.
+// The invalid values are logged and ignored, the unset values are left zero for the defaults of the caller.
+package envconfig
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// protocols of OTEL_EXPORTER_OTLP_PROTOCOL
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+)
+
+// compressions of OTEL_EXPORTER_OTLP_COMPRESSION
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// samplers of OTEL_TRACES_SAMPLER
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// Config the configuration from the environment variables
+type Config struct {
+	// Disabled OTEL_SDK_DISABLED, the SDK is not set up and the API is no-op
+	Disabled bool
+	// Traces, Metrics, Logs OTEL_EXPORTER_OTLP_* overridden by OTEL_EXPORTER_OTLP_{TRACES,METRICS,LOGS}_*
+	Traces  Exporter
+	Metrics Exporter
+	Logs    Exporter
+	// Sampler OTEL_TRACES_SAMPLER, SamplerArg OTEL_TRACES_SAMPLER_ARG
+	Sampler    string
+	SamplerArg string
+	// BSP OTEL_BSP_*, the batch span processor
+	BSP Batch
+	// BLRP OTEL_BLRP_*, the batch log record processor
+	BLRP Batch
+}
+
+// Exporter the OTLP exporter of a signal
+type Exporter struct {
+	// Endpoint the host:port of the collector, empty if not set
+	Endpoint string
+	// Insecure the endpoint is of http scheme or without scheme, TLS is expected for https
+	Insecure bool
+	// URLPath the URL path of the http/protobuf exporter, e.g. /v1/traces
+	URLPath string
+	// Protocol grpc or http/protobuf, empty if not set
+	Protocol string
+	// Headers the headers sent with the requests
+	Headers map[string]string
+	// Timeout the timeout of each export
+	Timeout time.Duration
+	// Compression gzip or none, empty if not set
+	Compression string
+}
+
+// Addr returns the address of the endpoint in the form of opentelemetry.Setup, host:port for gRPC
+// and http://host:port or https://host:port for HTTP, empty if the endpoint is not set
+func (e Exporter) Addr() string {
+	if e.Endpoint == "" || e.Protocol != ProtocolHTTPProtobuf {
+		return e.Endpoint
+	}
+	if e.Insecure {
+		return "http://" + e.Endpoint
+	}
+	return "https://" + e.Endpoint
+}
+
+// Batch the batch processor
+type Batch struct {
+	// ScheduleDelay the delay between two exports
+	ScheduleDelay time.Duration
+	// ExportTimeout the timeout of each export
+	ExportTimeout time.Duration
+	// MaxQueueSize the max number of the queued records
+	MaxQueueSize int
+	// MaxExportBatchSize the max number of the records of each export
+	MaxExportBatchSize int
+}
+
+// Load reads the configuration from the environment variables
+func Load() *Config {
+	generic := loadExporter("OTEL_EXPORTER_OTLP_", "")
+	return &Config{
+		Disabled:   strings.EqualFold(strings.TrimSpace(os.Getenv("OTEL_SDK_DISABLED")), "true"),
+		Traces:     mergeExporter(loadExporter("OTEL_EXPORTER_OTLP_TRACES_", "/v1/traces"), generic, "/v1/traces"),
+		Metrics:    mergeExporter(loadExporter("OTEL_EXPORTER_OTLP_METRICS_", "/v1/metrics"), generic, "/v1/metrics"),
+		Logs:       mergeExporter(loadExporter("OTEL_EXPORTER_OTLP_LOGS_", "/v1/logs"), generic, "/v1/logs"),
+		Sampler:    loadSampler(),
+		SamplerArg: strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER_ARG")),
+		BSP:        loadBatch("OTEL_BSP_"),
+		BLRP:       loadBatch("OTEL_BLRP_"),
+	}
+}
+
+// loadExporter reads the exporter variables of the prefix, the URL path of the endpoint defaults to signalPath
+func loadExporter(prefix, signalPath string) Exporter {
+	lookup := func(name string) (string, string) {
+		return prefix + name, strings.TrimSpace(os.Getenv(prefix + name))
+	}
+	var e Exporter
+	if name, v := lookup("ENDPOINT"); v != "" {
+		e.Endpoint, e.Insecure, e.URLPath = parseEndpoint(name, v, signalPath)
+	}
+	if name, v := lookup("PROTOCOL"); v != "" {
+		switch v {
+		case ProtocolGRPC, ProtocolHTTPProtobuf:
+			e.Protocol = v
+		default:
+			log.Printf("opentelemetry: ignore unsupported %s %q", name, v)
+		}
+	}
+	if name, v := lookup("HEADERS"); v != "" {
+		e.Headers = parseHeaders(name, v)
+	}
+	if name, v := lookup("TIMEOUT"); v != "" {
+		e.Timeout = parseMillis(name, v)
+	}
+	if name, v := lookup("COMPRESSION"); v != "" {
+		switch v {
+		case CompressionGzip, CompressionNone:
+			e.Compression = v
+		default:
+			log.Printf("opentelemetry: ignore unsupported %s %q", name, v)
+		}
+	}
+	return e
+}
+
+// mergeExporter returns the exporter of the signal, whose unset fields are taken from the generic one.
+// The URL path of the generic endpoint is the base of the signal path.
+func mergeExporter(e, generic Exporter, signalPath string) Exporter {
+	if e.Endpoint == "" && generic.Endpoint != "" {
+		e.Endpoint, e.Insecure = generic.Endpoint, generic.Insecure
+		e.URLPath = strings.TrimSuffix(generic.URLPath, "/") + signalPath
+	}
+	if e.Protocol == "" {
+		e.Protocol = generic.Protocol
+	}
+	if e.Headers == nil {
+		e.Headers = generic.Headers
+	}
+	if e.Timeout == 0 {
+		e.Timeout = generic.Timeout
+	}
+	if e.Compression == "" {
+		e.Compression = generic.Compression
+	}
+	return e
+}
+
+// parseEndpoint returns the host:port, whether it is insecure and the URL path of the endpoint URL
+func parseEndpoint(name, v, defaultPath string) (string, bool, string) {
+	if !strings.Contains(v, "://") {
+		return v, true, defaultPath
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		log.Printf("opentelemetry: ignore invalid %s %q", name, v)
+		return "", false, ""
+	}
+	path := u.Path
+	if path == "" || path == "/" {
+		path = defaultPath
+	}
+	return u.Host, u.Scheme == "http", path
+}
+
+// parseHeaders parses the comma separated key=value pairs, the values are URL encoded
+func parseHeaders(name, v string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			log.Printf("opentelemetry: ignore invalid header %q of %s", pair, name)
+			continue
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil {
+			log.Printf("opentelemetry: ignore invalid header %q of %s", pair, name)
+			continue
+		}
+		headers[strings.TrimSpace(kv[0])] = value
+	}
+	return headers
+}
+
+func loadSampler() string {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER")))
+	switch v {
+	case "", SamplerAlwaysOn, SamplerAlwaysOff, SamplerTraceIDRatio,
+		SamplerParentBasedAlwaysOn, SamplerParentBasedAlwaysOff, SamplerParentBasedTraceIDRatio:
+		return v
+	}
+	log.Printf("opentelemetry: ignore unsupported OTEL_TRACES_SAMPLER %q", v)
+	return ""
+}
+
+func loadBatch(prefix string) Batch {
+	return Batch{
+		ScheduleDelay:      parseMillis(prefix+"SCHEDULE_DELAY", os.Getenv(prefix+"SCHEDULE_DELAY")),
+		ExportTimeout:      parseMillis(prefix+"EXPORT_TIMEOUT", os.Getenv(prefix+"EXPORT_TIMEOUT")),
+		MaxQueueSize:       parseInt(prefix+"MAX_QUEUE_SIZE", os.Getenv(prefix+"MAX_QUEUE_SIZE")),
+		MaxExportBatchSize: parseInt(prefix+"MAX_EXPORT_BATCH_SIZE", os.Getenv(prefix+"MAX_EXPORT_BATCH_SIZE")),
+	}
+}
+
+// parseMillis parses the positive milliseconds, zero if empty or invalid
+func parseMillis(name, v string) time.Duration {
+	return time.Duration(parseInt(name, v)) * time.Millisecond
+}
+
+// parseInt parses the positive integer, zero if empty or invalid
+func parseInt(name, v string) int {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("opentelemetry: ignore invalid %s %q", name, v)
+		return 0
+	}
+	return n
+}
+
+// SamplerFraction returns the fraction of OTEL_TRACES_SAMPLER, the ratio of OTEL_TRACES_SAMPLER_ARG defaults to 1.
+// ok is false if the sampler is not set.
+func (c *Config) SamplerFraction() (fraction float64, ok bool) {
+	switch c.Sampler {
+	case SamplerAlwaysOn, SamplerParentBasedAlwaysOn:
+		return 1, true
+	case SamplerAlwaysOff, SamplerParentBasedAlwaysOff:
+		return 0, true
+	case SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+		if c.SamplerArg == "" {
+			return 1, true
+		}
+		ratio, err := strconv.ParseFloat(c.SamplerArg, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			log.Printf("opentelemetry: ignore invalid OTEL_TRACES_SAMPLER_ARG %q", c.SamplerArg)
+			return 1, true
+		}
+		return ratio, true
+	}
+	return 0, false
+}
+
+// NewSampler returns the sampler of OTEL_TRACES_SAMPLER, nil if not set
+func (c *Config) NewSampler() sdktrace.Sampler {
+	fraction, ok := c.SamplerFraction()
+	if !ok {
+		return nil
+	}
+	var sampler sdktrace.Sampler
+	switch c.Sampler {
+	case SamplerAlwaysOn, SamplerParentBasedAlwaysOn:
+		sampler = sdktrace.AlwaysSample()
+	case SamplerAlwaysOff, SamplerParentBasedAlwaysOff:
+		sampler = sdktrace.NeverSample()
+	default:
+		sampler = sdktrace.TraceIDRatioBased(fraction)
+	}
+	if strings.HasPrefix(c.Sampler, "parentbased_") {
+		sampler = sdktrace.ParentBased(sampler)
+	}
+	return sampler
+}
@@ -0,0 +1,103 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package envconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestLoad_Exporter(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/otlp/")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=a%20b, tenant=t1,invalid")
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "3000")
+	t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "none")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "https://metrics:4318/custom/path")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "logs:4317")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_TIMEOUT", "invalid")
+
+	c := Load()
+	assert.Equal(t, Exporter{
+		Endpoint:    "collector:4318",
+		Insecure:    true,
+		URLPath:     "/otlp/v1/traces",
+		Protocol:    ProtocolHTTPProtobuf,
+		Headers:     map[string]string{"api-key": "a b", "tenant": "t1"},
+		Timeout:     3 * time.Second,
+		Compression: CompressionNone,
+	}, c.Traces)
+	assert.Equal(t, "http://collector:4318", c.Traces.Addr())
+	assert.Equal(t, "metrics:4318", c.Metrics.Endpoint)
+	assert.False(t, c.Metrics.Insecure)
+	assert.Equal(t, "/custom/path", c.Metrics.URLPath)
+	assert.Equal(t, "https://metrics:4318", c.Metrics.Addr())
+	assert.Equal(t, "logs:4317", c.Logs.Addr())
+	assert.True(t, c.Logs.Insecure)
+	assert.Equal(t, 3*time.Second, c.Logs.Timeout, "the invalid value falls back to the generic one")
+	assert.False(t, c.Disabled)
+}
+
+func TestLoad_Unsupported(t *testing.T) {
+	t.Setenv("OTEL_SDK_DISABLED", "TRUE")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "ftp://collector")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+	t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "zstd")
+	t.Setenv("OTEL_TRACES_SAMPLER", "jaeger_remote")
+
+	c := Load()
+	assert.True(t, c.Disabled)
+	assert.Equal(t, Exporter{}, c.Traces)
+	assert.Equal(t, "", c.Sampler)
+	assert.Nil(t, c.NewSampler())
+}
+
+func TestLoad_Batch(t *testing.T) {
+	t.Setenv("OTEL_BSP_SCHEDULE_DELAY", "1000")
+	t.Setenv("OTEL_BSP_EXPORT_TIMEOUT", "2000")
+	t.Setenv("OTEL_BSP_MAX_QUEUE_SIZE", "100")
+	t.Setenv("OTEL_BSP_MAX_EXPORT_BATCH_SIZE", "-1")
+	t.Setenv("OTEL_BLRP_MAX_EXPORT_BATCH_SIZE", "10")
+
+	c := Load()
+	assert.Equal(t, Batch{ScheduleDelay: time.Second, ExportTimeout: 2 * time.Second, MaxQueueSize: 100}, c.BSP)
+	assert.Equal(t, Batch{MaxExportBatchSize: 10}, c.BLRP)
+}
+
+func TestConfig_Sampler(t *testing.T) {
+	for _, tt := range []struct {
+		sampler, arg string
+		fraction     float64
+		description  string
+	}{
+		{SamplerAlwaysOn, "", 1, "AlwaysOnSampler"},
+		{SamplerParentBasedAlwaysOff, "", 0, "ParentBased{root:AlwaysOffSampler"},
+		{SamplerTraceIDRatio, "0.25", 0.25, "TraceIDRatioBased{0.25}"},
+		{SamplerParentBasedTraceIDRatio, "0.5", 0.5, "ParentBased{root:TraceIDRatioBased{0.5}"},
+		{SamplerTraceIDRatio, "2", 1, "AlwaysOnSampler"},
+	} {
+		c := &Config{Sampler: tt.sampler, SamplerArg: tt.arg}
+		fraction, ok := c.SamplerFraction()
+		assert.True(t, ok)
+		assert.Equal(t, tt.fraction, fraction, tt.sampler)
+		var sampler sdktrace.Sampler = c.NewSampler()
+		assert.Contains(t, sampler.Description(), tt.description)
+	}
+	_, ok := (&Config{}).SamplerFraction()
+	assert.False(t, ok)
+}
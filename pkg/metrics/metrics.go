@@ -19,6 +19,12 @@ func init() {
 	prometheus.MustRegister(BatchProcessCounter)
 	prometheus.MustRegister(DeferredProcessCounter)
 	prometheus.MustRegister(LogsLevelTotal)
+	prometheus.MustRegister(TailSampleBufferGauge)
+	prometheus.MustRegister(TailSampleEvictedCounter)
+	prometheus.MustRegister(SpoolGauge)
+	prometheus.MustRegister(SpoolCounter)
+	prometheus.MustRegister(AdaptiveSamplingGauge)
+	prometheus.MustRegister(DestinationCounter)
 }
 
 var (
@@ -49,4 +55,58 @@ var (
 		},
 		[]string{"level"},
 	)
+	// TailSampleBufferGauge tail sample processor buffer occupancy
+	TailSampleBufferGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "opentelemetry_sdk",
+			Name:      "tail_sample_buffer",
+			Help:      "Tail Sample Buffer Occupancy",
+		},
+		[]string{"type"},
+	)
+	// TailSampleEvictedCounter tail sample processor evicted traces counter
+	TailSampleEvictedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "opentelemetry_sdk",
+			Name:      "tail_sample_evicted_total",
+			Help:      "Tail Sample Evicted Traces Total",
+		},
+		[]string{"reason"},
+	)
+	// SpoolGauge on-disk spool depth and bytes
+	SpoolGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "opentelemetry_sdk",
+			Name:      "spool",
+			Help:      "Spool Depth And Bytes",
+		},
+		[]string{"type", "telemetry"},
+	)
+	// SpoolCounter on-disk spool records counter
+	SpoolCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "opentelemetry_sdk",
+			Name:      "spool_records_total",
+			Help:      "Spool Records Total",
+		},
+		[]string{"status", "telemetry"},
+	)
+	// AdaptiveSamplingGauge effective fraction and target traces per second of the adaptive sampling
+	AdaptiveSamplingGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "opentelemetry_sdk",
+			Name:      "adaptive_sampling",
+			Help:      "Adaptive Sampling Fraction And Target",
+		},
+		[]string{"type"},
+	)
+	// DestinationCounter spans and logs of the fan-out destinations by status: success, failed, dropped and filtered
+	DestinationCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "opentelemetry_sdk",
+			Name:      "destination_total",
+			Help:      "Fan-out Destination Total",
+		},
+		[]string{"destination", "status", "telemetry"},
+	)
 )
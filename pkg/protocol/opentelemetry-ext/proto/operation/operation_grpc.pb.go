@@ -33,8 +33,10 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	OperationService_SetOperation_FullMethodName = "/opentelemetry.ext.proto.operation.OperationService/SetOperation"
-	OperationService_GetOperation_FullMethodName = "/opentelemetry.ext.proto.operation.OperationService/GetOperation"
+	OperationService_SetOperation_FullMethodName          = "/opentelemetry.ext.proto.operation.OperationService/SetOperation"
+	OperationService_GetOperation_FullMethodName          = "/opentelemetry.ext.proto.operation.OperationService/GetOperation"
+	OperationService_ReportOperationStatus_FullMethodName = "/opentelemetry.ext.proto.operation.OperationService/ReportOperationStatus"
+	OperationService_WatchOperation_FullMethodName        = "/opentelemetry.ext.proto.operation.OperationService/WatchOperation"
 )
 
 // OperationServiceClient is the client API for OperationService service.
@@ -43,6 +45,9 @@ const (
 type OperationServiceClient interface {
 	SetOperation(ctx context.Context, in *SetOperationRequest, opts ...grpc.CallOption) (*SetOperationResponse, error)
 	GetOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (*GetOperationResponse, error)
+	ReportOperationStatus(ctx context.Context, in *ReportOperationStatusRequest, opts ...grpc.CallOption) (*ReportOperationStatusResponse, error)
+	// WatchOperation 推送配置, 建立连接时及配置变更时推送完整配置
+	WatchOperation(ctx context.Context, in *WatchOperationRequest, opts ...grpc.CallOption) (OperationService_WatchOperationClient, error)
 }
 
 type operationServiceClient struct {
@@ -71,12 +76,56 @@ func (c *operationServiceClient) GetOperation(ctx context.Context, in *GetOperat
 	return out, nil
 }
 
+func (c *operationServiceClient) ReportOperationStatus(ctx context.Context, in *ReportOperationStatusRequest, opts ...grpc.CallOption) (*ReportOperationStatusResponse, error) {
+	out := new(ReportOperationStatusResponse)
+	err := c.cc.Invoke(ctx, OperationService_ReportOperationStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operationServiceClient) WatchOperation(ctx context.Context, in *WatchOperationRequest, opts ...grpc.CallOption) (OperationService_WatchOperationClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OperationService_ServiceDesc.Streams[0], OperationService_WatchOperation_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &operationServiceWatchOperationClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OperationService_WatchOperationClient interface {
+	Recv() (*WatchOperationResponse, error)
+	grpc.ClientStream
+}
+
+type operationServiceWatchOperationClient struct {
+	grpc.ClientStream
+}
+
+func (x *operationServiceWatchOperationClient) Recv() (*WatchOperationResponse, error) {
+	m := new(WatchOperationResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // OperationServiceServer is the server API for OperationService service.
 // All implementations must embed UnimplementedOperationServiceServer
 // for forward compatibility
 type OperationServiceServer interface {
 	SetOperation(context.Context, *SetOperationRequest) (*SetOperationResponse, error)
 	GetOperation(context.Context, *GetOperationRequest) (*GetOperationResponse, error)
+	ReportOperationStatus(context.Context, *ReportOperationStatusRequest) (*ReportOperationStatusResponse, error)
+	// WatchOperation 推送配置, 建立连接时及配置变更时推送完整配置
+	WatchOperation(*WatchOperationRequest, OperationService_WatchOperationServer) error
 	mustEmbedUnimplementedOperationServiceServer()
 }
 
@@ -90,6 +139,12 @@ func (UnimplementedOperationServiceServer) SetOperation(context.Context, *SetOpe
 func (UnimplementedOperationServiceServer) GetOperation(context.Context, *GetOperationRequest) (*GetOperationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOperation not implemented")
 }
+func (UnimplementedOperationServiceServer) ReportOperationStatus(context.Context, *ReportOperationStatusRequest) (*ReportOperationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportOperationStatus not implemented")
+}
+func (UnimplementedOperationServiceServer) WatchOperation(*WatchOperationRequest, OperationService_WatchOperationServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOperation not implemented")
+}
 func (UnimplementedOperationServiceServer) mustEmbedUnimplementedOperationServiceServer() {}
 
 // UnsafeOperationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -139,6 +194,45 @@ func _OperationService_GetOperation_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OperationService_ReportOperationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportOperationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperationServiceServer).ReportOperationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OperationService_ReportOperationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperationServiceServer).ReportOperationStatus(ctx, req.(*ReportOperationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperationService_WatchOperation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOperationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OperationServiceServer).WatchOperation(m, &operationServiceWatchOperationServer{stream})
+}
+
+type OperationService_WatchOperationServer interface {
+	Send(*WatchOperationResponse) error
+	grpc.ServerStream
+}
+
+type operationServiceWatchOperationServer struct {
+	grpc.ServerStream
+}
+
+func (x *operationServiceWatchOperationServer) Send(m *WatchOperationResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // OperationService_ServiceDesc is the grpc.ServiceDesc for OperationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,7 +248,17 @@ var OperationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOperation",
 			Handler:    _OperationService_GetOperation_Handler,
 		},
+		{
+			MethodName: "ReportOperationStatus",
+			Handler:    _OperationService_ReportOperationStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOperation",
+			Handler:       _OperationService_WatchOperation_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "opentelemetry-ext/proto/operation/operation.proto",
 }
@@ -96,8 +96,8 @@ type KeyValue struct {
 
 	Key      string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value    string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	Sampled  bool   `protobuf:"varint,3,opt,name=sampled,proto3" json:"sampled,omitempty"`
-	Deadline int64  `protobuf:"varint,4,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	Sampled  bool   `protobuf:"varint,3,opt,name=sampled,proto3" json:"sampled,omitempty"`   // false means never sample the value
+	Deadline int64  `protobuf:"varint,4,opt,name=deadline,proto3" json:"deadline,omitempty"` // unix timestamp in seconds when the entry expires, 0 means never
 	Comment  string `protobuf:"bytes,5,opt,name=comment,proto3" json:"comment,omitempty"`
 }
 
@@ -601,6 +601,99 @@ func (*DelSamplerResponse) Descriptor() ([]byte, []int) {
 	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP(), []int{11}
 }
 
+type WatchSamplerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WatchSamplerRequest) Reset() {
+	*x = WatchSamplerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchSamplerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSamplerRequest) ProtoMessage() {}
+
+func (x *WatchSamplerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSamplerRequest.ProtoReflect.Descriptor instead.
+func (*WatchSamplerRequest) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP(), []int{12}
+}
+
+type WatchSamplerResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Attributes []*KeyValues `protobuf:"bytes,1,rep,name=attributes,proto3" json:"attributes,omitempty"` // always sampled values, same as GetSampler
+	Rules      []*KeyValue  `protobuf:"bytes,2,rep,name=rules,proto3" json:"rules,omitempty"`           // all unexpired rules, same as GetSamplerV2
+}
+
+func (x *WatchSamplerResponse) Reset() {
+	*x = WatchSamplerResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchSamplerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSamplerResponse) ProtoMessage() {}
+
+func (x *WatchSamplerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSamplerResponse.ProtoReflect.Descriptor instead.
+func (*WatchSamplerResponse) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WatchSamplerResponse) GetAttributes() []*KeyValues {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *WatchSamplerResponse) GetRules() []*KeyValue {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
 type JudgeSamplerRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -613,7 +706,7 @@ type JudgeSamplerRequest struct {
 func (x *JudgeSamplerRequest) Reset() {
 	*x = JudgeSamplerRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[12]
+		mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -626,7 +719,7 @@ func (x *JudgeSamplerRequest) String() string {
 func (*JudgeSamplerRequest) ProtoMessage() {}
 
 func (x *JudgeSamplerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[12]
+	mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -639,7 +732,7 @@ func (x *JudgeSamplerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JudgeSamplerRequest.ProtoReflect.Descriptor instead.
 func (*JudgeSamplerRequest) Descriptor() ([]byte, []int) {
-	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP(), []int{12}
+	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *JudgeSamplerRequest) GetKey() string {
@@ -668,7 +761,7 @@ type JudgeSamplerResponse struct {
 func (x *JudgeSamplerResponse) Reset() {
 	*x = JudgeSamplerResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[13]
+		mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -681,7 +774,7 @@ func (x *JudgeSamplerResponse) String() string {
 func (*JudgeSamplerResponse) ProtoMessage() {}
 
 func (x *JudgeSamplerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[13]
+	mi := &file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -694,7 +787,7 @@ func (x *JudgeSamplerResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JudgeSamplerResponse.ProtoReflect.Descriptor instead.
 func (*JudgeSamplerResponse) Descriptor() ([]byte, []int) {
-	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP(), []int{13}
+	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *JudgeSamplerResponse) GetSampled() bool {
@@ -766,68 +859,87 @@ var file_opentelemetry_ext_proto_sampler_sampler_proto_rawDesc = []byte{
 	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x14, 0x0a, 0x12, 0x44,
 	0x65, 0x6c, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x3d, 0x0a, 0x13, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65,
-	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x22, 0x4c, 0x0a, 0x14, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70,
-	0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c,
-	0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x32, 0xec,
-	0x05, 0x0a, 0x0e, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x12, 0x75, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x12,
-	0x32, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e,
-	0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65,
-	0x72, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x33, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65,
+	0x65, 0x22, 0x15, 0x0a, 0x13, 0x57, 0x61, 0x74, 0x63, 0x68, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xa3, 0x01, 0x0a, 0x14, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x4a, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65,
+	0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x3f, 0x0a,
+	0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x6f,
+	0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x4b,
+	0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x22, 0x3d,
+	0x0a, 0x13, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x4c, 0x0a,
+	0x14, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x64, 0x12,
+	0x1a, 0x0a, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x32, 0xeb, 0x06, 0x0a, 0x0e,
+	0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x75,
+	0x0a, 0x0a, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x12, 0x32, 0x2e, 0x6f,
+	0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x53,
+	0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x33, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79,
+	0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x75, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70,
+	0x6c, 0x65, 0x72, 0x12, 0x32, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65,
 	0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61,
-	0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x75, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x53,
-	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x12, 0x32, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c,
-	0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70,
-	0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33, 0x2e, 0x6f, 0x70, 0x65,
+	0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65,
+	0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x75, 0x0a, 0x0a,
+	0x44, 0x65, 0x6c, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x12, 0x32, 0x2e, 0x6f, 0x70, 0x65,
 	0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74,
-	0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x75, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x12, 0x32, 0x2e,
-	0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78,
-	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e,
-	0x44, 0x65, 0x6c, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x33, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72,
-	0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70,
-	0x6c, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7b, 0x0a, 0x0c, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53,
-	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x12, 0x34, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c,
-	0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53, 0x61,
-	0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x6f,
-	0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x4a,
-	0x75, 0x64, 0x67, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x7b, 0x0a, 0x0c, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65,
-	0x72, 0x56, 0x32, 0x12, 0x34, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c,
+	0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33,
+	0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65,
+	0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72,
+	0x2e, 0x44, 0x65, 0x6c, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x7b, 0x0a, 0x0c, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53, 0x61, 0x6d, 0x70,
+	0x6c, 0x65, 0x72, 0x12, 0x34, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65,
 	0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61,
-	0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72,
-	0x56, 0x32, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x6f, 0x70, 0x65, 0x6e,
+	0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x4a, 0x75, 0x64, 0x67, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x6f, 0x70, 0x65, 0x6e,
 	0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x53,
-	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x56, 0x32, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x7b, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x56, 0x32,
+	0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x4a, 0x75, 0x64, 0x67,
+	0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x7b, 0x0a, 0x0c, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x56, 0x32,
 	0x12, 0x34, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79,
 	0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c,
-	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x56, 0x32, 0x52,
+	0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x56, 0x32, 0x52,
 	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c,
 	0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70,
-	0x6c, 0x65, 0x72, 0x56, 0x32, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x4b, 0x5a,
-	0x49, 0x74, 0x72, 0x70, 0x63, 0x2d, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2f, 0x67, 0x6f, 0x2d,
-	0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2f, 0x70, 0x6b,
-	0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x74,
-	0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2d, 0x65, 0x78, 0x74, 0x2f, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2f, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70,
+	0x6c, 0x65, 0x72, 0x56, 0x32, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7b, 0x0a,
+	0x0c, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x56, 0x32, 0x12, 0x34, 0x2e,
+	0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78,
+	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e,
+	0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x56, 0x32, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65,
+	0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61,
+	0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72,
+	0x56, 0x32, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7d, 0x0a, 0x0c, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x12, 0x34, 0x2e, 0x6f, 0x70, 0x65,
+	0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x2e, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x35, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79,
+	0x2e, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x73, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x72, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x4b, 0x5a, 0x49, 0x74, 0x72, 0x70,
+	0x63, 0x2d, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2f, 0x67, 0x6f, 0x2d, 0x6f, 0x70, 0x65, 0x6e,
+	0x74, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x74, 0x65, 0x6c, 0x65, 0x6d,
+	0x65, 0x74, 0x72, 0x79, 0x2d, 0x65, 0x78, 0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -842,7 +954,7 @@ func file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescGZIP() []byte {
 	return file_opentelemetry_ext_proto_sampler_sampler_proto_rawDescData
 }
 
-var file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_opentelemetry_ext_proto_sampler_sampler_proto_goTypes = []interface{}{
 	(*KeyValues)(nil),            // 0: opentelemetry.ext.proto.sampler.KeyValues
 	(*KeyValue)(nil),             // 1: opentelemetry.ext.proto.sampler.KeyValue
@@ -856,31 +968,37 @@ var file_opentelemetry_ext_proto_sampler_sampler_proto_goTypes = []interface{}{
 	(*GetSamplerV2Response)(nil), // 9: opentelemetry.ext.proto.sampler.GetSamplerV2Response
 	(*DelSamplerRequest)(nil),    // 10: opentelemetry.ext.proto.sampler.DelSamplerRequest
 	(*DelSamplerResponse)(nil),   // 11: opentelemetry.ext.proto.sampler.DelSamplerResponse
-	(*JudgeSamplerRequest)(nil),  // 12: opentelemetry.ext.proto.sampler.JudgeSamplerRequest
-	(*JudgeSamplerResponse)(nil), // 13: opentelemetry.ext.proto.sampler.JudgeSamplerResponse
+	(*WatchSamplerRequest)(nil),  // 12: opentelemetry.ext.proto.sampler.WatchSamplerRequest
+	(*WatchSamplerResponse)(nil), // 13: opentelemetry.ext.proto.sampler.WatchSamplerResponse
+	(*JudgeSamplerRequest)(nil),  // 14: opentelemetry.ext.proto.sampler.JudgeSamplerRequest
+	(*JudgeSamplerResponse)(nil), // 15: opentelemetry.ext.proto.sampler.JudgeSamplerResponse
 }
 var file_opentelemetry_ext_proto_sampler_sampler_proto_depIdxs = []int32{
 	0,  // 0: opentelemetry.ext.proto.sampler.SetSamplerRequest.attributes:type_name -> opentelemetry.ext.proto.sampler.KeyValues
 	1,  // 1: opentelemetry.ext.proto.sampler.SetSamplerV2Request.attributes:type_name -> opentelemetry.ext.proto.sampler.KeyValue
 	0,  // 2: opentelemetry.ext.proto.sampler.GetSamplerResponse.attributes:type_name -> opentelemetry.ext.proto.sampler.KeyValues
 	1,  // 3: opentelemetry.ext.proto.sampler.GetSamplerV2Response.attributes:type_name -> opentelemetry.ext.proto.sampler.KeyValue
-	2,  // 4: opentelemetry.ext.proto.sampler.SamplerService.SetSampler:input_type -> opentelemetry.ext.proto.sampler.SetSamplerRequest
-	6,  // 5: opentelemetry.ext.proto.sampler.SamplerService.GetSampler:input_type -> opentelemetry.ext.proto.sampler.GetSamplerRequest
-	10, // 6: opentelemetry.ext.proto.sampler.SamplerService.DelSampler:input_type -> opentelemetry.ext.proto.sampler.DelSamplerRequest
-	12, // 7: opentelemetry.ext.proto.sampler.SamplerService.JudgeSampler:input_type -> opentelemetry.ext.proto.sampler.JudgeSamplerRequest
-	4,  // 8: opentelemetry.ext.proto.sampler.SamplerService.SetSamplerV2:input_type -> opentelemetry.ext.proto.sampler.SetSamplerV2Request
-	8,  // 9: opentelemetry.ext.proto.sampler.SamplerService.GetSamplerV2:input_type -> opentelemetry.ext.proto.sampler.GetSamplerV2Request
-	3,  // 10: opentelemetry.ext.proto.sampler.SamplerService.SetSampler:output_type -> opentelemetry.ext.proto.sampler.SetSamplerResponse
-	7,  // 11: opentelemetry.ext.proto.sampler.SamplerService.GetSampler:output_type -> opentelemetry.ext.proto.sampler.GetSamplerResponse
-	11, // 12: opentelemetry.ext.proto.sampler.SamplerService.DelSampler:output_type -> opentelemetry.ext.proto.sampler.DelSamplerResponse
-	13, // 13: opentelemetry.ext.proto.sampler.SamplerService.JudgeSampler:output_type -> opentelemetry.ext.proto.sampler.JudgeSamplerResponse
-	5,  // 14: opentelemetry.ext.proto.sampler.SamplerService.SetSamplerV2:output_type -> opentelemetry.ext.proto.sampler.SetSamplerV2Response
-	9,  // 15: opentelemetry.ext.proto.sampler.SamplerService.GetSamplerV2:output_type -> opentelemetry.ext.proto.sampler.GetSamplerV2Response
-	10, // [10:16] is the sub-list for method output_type
-	4,  // [4:10] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	0,  // 4: opentelemetry.ext.proto.sampler.WatchSamplerResponse.attributes:type_name -> opentelemetry.ext.proto.sampler.KeyValues
+	1,  // 5: opentelemetry.ext.proto.sampler.WatchSamplerResponse.rules:type_name -> opentelemetry.ext.proto.sampler.KeyValue
+	2,  // 6: opentelemetry.ext.proto.sampler.SamplerService.SetSampler:input_type -> opentelemetry.ext.proto.sampler.SetSamplerRequest
+	6,  // 7: opentelemetry.ext.proto.sampler.SamplerService.GetSampler:input_type -> opentelemetry.ext.proto.sampler.GetSamplerRequest
+	10, // 8: opentelemetry.ext.proto.sampler.SamplerService.DelSampler:input_type -> opentelemetry.ext.proto.sampler.DelSamplerRequest
+	14, // 9: opentelemetry.ext.proto.sampler.SamplerService.JudgeSampler:input_type -> opentelemetry.ext.proto.sampler.JudgeSamplerRequest
+	4,  // 10: opentelemetry.ext.proto.sampler.SamplerService.SetSamplerV2:input_type -> opentelemetry.ext.proto.sampler.SetSamplerV2Request
+	8,  // 11: opentelemetry.ext.proto.sampler.SamplerService.GetSamplerV2:input_type -> opentelemetry.ext.proto.sampler.GetSamplerV2Request
+	12, // 12: opentelemetry.ext.proto.sampler.SamplerService.WatchSampler:input_type -> opentelemetry.ext.proto.sampler.WatchSamplerRequest
+	3,  // 13: opentelemetry.ext.proto.sampler.SamplerService.SetSampler:output_type -> opentelemetry.ext.proto.sampler.SetSamplerResponse
+	7,  // 14: opentelemetry.ext.proto.sampler.SamplerService.GetSampler:output_type -> opentelemetry.ext.proto.sampler.GetSamplerResponse
+	11, // 15: opentelemetry.ext.proto.sampler.SamplerService.DelSampler:output_type -> opentelemetry.ext.proto.sampler.DelSamplerResponse
+	15, // 16: opentelemetry.ext.proto.sampler.SamplerService.JudgeSampler:output_type -> opentelemetry.ext.proto.sampler.JudgeSamplerResponse
+	5,  // 17: opentelemetry.ext.proto.sampler.SamplerService.SetSamplerV2:output_type -> opentelemetry.ext.proto.sampler.SetSamplerV2Response
+	9,  // 18: opentelemetry.ext.proto.sampler.SamplerService.GetSamplerV2:output_type -> opentelemetry.ext.proto.sampler.GetSamplerV2Response
+	13, // 19: opentelemetry.ext.proto.sampler.SamplerService.WatchSampler:output_type -> opentelemetry.ext.proto.sampler.WatchSamplerResponse
+	13, // [13:20] is the sub-list for method output_type
+	6,  // [6:13] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_opentelemetry_ext_proto_sampler_sampler_proto_init() }
@@ -1034,7 +1152,7 @@ func file_opentelemetry_ext_proto_sampler_sampler_proto_init() {
 			}
 		}
 		file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JudgeSamplerRequest); i {
+			switch v := v.(*WatchSamplerRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1046,6 +1164,30 @@ func file_opentelemetry_ext_proto_sampler_sampler_proto_init() {
 			}
 		}
 		file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchSamplerResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JudgeSamplerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opentelemetry_ext_proto_sampler_sampler_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*JudgeSamplerResponse); i {
 			case 0:
 				return &v.state
@@ -1064,7 +1206,7 @@ func file_opentelemetry_ext_proto_sampler_sampler_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_opentelemetry_ext_proto_sampler_sampler_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -39,6 +39,7 @@ const (
 	SamplerService_JudgeSampler_FullMethodName = "/opentelemetry.ext.proto.sampler.SamplerService/JudgeSampler"
 	SamplerService_SetSamplerV2_FullMethodName = "/opentelemetry.ext.proto.sampler.SamplerService/SetSamplerV2"
 	SamplerService_GetSamplerV2_FullMethodName = "/opentelemetry.ext.proto.sampler.SamplerService/GetSamplerV2"
+	SamplerService_WatchSampler_FullMethodName = "/opentelemetry.ext.proto.sampler.SamplerService/WatchSampler"
 )
 
 // SamplerServiceClient is the client API for SamplerService service.
@@ -51,6 +52,8 @@ type SamplerServiceClient interface {
 	JudgeSampler(ctx context.Context, in *JudgeSamplerRequest, opts ...grpc.CallOption) (*JudgeSamplerResponse, error)
 	SetSamplerV2(ctx context.Context, in *SetSamplerV2Request, opts ...grpc.CallOption) (*SetSamplerV2Response, error)
 	GetSamplerV2(ctx context.Context, in *GetSamplerV2Request, opts ...grpc.CallOption) (*GetSamplerV2Response, error)
+	// WatchSampler pushes all attributes on connect and on every change
+	WatchSampler(ctx context.Context, in *WatchSamplerRequest, opts ...grpc.CallOption) (SamplerService_WatchSamplerClient, error)
 }
 
 type samplerServiceClient struct {
@@ -115,6 +118,38 @@ func (c *samplerServiceClient) GetSamplerV2(ctx context.Context, in *GetSamplerV
 	return out, nil
 }
 
+func (c *samplerServiceClient) WatchSampler(ctx context.Context, in *WatchSamplerRequest, opts ...grpc.CallOption) (SamplerService_WatchSamplerClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SamplerService_ServiceDesc.Streams[0], SamplerService_WatchSampler_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &samplerServiceWatchSamplerClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SamplerService_WatchSamplerClient interface {
+	Recv() (*WatchSamplerResponse, error)
+	grpc.ClientStream
+}
+
+type samplerServiceWatchSamplerClient struct {
+	grpc.ClientStream
+}
+
+func (x *samplerServiceWatchSamplerClient) Recv() (*WatchSamplerResponse, error) {
+	m := new(WatchSamplerResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // SamplerServiceServer is the server API for SamplerService service.
 // All implementations must embed UnimplementedSamplerServiceServer
 // for forward compatibility
@@ -125,6 +160,8 @@ type SamplerServiceServer interface {
 	JudgeSampler(context.Context, *JudgeSamplerRequest) (*JudgeSamplerResponse, error)
 	SetSamplerV2(context.Context, *SetSamplerV2Request) (*SetSamplerV2Response, error)
 	GetSamplerV2(context.Context, *GetSamplerV2Request) (*GetSamplerV2Response, error)
+	// WatchSampler pushes all attributes on connect and on every change
+	WatchSampler(*WatchSamplerRequest, SamplerService_WatchSamplerServer) error
 	mustEmbedUnimplementedSamplerServiceServer()
 }
 
@@ -150,6 +187,9 @@ func (UnimplementedSamplerServiceServer) SetSamplerV2(context.Context, *SetSampl
 func (UnimplementedSamplerServiceServer) GetSamplerV2(context.Context, *GetSamplerV2Request) (*GetSamplerV2Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSamplerV2 not implemented")
 }
+func (UnimplementedSamplerServiceServer) WatchSampler(*WatchSamplerRequest, SamplerService_WatchSamplerServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSampler not implemented")
+}
 func (UnimplementedSamplerServiceServer) mustEmbedUnimplementedSamplerServiceServer() {}
 
 // UnsafeSamplerServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -271,6 +311,27 @@ func _SamplerService_GetSamplerV2_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SamplerService_WatchSampler_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSamplerRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SamplerServiceServer).WatchSampler(m, &samplerServiceWatchSamplerServer{stream})
+}
+
+type SamplerService_WatchSamplerServer interface {
+	Send(*WatchSamplerResponse) error
+	grpc.ServerStream
+}
+
+type samplerServiceWatchSamplerServer struct {
+	grpc.ServerStream
+}
+
+func (x *samplerServiceWatchSamplerServer) Send(m *WatchSamplerResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // SamplerService_ServiceDesc is the grpc.ServiceDesc for SamplerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -303,6 +364,12 @@ var SamplerService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _SamplerService_GetSamplerV2_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSampler",
+			Handler:       _SamplerService_WatchSampler_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "opentelemetry-ext/proto/sampler/sampler.proto",
 }
@@ -0,0 +1,156 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remoteserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// fileStore keeps everything in memory and writes the whole state to a JSON file on every change
+type fileStore struct {
+	*memoryStore
+	path string
+}
+
+type fileData struct {
+	Operations []fileOperation `json:"operations"`
+	Samplers   []fileSampler   `json:"samplers"`
+}
+
+type fileOperation struct {
+	Tenant    string          `json:"tenant"`
+	Service   string          `json:"service"`
+	Operation json.RawMessage `json:"operation"`
+}
+
+type fileSampler struct {
+	Tenant string          `json:"tenant"`
+	Rule   json.RawMessage `json:"rule"`
+}
+
+// NewFileStore create a store persisted in the JSON file, the file is loaded if it exists
+func NewFileStore(path string) (Store, error) {
+	s := &fileStore{memoryStore: newMemoryStore(), path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileStore) load() error {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var data fileData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return fmt.Errorf("decode %s: %w", s.path, err)
+	}
+	for _, v := range data.Operations {
+		op := &operation.Operation{}
+		if err := protojson.Unmarshal(v.Operation, op); err != nil {
+			return fmt.Errorf("decode operation of %s/%s: %w", v.Tenant, v.Service, err)
+		}
+		s.setOperationLocked(v.Tenant, v.Service, op)
+	}
+	for _, v := range data.Samplers {
+		kv := &sampler.KeyValue{}
+		if err := protojson.Unmarshal(v.Rule, kv); err != nil {
+			return fmt.Errorf("decode sampler of %s: %w", v.Tenant, err)
+		}
+		s.setSamplersLocked(v.Tenant, []*sampler.KeyValue{kv})
+	}
+	return nil
+}
+
+// saveLocked writes the whole state to a temporary file and renames it to the store file
+func (s *fileStore) saveLocked() error {
+	var data fileData
+	for tenant, operations := range s.operations {
+		for service, op := range operations {
+			b, err := protojson.Marshal(op)
+			if err != nil {
+				return err
+			}
+			data.Operations = append(data.Operations, fileOperation{Tenant: tenant, Service: service, Operation: b})
+		}
+	}
+	for tenant, samplers := range s.samplers {
+		for _, kv := range samplers {
+			b, err := protojson.Marshal(kv)
+			if err != nil {
+				return err
+			}
+			data.Samplers = append(data.Samplers, fileSampler{Tenant: tenant, Rule: b})
+		}
+	}
+	b, err := json.MarshalIndent(&data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// SetOperation implements Store
+func (s *fileStore) SetOperation(_ context.Context, tenant, service string, op *operation.Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setOperationLocked(tenant, service, op)
+	return s.saveLocked()
+}
+
+// SetSamplers implements Store
+func (s *fileStore) SetSamplers(_ context.Context, tenant string, kvs []*sampler.KeyValue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setSamplersLocked(tenant, kvs)
+	return s.saveLocked()
+}
+
+// DeleteSampler implements Store
+func (s *fileStore) DeleteSampler(_ context.Context, tenant, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.samplers[tenant], samplerKey{key: key, value: value})
+	return s.saveLocked()
+}
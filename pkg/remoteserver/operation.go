@@ -0,0 +1,116 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remoteserver
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// SetOperation sets the operation of the service Operation.Service.Name, which is app.server
+func (s *Server) SetOperation(ctx context.Context,
+	req *operation.SetOperationRequest) (*operation.SetOperationResponse, error) {
+	tenant, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	service := req.GetOperation().GetService().GetName()
+	if app, server, ok := strings.Cut(service, "."); !ok || app == "" || server == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "service name %q is not app.server", service)
+	}
+	if err := s.store.SetOperation(ctx, tenant, service, req.GetOperation()); err != nil {
+		return nil, storeError(err)
+	}
+	s.notify()
+	return &operation.SetOperationResponse{}, nil
+}
+
+// GetOperation returns the operation of the service
+func (s *Server) GetOperation(ctx context.Context,
+	req *operation.GetOperationRequest) (*operation.GetOperationResponse, error) {
+	tenant, err := requestTenantID(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+	op, err := s.store.GetOperation(ctx, tenant, req.GetApp()+"."+req.GetServer())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &operation.GetOperationResponse{Operation: op}, nil
+}
+
+// ReportOperationStatus records the latest status reported by each instance
+func (s *Server) ReportOperationStatus(ctx context.Context,
+	req *operation.ReportOperationStatusRequest) (*operation.ReportOperationStatusResponse, error) {
+	tenant, err := requestTenantID(ctx, req.GetTenant())
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[strings.Join([]string{tenant, req.GetApp(), req.GetServer(), req.GetInstance()}, "/")] = req
+	return &operation.ReportOperationStatusResponse{}, nil
+}
+
+// OperationStatus returns the latest status reported by the instances of the service, sorted by instance
+func (s *Server) OperationStatus(tenant, app, server string) []*operation.ReportOperationStatusRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := strings.Join([]string{tenant, app, server}, "/") + "/"
+	var result []*operation.ReportOperationStatusRequest
+	for k, v := range s.statuses {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetInstance() < result[j].GetInstance()
+	})
+	return result
+}
+
+// WatchOperation pushes the operation of the service when its version differs from the client's
+func (s *Server) WatchOperation(req *operation.WatchOperationRequest,
+	stream operation.OperationService_WatchOperationServer) error {
+	ctx := stream.Context()
+	tenant, err := requestTenantID(ctx, req.GetTenant())
+	if err != nil {
+		return err
+	}
+	version := req.GetVersion()
+	for {
+		changed := s.changes()
+		op, err := s.store.GetOperation(ctx, tenant, req.GetApp()+"."+req.GetServer())
+		if err != nil {
+			return storeError(err)
+		}
+		if op != nil && op.GetVersion() != version {
+			if err := stream.Send(&operation.WatchOperationResponse{Operation: op}); err != nil {
+				return err
+			}
+			version = op.GetVersion()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
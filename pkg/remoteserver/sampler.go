@@ -0,0 +1,198 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remoteserver
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// SetSampler adds the always sampled attribute values, which never expire
+func (s *Server) SetSampler(ctx context.Context, req *sampler.SetSamplerRequest) (*sampler.SetSamplerResponse, error) {
+	var kvs []*sampler.KeyValue
+	for _, attr := range req.GetAttributes() {
+		for _, v := range attr.GetValues() {
+			kvs = append(kvs, &sampler.KeyValue{Key: attr.GetKey(), Value: v, Sampled: true})
+		}
+	}
+	if err := s.setSamplers(ctx, kvs); err != nil {
+		return nil, err
+	}
+	return &sampler.SetSamplerResponse{}, nil
+}
+
+// SetSamplerV2 adds the dyeing rules, a rule expires at its deadline
+func (s *Server) SetSamplerV2(ctx context.Context,
+	req *sampler.SetSamplerV2Request) (*sampler.SetSamplerV2Response, error) {
+	if err := s.setSamplers(ctx, req.GetAttributes()); err != nil {
+		return nil, err
+	}
+	return &sampler.SetSamplerV2Response{}, nil
+}
+
+func (s *Server) setSamplers(ctx context.Context, kvs []*sampler.KeyValue) error {
+	tenant, err := tenantID(ctx)
+	if err != nil {
+		return err
+	}
+	for _, kv := range kvs {
+		if kv.GetKey() == "" {
+			return status.Error(codes.InvalidArgument, "empty attribute key")
+		}
+	}
+	if err := s.store.SetSamplers(ctx, tenant, kvs); err != nil {
+		return storeError(err)
+	}
+	s.notify()
+	return nil
+}
+
+// DelSampler deletes the dyeing rule of the attribute value
+func (s *Server) DelSampler(ctx context.Context, req *sampler.DelSamplerRequest) (*sampler.DelSamplerResponse, error) {
+	tenant, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.DeleteSampler(ctx, tenant, req.GetKey(), req.GetValue()); err != nil {
+		return nil, storeError(err)
+	}
+	s.notify()
+	return &sampler.DelSamplerResponse{}, nil
+}
+
+// GetSampler returns the always sampled attribute values
+func (s *Server) GetSampler(ctx context.Context, _ *sampler.GetSamplerRequest) (*sampler.GetSamplerResponse, error) {
+	kvs, _, err := s.listSamplers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sampler.GetSamplerResponse{Attributes: toKeyValues(kvs)}, nil
+}
+
+// GetSamplerV2 returns all unexpired dyeing rules
+func (s *Server) GetSamplerV2(ctx context.Context,
+	_ *sampler.GetSamplerV2Request) (*sampler.GetSamplerV2Response, error) {
+	kvs, _, err := s.listSamplers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sampler.GetSamplerV2Response{Attributes: kvs}, nil
+}
+
+// JudgeSampler returns whether the attribute value is sampled
+func (s *Server) JudgeSampler(ctx context.Context,
+	req *sampler.JudgeSamplerRequest) (*sampler.JudgeSamplerResponse, error) {
+	kvs, _, err := s.listSamplers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, kv := range kvs {
+		if kv.GetKey() == req.GetKey() && kv.GetValue() == req.GetValue() {
+			return &sampler.JudgeSamplerResponse{Sampled: kv.GetSampled(), Deadline: kv.GetDeadline()}, nil
+		}
+	}
+	return &sampler.JudgeSamplerResponse{}, nil
+}
+
+// WatchSampler pushes the dyeing rules on connect, on every change and when a rule expires
+func (s *Server) WatchSampler(_ *sampler.WatchSamplerRequest, stream sampler.SamplerService_WatchSamplerServer) error {
+	ctx := stream.Context()
+	for {
+		changed := s.changes()
+		kvs, nextDeadline, err := s.listSamplers(ctx)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&sampler.WatchSamplerResponse{Attributes: toKeyValues(kvs), Rules: kvs}); err != nil {
+			return err
+		}
+		if err := s.waitSamplerChange(ctx, changed, nextDeadline); err != nil {
+			return err
+		}
+	}
+}
+
+// waitSamplerChange waits for the next change, or the next deadline if it is not zero
+func (s *Server) waitSamplerChange(ctx context.Context, changed <-chan struct{}, nextDeadline time.Time) error {
+	var expired <-chan time.Time
+	if !nextDeadline.IsZero() {
+		timer := time.NewTimer(nextDeadline.Sub(s.now()))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-changed:
+	case <-expired:
+	}
+	return nil
+}
+
+// listSamplers returns the unexpired dyeing rules of the tenant and the earliest deadline of them,
+// the expired rules are deleted.
+func (s *Server) listSamplers(ctx context.Context) ([]*sampler.KeyValue, time.Time, error) {
+	tenant, err := tenantID(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	all, err := s.store.ListSamplers(ctx, tenant)
+	if err != nil {
+		return nil, time.Time{}, storeError(err)
+	}
+	now := s.now().Unix()
+	kvs := make([]*sampler.KeyValue, 0, len(all))
+	var nextDeadline int64
+	for _, kv := range all {
+		deadline := kv.GetDeadline()
+		if deadline != 0 && deadline <= now {
+			if err := s.store.DeleteSampler(ctx, tenant, kv.GetKey(), kv.GetValue()); err != nil {
+				return nil, time.Time{}, storeError(err)
+			}
+			continue
+		}
+		if deadline != 0 && (nextDeadline == 0 || deadline < nextDeadline) {
+			nextDeadline = deadline
+		}
+		kvs = append(kvs, kv)
+	}
+	if nextDeadline == 0 {
+		return kvs, time.Time{}, nil
+	}
+	return kvs, time.Unix(nextDeadline, 0), nil
+}
+
+// toKeyValues groups the always sampled attribute values by key
+func toKeyValues(kvs []*sampler.KeyValue) []*sampler.KeyValues {
+	var result []*sampler.KeyValues
+	index := make(map[string]*sampler.KeyValues)
+	for _, kv := range kvs {
+		if !kv.GetSampled() {
+			continue
+		}
+		v, ok := index[kv.GetKey()]
+		if !ok {
+			v = &sampler.KeyValues{Key: kv.GetKey()}
+			index[kv.GetKey()] = v
+			result = append(result, v)
+		}
+		v.Values = append(v.Values, kv.GetValue())
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package remoteserver is a reference implementation of the SamplerService and the OperationService,
+// which serves the dyeing rules and the remote config of the services.
+package remoteserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// TenantHeader the header of the tenant id, the data of different tenants are isolated
+const TenantHeader = "x-tps-tenantid"
+
+var (
+	_ operation.OperationServiceServer = (*Server)(nil)
+	_ sampler.SamplerServiceServer     = (*Server)(nil)
+)
+
+// Server implements the SamplerService and the OperationService
+type Server struct {
+	operation.UnimplementedOperationServiceServer
+	sampler.UnimplementedSamplerServiceServer
+
+	store Store
+	now   func() time.Time
+
+	mu       sync.Mutex
+	changed  chan struct{}                                      // closed and replaced on every change
+	statuses map[string]*operation.ReportOperationStatusRequest // tenant/service/instance -> latest status
+}
+
+// NewServer create a new server on the store
+func NewServer(store Store) *Server {
+	return &Server{
+		store:    store,
+		now:      time.Now,
+		changed:  make(chan struct{}),
+		statuses: make(map[string]*operation.ReportOperationStatusRequest),
+	}
+}
+
+// RegisterGRPC registers the SamplerService and the OperationService on the grpc server
+func (s *Server) RegisterGRPC(gs *grpc.Server) {
+	operation.RegisterOperationServiceServer(gs, s)
+	sampler.RegisterSamplerServiceServer(gs, s)
+}
+
+// HTTPHandler returns the HTTP gateway of the SamplerService and the OperationService,
+// the routes are defined in sampler_http.yaml and operation_http.yaml
+func (s *Server) HTTPHandler(ctx context.Context) (http.Handler, error) {
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if strings.EqualFold(key, TenantHeader) {
+			return TenantHeader, true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}))
+	if err := operation.RegisterOperationServiceHandlerServer(ctx, mux, s); err != nil {
+		return nil, err
+	}
+	if err := sampler.RegisterSamplerServiceHandlerServer(ctx, mux, s); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// changes returns the channel closed on the next change
+func (s *Server) changes() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.changed
+}
+
+// notify wakes up all watch streams
+func (s *Server) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func tenantID(ctx context.Context) (string, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(TenantHeader); len(v) > 0 && v[0] != "" {
+			return v[0], nil
+		}
+	}
+	return "", status.Errorf(codes.InvalidArgument, "missing %s header", TenantHeader)
+}
+
+// requestTenantID returns the tenant id of the header, or the tenant of the request if the header is missing
+func requestTenantID(ctx context.Context, tenant string) (string, error) {
+	id, err := tenantID(ctx)
+	if err != nil {
+		if tenant == "" {
+			return "", err
+		}
+		return tenant, nil
+	}
+	if tenant != "" && tenant != id {
+		return "", status.Errorf(codes.PermissionDenied, "tenant %s mismatches the %s header", tenant, TenantHeader)
+	}
+	return id, nil
+}
+
+func storeError(err error) error {
+	return status.Error(codes.Internal, err.Error())
+}
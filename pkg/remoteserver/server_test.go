@@ -0,0 +1,192 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remoteserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+func tenantContext(tenant string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, tenant))
+}
+
+func TestServer_TenantIsolation(t *testing.T) {
+	s := NewServer(NewMemoryStore())
+	_, err := s.SetSampler(tenantContext("t1"), &sampler.SetSamplerRequest{
+		Attributes: []*sampler.KeyValues{{Key: "uid", Values: []string{"1", "2"}}},
+	})
+	require.NoError(t, err)
+
+	rsp, err := s.GetSampler(tenantContext("t1"), &sampler.GetSamplerRequest{})
+	require.NoError(t, err)
+	require.Len(t, rsp.GetAttributes(), 1)
+	assert.Equal(t, []string{"1", "2"}, rsp.GetAttributes()[0].GetValues())
+
+	rsp, err = s.GetSampler(tenantContext("t2"), &sampler.GetSamplerRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, rsp.GetAttributes())
+
+	_, err = s.GetSampler(context.Background(), &sampler.GetSamplerRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.GetOperation(tenantContext("t1"), &operation.GetOperationRequest{Tenant: "t2"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_SamplerV2(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewServer(NewMemoryStore())
+	s.now = func() time.Time { return now }
+	ctx := tenantContext("tenant")
+
+	_, err := s.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{
+		{Key: "uid", Value: "1", Sampled: true, Deadline: 1010, Comment: "debug"},
+		{Key: "uid", Value: "2", Sampled: false},
+		{Key: "uid", Value: "3", Sampled: true},
+	}})
+	require.NoError(t, err)
+
+	judge, err := s.JudgeSampler(ctx, &sampler.JudgeSamplerRequest{Key: "uid", Value: "1"})
+	require.NoError(t, err)
+	assert.True(t, judge.GetSampled())
+	assert.Equal(t, int64(1010), judge.GetDeadline())
+	judge, err = s.JudgeSampler(ctx, &sampler.JudgeSamplerRequest{Key: "uid", Value: "2"})
+	require.NoError(t, err)
+	assert.False(t, judge.GetSampled())
+
+	v1, err := s.GetSampler(ctx, &sampler.GetSamplerRequest{})
+	require.NoError(t, err)
+	require.Len(t, v1.GetAttributes(), 1)
+	assert.Equal(t, []string{"1", "3"}, v1.GetAttributes()[0].GetValues(), "unsampled rules are not in V1")
+
+	v2, err := s.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+	require.NoError(t, err)
+	require.Len(t, v2.GetAttributes(), 3)
+	assert.Equal(t, "debug", v2.GetAttributes()[0].GetComment())
+
+	now = time.Unix(1010, 0)
+	v2, err = s.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+	require.NoError(t, err)
+	require.Len(t, v2.GetAttributes(), 2, "rule expired at the deadline")
+	judge, err = s.JudgeSampler(ctx, &sampler.JudgeSamplerRequest{Key: "uid", Value: "1"})
+	require.NoError(t, err)
+	assert.False(t, judge.GetSampled())
+
+	_, err = s.DelSampler(ctx, &sampler.DelSamplerRequest{Key: "uid", Value: "3"})
+	require.NoError(t, err)
+	v2, err = s.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+	require.NoError(t, err)
+	assert.Len(t, v2.GetAttributes(), 1)
+}
+
+func TestServer_Operation(t *testing.T) {
+	s := NewServer(NewMemoryStore())
+	ctx := tenantContext("tenant")
+
+	_, err := s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+		Service: &operation.Service{Name: "server"},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+		Version: "1",
+		Service: &operation.Service{Name: "app.server"},
+	}})
+	require.NoError(t, err)
+	rsp, err := s.GetOperation(ctx, &operation.GetOperationRequest{App: "app", Server: "server"})
+	require.NoError(t, err)
+	assert.Equal(t, "1", rsp.GetOperation().GetVersion())
+
+	_, err = s.ReportOperationStatus(ctx, &operation.ReportOperationStatusRequest{
+		App: "app", Server: "server", Instance: "b", Version: "1", Applied: true,
+	})
+	require.NoError(t, err)
+	_, err = s.ReportOperationStatus(ctx, &operation.ReportOperationStatusRequest{
+		App: "app", Server: "server", Instance: "a", Version: "1", Error: "rejected",
+	})
+	require.NoError(t, err)
+	statuses := s.OperationStatus("tenant", "app", "server")
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "a", statuses[0].GetInstance())
+	assert.Equal(t, "rejected", statuses[0].GetError())
+}
+
+func TestServer_HTTPGateway(t *testing.T) {
+	s := NewServer(NewMemoryStore())
+	h, err := s.HTTPHandler(context.Background())
+	require.NoError(t, err)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v2/sampler",
+		strings.NewReader(`{"attributes":[{"key":"uid","value":"1","sampled":true}]}`))
+	require.NoError(t, err)
+	req.Header.Set(TenantHeader, "tenant")
+	rsp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	rsp.Body.Close()
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	req, err = http.NewRequest(http.MethodGet, srv.URL+"/api/sampler/judge/uid/1", nil)
+	require.NoError(t, err)
+	req.Header.Set(TenantHeader, "tenant")
+	rsp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	var judge struct {
+		Sampled bool `json:"sampled"`
+	}
+	require.NoError(t, json.NewDecoder(rsp.Body).Decode(&judge))
+	assert.True(t, judge.Sampled)
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, store.SetOperation(ctx, "tenant", "app.server", &operation.Operation{Version: "1"}))
+	require.NoError(t, store.SetSamplers(ctx, "tenant", []*sampler.KeyValue{
+		{Key: "uid", Value: "1", Sampled: true, Deadline: 100, Comment: "debug"},
+		{Key: "uid", Value: "2"},
+	}))
+	require.NoError(t, store.DeleteSampler(ctx, "tenant", "uid", "2"))
+
+	store, err = NewFileStore(path)
+	require.NoError(t, err)
+	op, err := store.GetOperation(ctx, "tenant", "app.server")
+	require.NoError(t, err)
+	assert.Equal(t, "1", op.GetVersion())
+	kvs, err := store.ListSamplers(ctx, "tenant")
+	require.NoError(t, err)
+	require.Len(t, kvs, 1)
+	assert.Equal(t, "debug", kvs[0].GetComment())
+	assert.Equal(t, int64(100), kvs[0].GetDeadline())
+}
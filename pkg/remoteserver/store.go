@@ -0,0 +1,133 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remoteserver
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// Store persists the operations and the dyeing rules of all tenants.
+// The messages passed to and returned by a Store must not be modified by the caller.
+type Store interface {
+	// GetOperation returns the operation of the service app.server, nil if not found
+	GetOperation(ctx context.Context, tenant, service string) (*operation.Operation, error)
+	// SetOperation replaces the operation of the service app.server
+	SetOperation(ctx context.Context, tenant, service string, op *operation.Operation) error
+	// ListSamplers returns all dyeing rules of the tenant, including the expired ones
+	ListSamplers(ctx context.Context, tenant string) ([]*sampler.KeyValue, error)
+	// SetSamplers adds the dyeing rules, the rule of the same key and value is replaced
+	SetSamplers(ctx context.Context, tenant string, kvs []*sampler.KeyValue) error
+	// DeleteSampler deletes the dyeing rule of the key and value
+	DeleteSampler(ctx context.Context, tenant, key, value string) error
+}
+
+type samplerKey struct {
+	key   string
+	value string
+}
+
+// memoryStore keeps everything in memory
+type memoryStore struct {
+	mu         sync.RWMutex
+	operations map[string]map[string]*operation.Operation  // tenant -> service -> operation
+	samplers   map[string]map[samplerKey]*sampler.KeyValue // tenant -> key/value -> rule
+}
+
+// NewMemoryStore create a store which keeps everything in memory
+func NewMemoryStore() Store {
+	return newMemoryStore()
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		operations: make(map[string]map[string]*operation.Operation),
+		samplers:   make(map[string]map[samplerKey]*sampler.KeyValue),
+	}
+}
+
+// GetOperation implements Store
+func (s *memoryStore) GetOperation(_ context.Context, tenant, service string) (*operation.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.operations[tenant][service], nil
+}
+
+// SetOperation implements Store
+func (s *memoryStore) SetOperation(_ context.Context, tenant, service string, op *operation.Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setOperationLocked(tenant, service, op)
+	return nil
+}
+
+func (s *memoryStore) setOperationLocked(tenant, service string, op *operation.Operation) {
+	operations := s.operations[tenant]
+	if operations == nil {
+		operations = make(map[string]*operation.Operation)
+		s.operations[tenant] = operations
+	}
+	operations[service] = proto.Clone(op).(*operation.Operation)
+}
+
+// ListSamplers implements Store
+func (s *memoryStore) ListSamplers(_ context.Context, tenant string) ([]*sampler.KeyValue, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	samplers := s.samplers[tenant]
+	result := make([]*sampler.KeyValue, 0, len(samplers))
+	for _, kv := range samplers {
+		result = append(result, kv)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].GetKey() != result[j].GetKey() {
+			return result[i].GetKey() < result[j].GetKey()
+		}
+		return result[i].GetValue() < result[j].GetValue()
+	})
+	return result, nil
+}
+
+// SetSamplers implements Store
+func (s *memoryStore) SetSamplers(_ context.Context, tenant string, kvs []*sampler.KeyValue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setSamplersLocked(tenant, kvs)
+	return nil
+}
+
+func (s *memoryStore) setSamplersLocked(tenant string, kvs []*sampler.KeyValue) {
+	samplers := s.samplers[tenant]
+	if samplers == nil {
+		samplers = make(map[samplerKey]*sampler.KeyValue)
+		s.samplers[tenant] = samplers
+	}
+	for _, kv := range kvs {
+		samplers[samplerKey{key: kv.GetKey(), value: kv.GetValue()}] = proto.Clone(kv).(*sampler.KeyValue)
+	}
+}
+
+// DeleteSampler implements Store
+func (s *memoryStore) DeleteSampler(_ context.Context, tenant, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.samplers[tenant], samplerKey{key: key, value: value})
+	return nil
+}
@@ -0,0 +1,200 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package tlsconfig builds the client TLS config of the exporters, the certificates are reloaded from disk
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval the default interval to check the certificate files for changes
+const DefaultReloadInterval = time.Minute
+
+// Config the client TLS of the exporters
+type Config struct {
+	// Enabled use TLS, the exporters are insecure if false
+	Enabled bool `yaml:"enabled"`
+	// CAFile the PEM CA bundle to verify the server, default the system roots
+	CAFile string `yaml:"ca_file"`
+	// CertFile, KeyFile the PEM client certificate and key for mTLS
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ServerName overrides the server name to verify, default the host of the exporter address
+	ServerName string `yaml:"server_name"`
+	// MinVersion the min TLS version, 1.0, 1.1, 1.2 or 1.3, default 1.2
+	MinVersion string `yaml:"min_version"`
+	// InsecureSkipVerify skips the verification of the server certificate
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+	// ReloadInterval the interval to check the certificate files for changes on handshake,
+	// default DefaultReloadInterval, negative to disable the reload
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// New returns the client TLS config of the exporter address, e.g. host:port or https://host:port, whose host
+// is verified unless cfg.ServerName overrides it. The client certificate and the CA bundle are loaded now and
+// reloaded on handshake after they are changed on disk, the last loaded ones are kept if the reload fails.
+func New(cfg Config, addr string) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("tlsconfig: unknown min version %q", cfg.MinVersion)
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("tlsconfig: cert_file and key_file must be set together")
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = hostOf(addr)
+	}
+	r := &reloader{cfg: cfg, interval: cfg.ReloadInterval, now: time.Now}
+	if r.interval == 0 {
+		r.interval = DefaultReloadInterval
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checked = r.now()
+	c := &tls.Config{
+		ServerName:         cfg.ServerName,
+		MinVersion:         minVersion,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CertFile != "" {
+		c.GetClientCertificate = r.clientCertificate
+	}
+	if cfg.CAFile != "" && !cfg.InsecureSkipVerify {
+		// the chain is verified by verifyConnection against the reloaded CA bundle instead
+		c.InsecureSkipVerify = true
+		c.VerifyConnection = r.verifyConnection
+	}
+	return c, nil
+}
+
+// hostOf returns the host of the address with or without the scheme
+func hostOf(addr string) string {
+	if i := strings.Index(addr, "://"); i >= 0 {
+		addr = addr[i+len("://"):]
+	}
+	if i := strings.IndexByte(addr, '/'); i >= 0 {
+		addr = addr[:i]
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// reloader holds the certificates loaded from the files, and reloads them if the files are modified
+type reloader struct {
+	cfg      Config
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	checked  time.Time
+	modTimes [3]time.Time
+	cert     *tls.Certificate
+	roots    *x509.CertPool
+}
+
+// load reads the files if any of them is modified
+func (r *reloader) load() error {
+	var modTimes [3]time.Time
+	for i, name := range []string{r.cfg.CAFile, r.cfg.CertFile, r.cfg.KeyFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return fmt.Errorf("tlsconfig: %w", err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+	if r.cert != nil || r.roots != nil {
+		if modTimes == r.modTimes {
+			return nil
+		}
+	}
+	var roots *x509.CertPool
+	if r.cfg.CAFile != "" {
+		pem, err := os.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("tlsconfig: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tlsconfig: no certificate in %s", r.cfg.CAFile)
+		}
+	}
+	var cert *tls.Certificate
+	if r.cfg.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("tlsconfig: %w", err)
+		}
+		cert = &c
+	}
+	r.roots, r.cert, r.modTimes = roots, cert, modTimes
+	return nil
+}
+
+// current returns the loaded certificates, and reloads them at most once per interval
+func (r *reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := r.now(); r.interval > 0 && now.Sub(r.checked) >= r.interval {
+		r.checked = now
+		if err := r.load(); err != nil {
+			log.Printf("opentelemetry: reload tls certificates: %v", err)
+		}
+	}
+	return r.cert, r.roots
+}
+
+func (r *reloader) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+func (r *reloader) verifyConnection(cs tls.ConnectionState) error {
+	_, roots := r.current()
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tlsconfig: no server certificate")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       r.cfg.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,157 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, server bool) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		if server {
+			template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+			template.DNSNames = []string{"localhost"}
+			template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeFile(t *testing.T, path string, content []byte, modTime time.Time) {
+	require.Nil(t, os.WriteFile(path, content, 0o600))
+	require.Nil(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestNew_MTLS(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, false)
+	serverCert := newTestCert(t, "server", ca, true)
+	clientCert := newTestCert(t, "client", ca, false)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	pair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	require.Nil(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "cert.pem"),
+		filepath.Join(dir, "key.pem")
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, caFile, ca.certPEM, modTime)
+	writeFile(t, certFile, clientCert.certPEM, modTime)
+	writeFile(t, keyFile, clientCert.keyPEM, modTime)
+
+	get := func(c *tls.Config) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: c}}
+		rsp, err := client.Get(server.URL)
+		if err == nil {
+			rsp.Body.Close()
+		}
+		return err
+	}
+	c, err := New(Config{Enabled: true, CAFile: caFile, CertFile: certFile, KeyFile: keyFile,
+		ServerName: "localhost", ReloadInterval: time.Nanosecond}, server.URL)
+	require.Nil(t, err)
+	assert.Nil(t, get(c))
+
+	c, err = New(Config{Enabled: true, CAFile: caFile, CertFile: certFile, KeyFile: keyFile,
+		ServerName: "other.host"}, server.URL)
+	require.Nil(t, err)
+	assert.NotNil(t, get(c), "server name mismatch")
+
+	c, err = New(Config{Enabled: true, CAFile: caFile}, server.URL)
+	require.Nil(t, err)
+	assert.NotNil(t, get(c), "client certificate required")
+
+	// the CA bundle replaced on disk is reloaded
+	c, err = New(Config{Enabled: true, CAFile: caFile, CertFile: certFile, KeyFile: keyFile,
+		ReloadInterval: time.Nanosecond}, server.URL)
+	require.Nil(t, err)
+	assert.Nil(t, get(c))
+	writeFile(t, caFile, newTestCert(t, "other", nil, false).certPEM, time.Now())
+	assert.NotNil(t, get(c))
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New(Config{MinVersion: "2.0"}, "")
+	assert.NotNil(t, err)
+	_, err = New(Config{CertFile: "cert.pem"}, "")
+	assert.NotNil(t, err)
+	_, err = New(Config{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, "")
+	assert.NotNil(t, err)
+
+	c, err := New(Config{MinVersion: "1.3", InsecureSkipVerify: true}, "https://collector:4318/v1/traces")
+	require.Nil(t, err)
+	assert.Equal(t, "collector", c.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), c.MinVersion)
+	assert.True(t, c.InsecureSkipVerify)
+	assert.Nil(t, c.VerifyConnection)
+}
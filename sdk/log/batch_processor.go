@@ -35,6 +35,16 @@ const (
 	DefaultBlockOnQueueFull     = false
 )
 
+var _ Processor = (*BatchProcessor)(nil)
+
+// Processor processes the logs of the Logger, e.g. BatchProcessor and FanOutProcessor
+type Processor interface {
+	// Enqueue enqueues the logs, it should not block unless configured to
+	Enqueue(rl *logsproto.ResourceLogs)
+	// Shutdown is invoked during service shutdown.
+	Shutdown(ctx context.Context) error
+}
+
 // BatchProcessor is a component that accepts spans and metrics, places them
 // into batches and sends downstream.
 type BatchProcessor struct {
@@ -46,6 +56,8 @@ type BatchProcessor struct {
 
 	timer *time.Timer
 
+	o BatchProcessorOptions
+
 	exporter Exporter
 	stopCh   chan struct{}
 	stopWait sync.WaitGroup
@@ -55,13 +67,23 @@ type BatchProcessor struct {
 }
 
 // NewBatchProcessor return BatchProcessor
-func NewBatchProcessor(exporter Exporter) *BatchProcessor {
+func NewBatchProcessor(exporter Exporter, options ...BatchProcessorOption) *BatchProcessor {
+	o := BatchProcessorOptions{
+		MaxQueueSize:       DefaultMaxQueueSize,
+		BatchTimeout:       DefaultBatchTimeout,
+		MaxExportBatchSize: DefaultMaxExportBatchSize,
+		BlockOnQueueFull:   DefaultBlockOnQueueFull,
+	}
+	for _, opt := range options {
+		opt(&o)
+	}
 	bp := &BatchProcessor{
+		o:        o,
 		exporter: exporter,
-		batch:    make([]*logsproto.ResourceLogs, 0, DefaultMaxExportBatchSize),
-		queue:    make(chan *logsproto.ResourceLogs, DefaultMaxQueueSize),
+		batch:    make([]*logsproto.ResourceLogs, 0, o.MaxExportBatchSize),
+		queue:    make(chan *logsproto.ResourceLogs, o.MaxQueueSize),
 		stopCh:   make(chan struct{}),
-		timer:    time.NewTimer(DefaultBatchTimeout),
+		timer:    time.NewTimer(o.BatchTimeout),
 		debugger: debug.NewUTF8Debugger(),
 	}
 	bp.stopWait.Add(1)
@@ -108,7 +130,7 @@ func (bp *BatchProcessor) Enqueue(rl *logsproto.ResourceLogs) {
 	default:
 	}
 
-	if DefaultBlockOnQueueFull {
+	if bp.o.BlockOnQueueFull {
 		bp.queue <- rl
 		return
 	}
@@ -117,12 +139,13 @@ func (bp *BatchProcessor) Enqueue(rl *logsproto.ResourceLogs) {
 	case bp.queue <- rl:
 	default:
 		metrics.BatchProcessCounter.WithLabelValues("dropped", "logs").Add(1)
+		bp.countDestination("dropped", recordCount(rl))
 		atomic.AddUint32(&bp.dropped, 1)
 	}
 }
 
 func (bp *BatchProcessor) shouldProcessInBatch() bool {
-	if len(bp.batch) == DefaultMaxExportBatchSize {
+	if len(bp.batch) == bp.o.MaxExportBatchSize {
 		return true
 	}
 	if bp.batchedSize >= DefaultMaxBatchedPacketSize {
@@ -154,23 +177,42 @@ func (bp *BatchProcessor) processQueue() {
 }
 
 func (bp *BatchProcessor) export() {
-	bp.timer.Reset(DefaultBatchTimeout)
+	bp.timer.Reset(bp.o.BatchTimeout)
 	if len(bp.batch) > 0 {
-		err := bp.exporter.ExportLogs(context.Background(), bp.batch)
+		ctx, cancel := bp.exportContext()
+		err := bp.exporter.ExportLogs(ctx, bp.batch)
+		cancel()
 		if err != nil {
 			otel.Handle(err)
 			metrics.BatchProcessCounter.WithLabelValues("failed", "logs").Add(1)
+			bp.countDestination("failed", recordCount(bp.batch...))
 			if bp.debugger.Enabled() {
 				bp.debugger.DebugLogsInvalidUTF8(err, bp.batch)
 			}
 		} else {
 			metrics.BatchProcessCounter.WithLabelValues("success", "logs").Add(1)
+			bp.countDestination("success", recordCount(bp.batch...))
 		}
 		bp.batch = bp.batch[:0]
 		bp.batchedSize = 0
 	}
 }
 
+// countDestination counts the log records of the fan-out destination by status
+func (bp *BatchProcessor) countDestination(status string, n int) {
+	if bp.o.Destination != "" {
+		metrics.DestinationCounter.WithLabelValues(bp.o.Destination, status, "logs").Add(float64(n))
+	}
+}
+
+// exportContext returns the context of an export, which is canceled after ExportTimeout if set
+func (bp *BatchProcessor) exportContext() (context.Context, context.CancelFunc) {
+	if bp.o.ExportTimeout > 0 {
+		return context.WithTimeout(context.Background(), bp.o.ExportTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
 func (bp *BatchProcessor) drainQueue() {
 	for {
 		select {
@@ -181,7 +223,7 @@ func (bp *BatchProcessor) drainQueue() {
 			}
 
 			bp.batch = append(bp.batch, sd)
-			if len(bp.batch) == DefaultMaxExportBatchSize {
+			if len(bp.batch) == bp.o.MaxExportBatchSize {
 				bp.export()
 			}
 		default:
@@ -211,11 +253,18 @@ type BatchProcessorOptions struct {
 	// The default value of MaxExportBatchSize is 512.
 	MaxExportBatchSize int
 
+	// ExportTimeout is the maximum duration of each export, no timeout if zero.
+	ExportTimeout time.Duration
+
 	// BlockOnQueueFull blocks onEnd() and onStart() method if the queue is full
 	// AND if BlockOnQueueFull is set to true.
 	// Blocking option should be used carefully as it can severely affect the performance of an
 	// application.
 	BlockOnQueueFull bool
+
+	// Destination is the name of the fan-out destination of the processor, whose exported, failed and
+	// dropped log records are counted by metrics.DestinationCounter as well if not empty.
+	Destination string
 }
 
 // WithMaxQueueSize return BatchProcessorOption which to set MaxQueueSize
@@ -239,6 +288,13 @@ func WithBatchTimeout(delay time.Duration) BatchProcessorOption {
 	}
 }
 
+// WithExportTimeout return BatchProcessorOption which to set ExportTimeout
+func WithExportTimeout(timeout time.Duration) BatchProcessorOption {
+	return func(o *BatchProcessorOptions) {
+		o.ExportTimeout = timeout
+	}
+}
+
 // WithBlocking return BatchProcessorOption which to set BlockOnQueueFull
 func WithBlocking() BatchProcessorOption {
 	return func(o *BatchProcessorOptions) {
@@ -246,6 +302,13 @@ func WithBlocking() BatchProcessorOption {
 	}
 }
 
+// WithDestination return BatchProcessorOption which to set Destination
+func WithDestination(name string) BatchProcessorOption {
+	return func(o *BatchProcessorOptions) {
+		o.Destination = name
+	}
+}
+
 func calcLogSize(l *logsproto.ResourceLogs) int {
 	if l == nil {
 		return 0
@@ -0,0 +1,186 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package log
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	commonproto "go.opentelemetry.io/proto/otlp/common/v1"
+	logsproto "go.opentelemetry.io/proto/otlp/logs/v1"
+
+	"trpc-system/go-opentelemetry/api/log"
+	"trpc-system/go-opentelemetry/pkg/metrics"
+)
+
+var _ Processor = (*FanOutProcessor)(nil)
+
+// Filter reports whether the log record is exported to the destination
+type Filter func(*logsproto.LogRecord) bool
+
+// SeverityFilter returns the filter of the log records of the level or higher. The severity is read from
+// the severity number, or else the severity text, the records of unknown severity pass.
+func SeverityFilter(level log.Level) Filter {
+	min := toSeverityNumber(level)
+	return func(r *logsproto.LogRecord) bool {
+		number := r.SeverityNumber
+		if number == logsproto.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED {
+			l, err := log.ParseLevel(r.SeverityText)
+			if err != nil {
+				return true
+			}
+			number = toSeverityNumber(l)
+		}
+		return number >= min
+	}
+}
+
+// AttributeFilter returns the filter of the log records with all the attributes,
+// the values are compared by their string form, e.g. the int 200 matches "200"
+func AttributeFilter(kvs ...attribute.KeyValue) Filter {
+	return func(r *logsproto.LogRecord) bool {
+		for _, kv := range kvs {
+			if !hasAttribute(r.Attributes, string(kv.Key), kv.Value.Emit()) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AllFilters returns the filter of the log records passing all the filters, nil filters are skipped
+func AllFilters(filters ...Filter) Filter {
+	return func(r *logsproto.LogRecord) bool {
+		for _, f := range filters {
+			if f != nil && !f(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func hasAttribute(attrs []*commonproto.KeyValue, key, value string) bool {
+	for _, a := range attrs {
+		if a != nil && a.Key == key {
+			return anyValueString(a.Value) == value
+		}
+	}
+	return false
+}
+
+// anyValueString returns the string form of the scalar value as attribute.Value.Emit, empty for the others
+func anyValueString(v *commonproto.AnyValue) string {
+	switch v := v.GetValue().(type) {
+	case *commonproto.AnyValue_StringValue:
+		return v.StringValue
+	case *commonproto.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonproto.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonproto.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// Destination a named destination of the fan-out processor
+type Destination struct {
+	// Name the destination label of metrics.DestinationCounter
+	Name string
+	// Processor batches and exports the log records of the destination, usually a BatchProcessor of its own
+	// exporter created with WithDestination(Name), which counts the exported, failed and dropped log records
+	Processor Processor
+	// Filter the log records exported to the destination, all if nil
+	Filter Filter
+}
+
+// FanOutProcessor sends the logs to multiple destinations, each with its own processor and filter
+type FanOutProcessor struct {
+	destinations []Destination
+}
+
+// NewFanOutProcessor create a new fan-out processor of the destinations
+func NewFanOutProcessor(destinations ...Destination) *FanOutProcessor {
+	return &FanOutProcessor{destinations: destinations}
+}
+
+// Enqueue sends the log records to the destinations whose filters they pass, the others count them as filtered.
+func (p *FanOutProcessor) Enqueue(rl *logsproto.ResourceLogs) {
+	for _, d := range p.destinations {
+		if d.Filter == nil {
+			d.Processor.Enqueue(rl)
+			continue
+		}
+		filtered, n := filterResourceLogs(rl, d.Filter)
+		if n > 0 {
+			metrics.DestinationCounter.WithLabelValues(d.Name, "filtered", "logs").Add(float64(n))
+		}
+		if filtered != nil {
+			d.Processor.Enqueue(filtered)
+		}
+	}
+}
+
+// Shutdown shuts down the processors of all destinations, the first error is returned.
+func (p *FanOutProcessor) Shutdown(ctx context.Context) error {
+	var err error
+	for _, d := range p.destinations {
+		if e := d.Processor.Shutdown(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// filterResourceLogs returns the copy of the logs of the records passing the filter, nil if none passes,
+// and the number of the records filtered out. The logs are copied as they are shared by the destinations.
+func filterResourceLogs(rl *logsproto.ResourceLogs, filter Filter) (*logsproto.ResourceLogs, int) {
+	var filtered int
+	scopeLogs := make([]*logsproto.ScopeLogs, 0, len(rl.ScopeLogs))
+	for _, sl := range rl.ScopeLogs {
+		records := make([]*logsproto.LogRecord, 0, len(sl.LogRecords))
+		for _, r := range sl.LogRecords {
+			if filter != nil && !filter(r) {
+				filtered++
+				continue
+			}
+			records = append(records, r)
+		}
+		if len(records) > 0 {
+			scopeLogs = append(scopeLogs, &logsproto.ScopeLogs{
+				Scope:      sl.Scope,
+				LogRecords: records,
+				SchemaUrl:  sl.SchemaUrl,
+			})
+		}
+	}
+	if len(scopeLogs) == 0 {
+		return nil, filtered
+	}
+	return &logsproto.ResourceLogs{Resource: rl.Resource, ScopeLogs: scopeLogs, SchemaUrl: rl.SchemaUrl}, filtered
+}
+
+// recordCount returns the number of the log records of the logs
+func recordCount(logs ...*logsproto.ResourceLogs) int {
+	var n int
+	for _, rl := range logs {
+		for _, sl := range rl.GetScopeLogs() {
+			n += len(sl.GetLogRecords())
+		}
+	}
+	return n
+}
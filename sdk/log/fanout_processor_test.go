@@ -0,0 +1,143 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	commonproto "go.opentelemetry.io/proto/otlp/common/v1"
+	logsproto "go.opentelemetry.io/proto/otlp/logs/v1"
+
+	"trpc-system/go-opentelemetry/api/log"
+	"trpc-system/go-opentelemetry/pkg/metrics"
+)
+
+// recordProcessor records the bodies of the enqueued log records
+type recordProcessor struct {
+	mu       sync.Mutex
+	bodies   []string
+	shutdown error
+}
+
+func (p *recordProcessor) Enqueue(rl *logsproto.ResourceLogs) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, sl := range rl.ScopeLogs {
+		for _, r := range sl.LogRecords {
+			p.bodies = append(p.bodies, r.Body.GetStringValue())
+		}
+	}
+}
+
+func (p *recordProcessor) Shutdown(context.Context) error {
+	return p.shutdown
+}
+
+func (p *recordProcessor) records() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.bodies
+}
+
+func newRecord(body string, level log.Level, kvs ...*commonproto.KeyValue) *logsproto.LogRecord {
+	return &logsproto.LogRecord{
+		SeverityNumber: toSeverityNumber(level),
+		SeverityText:   string(level),
+		Body:           &commonproto.AnyValue{Value: &commonproto.AnyValue_StringValue{StringValue: body}},
+		Attributes:     kvs,
+	}
+}
+
+func stringKeyValue(key, value string) *commonproto.KeyValue {
+	return &commonproto.KeyValue{
+		Key:   key,
+		Value: &commonproto.AnyValue{Value: &commonproto.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func TestFanOutProcessor(t *testing.T) {
+	all, errs, tagged := &recordProcessor{}, &recordProcessor{}, &recordProcessor{}
+	logger := NewLogger(WithProcessor(NewFanOutProcessor(
+		Destination{Name: "all", Processor: all},
+		Destination{Name: "errors", Processor: errs, Filter: SeverityFilter(log.ErrorLevel)},
+		Destination{Name: "tagged", Processor: tagged, Filter: AllFilters(nil,
+			AttributeFilter(attribute.String("env", "prod"), attribute.Int("code", 500)))},
+	)), WithLevelEnable(log.DebugLevel))
+	filtered := metrics.DestinationCounter.WithLabelValues("errors", "filtered", "logs")
+	before := testutil.ToFloat64(filtered)
+
+	ctx := context.Background()
+	logger.Log(ctx, "ok", log.WithLevel(log.InfoLevel), log.WithFields(attribute.String("env", "prod")))
+	logger.Log(ctx, "failed", log.WithLevel(log.ErrorLevel),
+		log.WithFields(attribute.String("env", "prod"), attribute.String("code", "500")))
+
+	assert.Equal(t, []string{"ok", "failed"}, all.records())
+	assert.Equal(t, []string{"failed"}, errs.records())
+	assert.Equal(t, []string{"failed"}, tagged.records())
+	assert.Equal(t, float64(1), testutil.ToFloat64(filtered)-before)
+	assert.NoError(t, logger.Shutdown(ctx))
+}
+
+func TestFanOutProcessor_Shutdown(t *testing.T) {
+	failed := &recordProcessor{shutdown: errors.New("unavailable")}
+	ok := &recordProcessor{}
+	p := NewFanOutProcessor(Destination{Name: "ok", Processor: ok}, Destination{Name: "failed", Processor: failed})
+	assert.EqualError(t, p.Shutdown(context.Background()), "unavailable")
+}
+
+func TestSeverityFilter(t *testing.T) {
+	filter := SeverityFilter(log.WarnLevel)
+	assert.False(t, filter(newRecord("info", log.InfoLevel)))
+	assert.True(t, filter(newRecord("warn", log.WarnLevel)))
+	assert.True(t, filter(newRecord("error", log.ErrorLevel)))
+	assert.False(t, filter(&logsproto.LogRecord{SeverityText: "debug"}), "read from the severity text")
+	assert.True(t, filter(&logsproto.LogRecord{SeverityText: "fatal"}), "read from the severity text")
+	assert.True(t, filter(&logsproto.LogRecord{SeverityText: "unknown"}), "unknown severity passes")
+}
+
+func Test_filterResourceLogs(t *testing.T) {
+	rl := &logsproto.ResourceLogs{
+		SchemaUrl: "schema",
+		ScopeLogs: []*logsproto.ScopeLogs{
+			{LogRecords: []*logsproto.LogRecord{newRecord("info", log.InfoLevel)}},
+			{LogRecords: []*logsproto.LogRecord{
+				newRecord("error", log.ErrorLevel, stringKeyValue("env", "prod")),
+				newRecord("warn", log.WarnLevel),
+			}},
+		},
+	}
+
+	filtered, n := filterResourceLogs(rl, SeverityFilter(log.WarnLevel))
+	require.NotNil(t, filtered)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "schema", filtered.SchemaUrl)
+	require.Len(t, filtered.ScopeLogs, 1, "the scopes of no records are dropped")
+	assert.Len(t, filtered.ScopeLogs[0].LogRecords, 2)
+	assert.Len(t, rl.ScopeLogs[1].LogRecords, 2, "the logs are copied")
+
+	filtered, n = filterResourceLogs(rl, AttributeFilter(attribute.String("env", "test")))
+	assert.Nil(t, filtered)
+	assert.Equal(t, 3, n)
+
+	filtered, n = filterResourceLogs(rl, nil)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 3, recordCount(filtered))
+}
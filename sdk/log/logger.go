@@ -15,6 +15,7 @@ package log
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -36,9 +37,12 @@ func NewLogger(opts ...LoggerOption) *Logger {
 		o(options)
 	}
 
-	return &Logger{
+	l := &Logger{
 		opts: options,
 	}
+	l.levelNumber = int32(options.LevelNumber)
+	l.localLevel = options.LevelEnabled
+	return l
 }
 
 // LoggerOptions logger options detail
@@ -47,7 +51,7 @@ type LoggerOptions struct {
 	Resource *resource.Resource
 
 	// Processor export logs
-	Processor *BatchProcessor
+	Processor Processor
 
 	// LevelEnabled enabled level
 	LevelEnabled log.Level
@@ -101,9 +105,29 @@ func WithBatcher(batcher *BatchProcessor) LoggerOption {
 	}
 }
 
+// WithProcessor setting Processor, e.g. the FanOutProcessor of multiple destinations
+func WithProcessor(processor Processor) LoggerOption {
+	return func(options *LoggerOptions) {
+		options.Processor = processor
+	}
+}
+
 // Logger logger impl
 type Logger struct {
 	opts *LoggerOptions
+	// levelNumber enabled level number, it can be changed at runtime by SetLevel
+	levelNumber int32
+	localLevel  log.Level
+}
+
+// SetLevel changes the enabled level at runtime.
+func (l *Logger) SetLevel(level log.Level) {
+	atomic.StoreInt32(&l.levelNumber, int32(toSeverityNumber(level)))
+}
+
+// levelEnabled returns the enabled level number.
+func (l *Logger) levelEnabled() logsproto.SeverityNumber {
+	return logsproto.SeverityNumber(atomic.LoadInt32(&l.levelNumber))
 }
 
 // Shutdown is invoked during service shutdown.
@@ -124,13 +148,14 @@ func (l *Logger) Log(ctx context.Context, msg string, opts ...log.Option) {
 	}
 	sampled := false
 	levelNumber := toSeverityNumber(cfg.Level)
-	if l.opts.EnableSampler && levelNumber >= l.opts.LevelNumber {
+	levelEnabled := l.levelEnabled()
+	if l.opts.EnableSampler && levelNumber >= levelEnabled {
 		if trace.SpanFromContext(ctx).SpanContext().IsSampled() ||
 			(l.opts.EnableSamplerError && levelNumber >= logsproto.SeverityNumber_SEVERITY_NUMBER_ERROR) {
 			sampled = true
 		}
 	}
-	if !l.opts.EnableSampler && levelNumber >= l.opts.LevelNumber {
+	if !l.opts.EnableSampler && levelNumber >= levelEnabled {
 		sampled = true
 	}
 
@@ -0,0 +1,34 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package log
+
+import (
+	"fmt"
+
+	"trpc-system/go-opentelemetry/api/log"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// PrepareRemoteConfig validates the log level of the remote config, the returned function applies it.
+// The local level is restored if the remote level is empty.
+func (l *Logger) PrepareRemoteConfig(config *operation.Operation) (func(), error) {
+	level := l.localLevel
+	if text := config.GetLog().GetLevel(); text != "" {
+		var err error
+		if level, err = log.ParseLevel(text); err != nil {
+			return nil, fmt.Errorf("log level: %w", err)
+		}
+	}
+	return func() { l.SetLevel(level) }, nil
+}
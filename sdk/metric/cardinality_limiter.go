@@ -0,0 +1,237 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowLabelValue the label value which the values exceeding the cardinality budgets are folded into
+const OverflowLabelValue = "__overflow__"
+
+// CardinalityLimitConfig the cardinality budgets of the RPC started and handled metrics, the values exceeding
+// the budgets are folded into OverflowLabelValue when recorded, so the totals remain correct
+type CardinalityLimitConfig struct {
+	// MetricLimit the max number of series of each metric, the new series beyond it are recorded to the series
+	// whose label values are all OverflowLabelValue, default 500
+	MetricLimit int `yaml:"metric_limit"`
+	// LabelLimits the max number of distinct values of each label, e.g. callee_method: 200,
+	// the new values beyond it are recorded as OverflowLabelValue
+	LabelLimits map[string]int `yaml:"label_limits"`
+}
+
+// CardinalityStatus the status of the cardinality limiter of a metric, served by CardinalityHandler
+type CardinalityStatus struct {
+	Name     string                   `json:"name"`
+	Series   int                      `json:"series"`
+	Limit    int                      `json:"limit"`
+	Overflow uint64                   `json:"overflow"`
+	Labels   []LabelCardinalityStatus `json:"labels,omitempty"`
+}
+
+// LabelCardinalityStatus the status of the label budget
+type LabelCardinalityStatus struct {
+	Name     string `json:"name"`
+	Values   int    `json:"values"`
+	Limit    int    `json:"limit"`
+	Overflow uint64 `json:"overflow"`
+}
+
+// cardinalityLimiter folds the label values of a metric exceeding the budgets, the accepted series and values are
+// kept until the process exits, so a series never moves between the overflow and its own
+type cardinalityLimiter struct {
+	name        string
+	labels      []string
+	metricLimit int
+	// labelLimits the budget by label index, 0 for no budget
+	labelLimits []int
+
+	mu     sync.RWMutex
+	series map[string]struct{}
+	// values the accepted values by label index, nil if the label has no budget
+	values []map[string]struct{}
+
+	overflow       uint64
+	labelOverflows []uint64
+}
+
+func newCardinalityLimiter(name string, labels []string, cfg CardinalityLimitConfig) *cardinalityLimiter {
+	l := &cardinalityLimiter{
+		name:           name,
+		labels:         labels,
+		metricLimit:    cfg.MetricLimit,
+		labelLimits:    make([]int, len(labels)),
+		series:         make(map[string]struct{}),
+		values:         make([]map[string]struct{}, len(labels)),
+		labelOverflows: make([]uint64, len(labels)),
+	}
+	if l.metricLimit <= 0 {
+		l.metricLimit = rpcMetricsCardinalityLimit
+	}
+	for i, label := range labels {
+		if limit := cfg.LabelLimits[label]; limit > 0 {
+			l.labelLimits[i] = limit
+			l.values[i] = make(map[string]struct{}, limit)
+		}
+	}
+	return l
+}
+
+// limit returns the label values to record, labelValues is not modified
+func (l *cardinalityLimiter) limit(labelValues []string) []string {
+	if l == nil {
+		return labelValues
+	}
+	key := strings.Join(labelValues, "\xff")
+	l.mu.RLock()
+	_, ok := l.series[key]
+	l.mu.RUnlock()
+	if ok {
+		return labelValues
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.series[key]; ok {
+		return labelValues
+	}
+	values, folded := labelValues, false
+	for i := 0; i < len(values) && i < len(l.labelLimits); i++ {
+		if l.values[i] == nil {
+			continue
+		}
+		if _, ok := l.values[i][values[i]]; ok {
+			continue
+		}
+		if len(l.values[i]) < l.labelLimits[i] {
+			l.values[i][values[i]] = struct{}{}
+			continue
+		}
+		if !folded {
+			values, folded = append([]string(nil), labelValues...), true
+		}
+		values[i] = OverflowLabelValue
+		l.foldLabel(i)
+	}
+	if folded {
+		key = strings.Join(values, "\xff")
+		if _, ok := l.series[key]; ok {
+			return values
+		}
+	}
+	if len(l.series) < l.metricLimit {
+		l.series[key] = struct{}{}
+		return values
+	}
+	l.foldSeries()
+	values = make([]string, len(labelValues))
+	for i := range values {
+		values[i] = OverflowLabelValue
+	}
+	return values
+}
+
+func (l *cardinalityLimiter) foldLabel(i int) {
+	if atomic.AddUint64(&l.labelOverflows[i], 1) == 1 {
+		log.Printf("opentelemetry: metric '%s' label '%s' high cardinality, limit:%d, fold into %s",
+			l.name, l.labels[i], l.labelLimits[i], OverflowLabelValue)
+	}
+	cardinalityFoldedTotal.WithLabelValues(l.name, l.labels[i]).Inc()
+}
+
+func (l *cardinalityLimiter) foldSeries() {
+	if atomic.AddUint64(&l.overflow, 1) == 1 {
+		log.Printf("opentelemetry: metric '%s' high cardinality, limit:%d, fold into %s",
+			l.name, l.metricLimit, OverflowLabelValue)
+	}
+	cardinalityFoldedTotal.WithLabelValues(l.name, "").Inc()
+}
+
+func (l *cardinalityLimiter) status() CardinalityStatus {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	s := CardinalityStatus{
+		Name:     l.name,
+		Series:   len(l.series),
+		Limit:    l.metricLimit,
+		Overflow: atomic.LoadUint64(&l.overflow),
+	}
+	for i, label := range l.labels {
+		if l.values[i] == nil {
+			continue
+		}
+		s.Labels = append(s.Labels, LabelCardinalityStatus{
+			Name:     label,
+			Values:   len(l.values[i]),
+			Limit:    l.labelLimits[i],
+			Overflow: atomic.LoadUint64(&l.labelOverflows[i]),
+		})
+	}
+	return s
+}
+
+// rpcCardinalityLimiters the limiters of the RPC started and handled metrics
+type rpcCardinalityLimiters struct {
+	serverStarted *cardinalityLimiter
+	serverHandled *cardinalityLimiter
+	clientStarted *cardinalityLimiter
+	clientHandled *cardinalityLimiter
+}
+
+var defaultRPCLimiters atomic.Value
+
+// setupRPCCardinalityLimiters creates the limiters by the label names of the RPC metrics
+func setupRPCCardinalityLimiters(cfg CardinalityLimitConfig) {
+	defaultRPCLimiters.Store(&rpcCardinalityLimiters{
+		serverStarted: newCardinalityLimiter(
+			"rpc_server_started_total", serverLabelsOption(ServerStartedCounter), cfg),
+		serverHandled: newCardinalityLimiter(
+			"rpc_server_handled_total", serverLabelsOption(ServerHandledCounter), cfg),
+		clientStarted: newCardinalityLimiter(
+			"rpc_client_started_total", clientLabelsOption(ClientStartedCounter), cfg),
+		clientHandled: newCardinalityLimiter(
+			"rpc_client_handled_total", clientLabelsOption(ClientHandledCounter), cfg),
+	})
+}
+
+// getRPCLimiters returns the limiters, the limiters are nil before setup
+func getRPCLimiters() *rpcCardinalityLimiters {
+	if l, ok := defaultRPCLimiters.Load().(*rpcCardinalityLimiters); ok {
+		return l
+	}
+	return &rpcCardinalityLimiters{}
+}
+
+// CardinalityHandler serves the status of the cardinality limiters of the RPC metrics as JSON
+func CardinalityHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var statuses []CardinalityStatus
+		l := getRPCLimiters()
+		for _, v := range []*cardinalityLimiter{l.serverStarted, l.serverHandled, l.clientStarted, l.clientHandled} {
+			if v != nil {
+				statuses = append(statuses, v.status())
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if statuses == nil {
+			statuses = []CardinalityStatus{}
+		}
+		_ = json.NewEncoder(w).Encode(statuses)
+	})
+}
@@ -0,0 +1,82 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardinalityLimiter_Label(t *testing.T) {
+	l := newCardinalityLimiter("test", []string{"service", "method"}, CardinalityLimitConfig{
+		LabelLimits: map[string]int{"method": 2},
+	})
+	assert.Equal(t, []string{"s1", "m1"}, l.limit([]string{"s1", "m1"}))
+	assert.Equal(t, []string{"s2", "m2"}, l.limit([]string{"s2", "m2"}))
+	in := []string{"s1", "m3"}
+	assert.Equal(t, []string{"s1", OverflowLabelValue}, l.limit(in))
+	assert.Equal(t, []string{"s1", "m3"}, in)
+	assert.Equal(t, []string{"s1", OverflowLabelValue}, l.limit([]string{"s1", "m4"}))
+	// the accepted values are kept
+	assert.Equal(t, []string{"s3", "m1"}, l.limit([]string{"s3", "m1"}))
+
+	s := l.status()
+	assert.Equal(t, 4, s.Series)
+	assert.Equal(t, []LabelCardinalityStatus{{Name: "method", Values: 2, Limit: 2, Overflow: 2}}, s.Labels)
+	assert.Equal(t, float64(2), testutil.ToFloat64(cardinalityFoldedTotal.WithLabelValues("test", "method")))
+}
+
+func TestCardinalityLimiter_Metric(t *testing.T) {
+	l := newCardinalityLimiter("test_metric", []string{"service", "method"}, CardinalityLimitConfig{MetricLimit: 2})
+	assert.Equal(t, []string{"s1", "m1"}, l.limit([]string{"s1", "m1"}))
+	assert.Equal(t, []string{"s1", "m2"}, l.limit([]string{"s1", "m2"}))
+	assert.Equal(t, []string{OverflowLabelValue, OverflowLabelValue}, l.limit([]string{"s1", "m3"}))
+	assert.Equal(t, []string{"s1", "m1"}, l.limit([]string{"s1", "m1"}))
+
+	s := l.status()
+	assert.Equal(t, 2, s.Series)
+	assert.Equal(t, uint64(1), s.Overflow)
+
+	var nilLimiter *cardinalityLimiter
+	assert.Equal(t, []string{"s1"}, nilLimiter.limit([]string{"s1"}))
+}
+
+func TestCardinalityLimiter_Reporter(t *testing.T) {
+	resetDefaultRegistry(t)
+	require.Nil(t, setupRPCRecorder(Config{CardinalityLimit: CardinalityLimitConfig{
+		LabelLimits: map[string]int{"callee_method": 1},
+	}}))
+	defer defaultRPCLimiters.Store(&rpcCardinalityLimiters{})
+
+	NewServerReporter("trpc", "caller", "/caller", "callee", "m1").Handled(context.Background(), "0")
+	NewServerReporter("trpc", "caller", "/caller", "callee", "m2").Handled(context.Background(), "0")
+	NewServerReporter("trpc", "caller", "/caller", "callee", "m3").Handled(context.Background(), "0")
+	assert.Equal(t, float64(2), testutil.ToFloat64(
+		serverStartedCounter.WithLabelValues("trpc", "caller", "default_pattern_method", "callee", OverflowLabelValue)))
+
+	w := httptest.NewRecorder()
+	CardinalityHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics/cardinality", nil))
+	var statuses []CardinalityStatus
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 4)
+	assert.Equal(t, "rpc_server_started_total", statuses[0].Name)
+	assert.Equal(t, uint64(2), statuses[0].Labels[0].Overflow)
+}
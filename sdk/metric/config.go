@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"time"
 
+	otelmetric "go.opentelemetry.io/otel/metric"
+
 	"trpc-system/go-opentelemetry/sdk/remote"
 )
 
@@ -30,8 +32,14 @@ type Config struct {
 	Enabled bool `yaml:"enabled"`
 	// EnabledRegister default enabled
 	EnabledRegister bool `yaml:"enable_register"`
-	// RegistryEndpoints registry addrs
+	// RegistryEndpoints registry addrs, the agent addr for consul
 	RegistryEndpoints []string `yaml:"registry_endpoints"`
+	// RegistryType etcd (default), consul, file_sd, http_sd or the type registered by RegisterRegistryFactory
+	RegistryType string `yaml:"registry_type"`
+	// Registry the user defined registry, takes precedence over RegistryType
+	Registry Registry `yaml:"-"`
+	// FileSDPath the file written by the file_sd registry
+	FileSDPath string `yaml:"file_sd_path"`
 	// TLS credentials
 	TLSCert TLSCert `yaml:"tls_cert"`
 	// TTL Time to live
@@ -58,6 +66,16 @@ type Config struct {
 	PrometheusPush PrometheusPushConfig `yaml:"prometheus_push"`
 	// EnabledZPage zPage option
 	EnabledZPage bool
+	// EnabledServiceGraph serves the service graph by the admin server started by the metrics
+	EnabledServiceGraph bool
+	// CardinalityLimit the cardinality budgets of the RPC metrics
+	CardinalityLimit CardinalityLimitConfig `yaml:"cardinality_limit"`
+	// Exemplar the exemplar policies of the RPC handled metrics
+	Exemplar ExemplarConfig `yaml:"exemplar"`
+	// Backend the backend of the RPC metrics, BackendPrometheus (default) or BackendOpenTelemetry
+	Backend string `yaml:"backend"`
+	// MeterProvider the meter provider of BackendOpenTelemetry, default the global one
+	MeterProvider otelmetric.MeterProvider `yaml:"-"`
 }
 
 // DefaultConfig 默认配置
@@ -155,4 +173,6 @@ type PrometheusPushConfig struct {
 	Password     string            `yaml:"password"`
 	Grouping     map[string]string `yaml:"grouping"`
 	HTTPHeaders  map[string]string `yaml:"http_headers"`
+	// DeleteOnShutdown deletes the metrics from the push gateway after the final push on Shutdown
+	DeleteOnShutdown bool `yaml:"delete_on_shutdown"`
 }
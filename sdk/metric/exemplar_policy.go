@@ -0,0 +1,355 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exemplar policies of the RPC handled metrics
+const (
+	// ExemplarPolicyDefault the span is attached if sampled, or if failed or slow when deferred sampling is enabled
+	ExemplarPolicyDefault = ""
+	// ExemplarPolicySampled the span is attached if its trace is retained
+	ExemplarPolicySampled = "sampled"
+	// ExemplarPolicyReservoir at most ReservoirSize retained spans are attached per bucket per interval
+	ExemplarPolicyReservoir = "reservoir"
+	// ExemplarPolicyPercentile the retained spans not faster than the percentile of the last interval are
+	// attached, at most ReservoirSize per bucket per interval
+	ExemplarPolicyPercentile = "percentile"
+	// ExemplarPolicyErrorCodes the retained spans whose code type is one of CodeTypes are attached
+	ExemplarPolicyErrorCodes = "error_codes"
+	// ExemplarPolicyNone no exemplar is attached
+	ExemplarPolicyNone = "none"
+)
+
+// extra exemplar labels besides traceID
+const (
+	// ExemplarLabelSpanID the span ID of the RPC
+	ExemplarLabelSpanID = "span_id"
+	// ExemplarLabelDyeingKey the dyeing key of the RPC, truncated to fit the exemplar length limit
+	ExemplarLabelDyeingKey = "dyeing_key"
+)
+
+// names of the RPC handled metrics which accept exemplar policies
+const (
+	exemplarServerCounter   = "rpc_server_handled_total"
+	exemplarServerHistogram = "rpc_server_handled_seconds"
+	exemplarClientCounter   = "rpc_client_handled_total"
+	exemplarClientHistogram = "rpc_client_handled_seconds"
+)
+
+const (
+	defaultExemplarReservoirSize = 1
+	defaultExemplarInterval      = time.Minute
+)
+
+// ExemplarPolicyConfig the policy deciding which RPCs are attached as exemplars,
+// all policies except ExemplarPolicyDefault attach only the spans whose trace is retained
+type ExemplarPolicyConfig struct {
+	// Policy one of the ExemplarPolicy constants, default ExemplarPolicyDefault
+	Policy string `yaml:"policy"`
+	// ReservoirSize the max number of exemplars per bucket per interval, default 1
+	ReservoirSize int `yaml:"reservoir_size"`
+	// Interval the window of the reservoir and the percentile, default 1m
+	Interval time.Duration `yaml:"interval"`
+	// Percentile the percentile of ExemplarPolicyPercentile in (0, 100), e.g. 99
+	Percentile float64 `yaml:"percentile"`
+	// CodeTypes the code types of ExemplarPolicyErrorCodes, default exception and timeout
+	CodeTypes []string `yaml:"code_types"`
+	// Labels the extra exemplar labels, ExemplarLabelSpanID and ExemplarLabelDyeingKey, added in order while the
+	// exemplar fits in 64 runes, which leaves room for either the span ID or a dyeing key of 15 runes
+	Labels []string `yaml:"labels"`
+}
+
+// ExemplarConfig the exemplar policies of the RPC handled metrics
+type ExemplarConfig struct {
+	// Default the policy of the metrics absent from Metrics
+	Default ExemplarPolicyConfig `yaml:"default"`
+	// Metrics the policies by metric name, rpc_server_handled_total, rpc_server_handled_seconds,
+	// rpc_client_handled_total or rpc_client_handled_seconds
+	Metrics map[string]ExemplarPolicyConfig `yaml:"metrics"`
+}
+
+// exemplarCandidate the handled RPC to be decided by the policy
+type exemplarCandidate struct {
+	span trace.SpanContext
+	// retained whether the trace is kept, sampled or failed or slow when deferred sampling is enabled
+	retained    bool
+	labelValues []string
+	codeType    string
+	costSecs    float64
+}
+
+// exemplarPolicy decides the exemplars of a metric
+type exemplarPolicy struct {
+	cfg ExemplarPolicyConfig
+	// buckets the latency buckets of the reservoir and the percentile, nil to use a single bucket per series
+	buckets   []float64
+	codeTypes map[string]struct{}
+	now       func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	// taken the exemplars of the current window by series and bucket
+	taken map[string]int
+	// counts the observations of the current window by series and latency bucket
+	counts map[string][]uint64
+	// thresholds the bucket index of the percentile of the last window by series
+	thresholds map[string]int
+}
+
+func newExemplarPolicy(cfg ExemplarPolicyConfig, buckets, latencyBuckets []float64) (*exemplarPolicy, error) {
+	switch cfg.Policy {
+	case ExemplarPolicyDefault, ExemplarPolicySampled, ExemplarPolicyNone:
+	case ExemplarPolicyReservoir, ExemplarPolicyErrorCodes:
+	case ExemplarPolicyPercentile:
+		if cfg.Percentile <= 0 || cfg.Percentile >= 100 {
+			return nil, fmt.Errorf("metric: exemplar percentile %v out of range (0, 100)", cfg.Percentile)
+		}
+	default:
+		return nil, fmt.Errorf("metric: unknown exemplar policy %q", cfg.Policy)
+	}
+	for _, label := range cfg.Labels {
+		if label != ExemplarLabelSpanID && label != ExemplarLabelDyeingKey {
+			return nil, fmt.Errorf("metric: unknown exemplar label %q", label)
+		}
+	}
+	if cfg.ReservoirSize <= 0 {
+		cfg.ReservoirSize = defaultExemplarReservoirSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultExemplarInterval
+	}
+	if len(cfg.CodeTypes) == 0 {
+		cfg.CodeTypes = []string{CodeTypeException.String(), CodeTypeTimeout.String()}
+	}
+	p := &exemplarPolicy{
+		cfg:        cfg,
+		buckets:    buckets,
+		codeTypes:  make(map[string]struct{}, len(cfg.CodeTypes)),
+		now:        time.Now,
+		taken:      make(map[string]int),
+		counts:     make(map[string][]uint64),
+		thresholds: make(map[string]int),
+	}
+	if cfg.Policy == ExemplarPolicyPercentile {
+		// the percentile is always measured by the latency buckets, the counter included
+		p.buckets = latencyBuckets
+	}
+	for _, t := range cfg.CodeTypes {
+		p.codeTypes[t] = struct{}{}
+	}
+	return p, nil
+}
+
+// attach returns whether the candidate is attached as the exemplar, legacy is returned for ExemplarPolicyDefault
+func (p *exemplarPolicy) attach(c exemplarCandidate, legacy bool) bool {
+	if p == nil || p.cfg.Policy == ExemplarPolicyDefault {
+		return legacy
+	}
+	if p.cfg.Policy == ExemplarPolicyNone || !c.retained || !c.span.HasTraceID() {
+		if p.cfg.Policy == ExemplarPolicyPercentile {
+			p.observe(c)
+		}
+		return false
+	}
+	switch p.cfg.Policy {
+	case ExemplarPolicySampled:
+		return true
+	case ExemplarPolicyErrorCodes:
+		_, ok := p.codeTypes[c.codeType]
+		return ok
+	case ExemplarPolicyReservoir:
+		return p.take(c)
+	case ExemplarPolicyPercentile:
+		return p.observe(c) && p.take(c)
+	}
+	return false
+}
+
+// labels returns the names of the extra exemplar labels
+func (p *exemplarPolicy) labels() []string {
+	if p == nil {
+		return nil
+	}
+	return p.cfg.Labels
+}
+
+// rotate starts a new window if the interval passes, the caller must hold the lock
+func (p *exemplarPolicy) rotate() {
+	now := p.now()
+	if !p.windowStart.IsZero() && now.Sub(p.windowStart) < p.cfg.Interval {
+		return
+	}
+	if !p.windowStart.IsZero() && p.cfg.Policy == ExemplarPolicyPercentile {
+		thresholds := make(map[string]int, len(p.counts))
+		for series, counts := range p.counts {
+			thresholds[series] = percentileBucket(counts, p.cfg.Percentile)
+		}
+		p.thresholds = thresholds
+	}
+	p.windowStart = now
+	p.taken = make(map[string]int)
+	p.counts = make(map[string][]uint64)
+}
+
+// take returns whether the reservoir of the bucket of the candidate has room in the current window
+func (p *exemplarPolicy) take(c exemplarCandidate) bool {
+	key := fmt.Sprintf("%s\xff%d", strings.Join(c.labelValues, "\xff"), p.bucket(c.costSecs))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rotate()
+	if p.taken[key] >= p.cfg.ReservoirSize {
+		return false
+	}
+	p.taken[key]++
+	return true
+}
+
+// observe counts the candidate in the current window, and returns whether it is not faster than the percentile
+// of the last window, which is false in the first window
+func (p *exemplarPolicy) observe(c exemplarCandidate) bool {
+	series := strings.Join(c.labelValues, "\xff")
+	bucket := p.bucket(c.costSecs)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rotate()
+	counts, ok := p.counts[series]
+	if !ok {
+		counts = make([]uint64, len(p.buckets)+1)
+		p.counts[series] = counts
+	}
+	counts[bucket]++
+	threshold, ok := p.thresholds[series]
+	return ok && bucket >= threshold
+}
+
+// bucket returns the index of the bucket whose upper bound is the first not less than costSecs
+func (p *exemplarPolicy) bucket(costSecs float64) int {
+	return sort.SearchFloat64s(p.buckets, costSecs)
+}
+
+// percentileBucket returns the index of the bucket containing the percentile of the counts
+func percentileBucket(counts []uint64, percentile float64) int {
+	var total uint64
+	for _, v := range counts {
+		total += v
+	}
+	rank := float64(total) * percentile / 100
+	var cumulative uint64
+	for i, v := range counts {
+		cumulative += v
+		if float64(cumulative) >= rank {
+			return i
+		}
+	}
+	return len(counts) - 1
+}
+
+// rpcExemplarPolicies the exemplar policies of the RPC handled metrics
+type rpcExemplarPolicies struct {
+	serverCounter   *exemplarPolicy
+	serverHistogram *exemplarPolicy
+	clientCounter   *exemplarPolicy
+	clientHistogram *exemplarPolicy
+}
+
+var defaultRPCExemplarPolicies atomic.Value
+
+// setupRPCExemplarPolicies creates the policies of the RPC handled metrics
+func setupRPCExemplarPolicies(cfg ExemplarConfig) error {
+	for name := range cfg.Metrics {
+		switch name {
+		case exemplarServerCounter, exemplarServerHistogram, exemplarClientCounter, exemplarClientHistogram:
+		default:
+			return fmt.Errorf("metric: exemplar policy of unknown metric %q", name)
+		}
+	}
+	policyConfig := func(name string) ExemplarPolicyConfig {
+		if c, ok := cfg.Metrics[name]; ok {
+			return c
+		}
+		return cfg.Default
+	}
+	policies := &rpcExemplarPolicies{}
+	var err error
+	if policies.serverCounter, err = newExemplarPolicy(
+		policyConfig(exemplarServerCounter), nil, serverHandledHistogramBuckets); err != nil {
+		return err
+	}
+	if policies.serverHistogram, err = newExemplarPolicy(policyConfig(exemplarServerHistogram),
+		serverHandledHistogramBuckets, serverHandledHistogramBuckets); err != nil {
+		return err
+	}
+	if policies.clientCounter, err = newExemplarPolicy(
+		policyConfig(exemplarClientCounter), nil, clientHandledHistogramBuckets); err != nil {
+		return err
+	}
+	if policies.clientHistogram, err = newExemplarPolicy(policyConfig(exemplarClientHistogram),
+		clientHandledHistogramBuckets, clientHandledHistogramBuckets); err != nil {
+		return err
+	}
+	defaultRPCExemplarPolicies.Store(policies)
+	return nil
+}
+
+// getRPCExemplarPolicies returns the policies, the policies are nil before setup
+func getRPCExemplarPolicies() *rpcExemplarPolicies {
+	if p, ok := defaultRPCExemplarPolicies.Load().(*rpcExemplarPolicies); ok {
+		return p
+	}
+	return &rpcExemplarPolicies{}
+}
+
+// exemplarLabels returns the exemplar of the span of ctx with the extra labels in order while they fit in
+// prometheus.ExemplarMaxRunes, the span ID is skipped if it does not fit and the dyeing key is truncated
+func exemplarLabels(ctx context.Context, extra []string, dyeingKey string) prometheus.Labels {
+	sp := trace.SpanContextFromContext(ctx)
+	labels := prometheus.Labels{"traceID": sp.TraceID().String()}
+	budget := prometheus.ExemplarMaxRunes - len("traceID") - len(labels["traceID"])
+	for _, name := range extra {
+		switch name {
+		case ExemplarLabelSpanID:
+			if v := sp.SpanID().String(); len(name)+len(v) <= budget {
+				labels[name] = v
+				budget -= len(name) + len(v)
+			}
+		case ExemplarLabelDyeingKey:
+			if dyeingKey != "" && len(name) < budget {
+				v := truncateRunes(dyeingKey, budget-len(name))
+				labels[name] = v
+				budget -= len(name) + utf8.RuneCountInString(v)
+			}
+		}
+	}
+	return labels
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
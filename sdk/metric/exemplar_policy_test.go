@@ -0,0 +1,200 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testSpanContext(sampled bool) trace.SpanContext {
+	cfg := trace.SpanContextConfig{
+		TraceID: trace.TraceID{1, 2, 3},
+		SpanID:  trace.SpanID{4, 5, 6},
+	}
+	if sampled {
+		cfg.TraceFlags = trace.FlagsSampled
+	}
+	return trace.NewSpanContext(cfg)
+}
+
+func TestExemplarPolicy_Attach(t *testing.T) {
+	retained := exemplarCandidate{span: testSpanContext(true), retained: true, labelValues: []string{"m1"},
+		codeType: CodeTypeSuccess.String(), costSecs: 0.2}
+	dropped := retained
+	dropped.retained = false
+
+	var nilPolicy *exemplarPolicy
+	assert.True(t, nilPolicy.attach(dropped, true))
+
+	p, err := newExemplarPolicy(ExemplarPolicyConfig{}, nil, nil)
+	require.Nil(t, err)
+	assert.False(t, p.attach(retained, false))
+
+	p, err = newExemplarPolicy(ExemplarPolicyConfig{Policy: ExemplarPolicySampled}, nil, nil)
+	require.Nil(t, err)
+	assert.True(t, p.attach(retained, false))
+	assert.False(t, p.attach(dropped, true))
+
+	p, err = newExemplarPolicy(ExemplarPolicyConfig{Policy: ExemplarPolicyNone}, nil, nil)
+	require.Nil(t, err)
+	assert.False(t, p.attach(retained, true))
+
+	p, err = newExemplarPolicy(ExemplarPolicyConfig{Policy: ExemplarPolicyErrorCodes}, nil, nil)
+	require.Nil(t, err)
+	assert.False(t, p.attach(retained, true))
+	failed := retained
+	failed.codeType = CodeTypeTimeout.String()
+	assert.True(t, p.attach(failed, false))
+}
+
+func TestExemplarPolicy_Reservoir(t *testing.T) {
+	now := time.Unix(0, 0)
+	p, err := newExemplarPolicy(ExemplarPolicyConfig{Policy: ExemplarPolicyReservoir, ReservoirSize: 2},
+		[]float64{0.1, 1}, nil)
+	require.Nil(t, err)
+	p.now = func() time.Time { return now }
+
+	c := exemplarCandidate{span: testSpanContext(true), retained: true, labelValues: []string{"m1"}, costSecs: 0.05}
+	assert.True(t, p.attach(c, false))
+	assert.True(t, p.attach(c, false))
+	assert.False(t, p.attach(c, false))
+	// the other bucket and series have their own reservoir
+	slow := c
+	slow.costSecs = 0.5
+	assert.True(t, p.attach(slow, false))
+	other := c
+	other.labelValues = []string{"m2"}
+	assert.True(t, p.attach(other, false))
+
+	now = now.Add(time.Minute)
+	assert.True(t, p.attach(c, false))
+}
+
+func TestExemplarPolicy_Percentile(t *testing.T) {
+	now := time.Unix(0, 0)
+	p, err := newExemplarPolicy(ExemplarPolicyConfig{Policy: ExemplarPolicyPercentile, Percentile: 95,
+		ReservoirSize: 10}, nil, []float64{0.1, 1})
+	require.Nil(t, err)
+	p.now = func() time.Time { return now }
+
+	fast := exemplarCandidate{span: testSpanContext(true), retained: true, labelValues: []string{"m1"}, costSecs: 0.05}
+	slow := fast
+	slow.costSecs = 0.5
+	// no percentile in the first window
+	for i := 0; i < 9; i++ {
+		assert.False(t, p.attach(fast, false))
+	}
+	assert.False(t, p.attach(slow, false))
+
+	now = now.Add(time.Minute)
+	assert.False(t, p.attach(fast, false))
+	assert.True(t, p.attach(slow, false))
+
+	_, err = newExemplarPolicy(ExemplarPolicyConfig{Policy: ExemplarPolicyPercentile}, nil, nil)
+	assert.NotNil(t, err)
+}
+
+func TestExemplarLabels(t *testing.T) {
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext(true))
+	labels := exemplarLabels(ctx, nil, "dyeing")
+	assert.Equal(t, prometheus.Labels{"traceID": testSpanContext(true).TraceID().String()}, labels)
+
+	labels = exemplarLabels(ctx, []string{ExemplarLabelSpanID, ExemplarLabelDyeingKey}, "dyeing")
+	assert.Equal(t, testSpanContext(true).SpanID().String(), labels[ExemplarLabelSpanID])
+	assert.NotContains(t, labels, ExemplarLabelDyeingKey)
+
+	labels = exemplarLabels(ctx, []string{ExemplarLabelDyeingKey, ExemplarLabelSpanID}, strings.Repeat("染", 100))
+	assert.NotContains(t, labels, ExemplarLabelSpanID)
+	runes := 0
+	for k, v := range labels {
+		runes += utf8.RuneCountInString(k) + utf8.RuneCountInString(v)
+	}
+	assert.Equal(t, prometheus.ExemplarMaxRunes, runes)
+}
+
+func TestSetupRPCExemplarPolicies(t *testing.T) {
+	assert.NotNil(t, setupRPCExemplarPolicies(ExemplarConfig{
+		Metrics: map[string]ExemplarPolicyConfig{"rpc_unknown": {}},
+	}))
+	assert.NotNil(t, setupRPCExemplarPolicies(ExemplarConfig{Default: ExemplarPolicyConfig{Policy: "unknown"}}))
+	assert.NotNil(t, setupRPCExemplarPolicies(ExemplarConfig{Default: ExemplarPolicyConfig{Labels: []string{"x"}}}))
+}
+
+func TestExemplarPolicy_Reporter(t *testing.T) {
+	resetDefaultRegistry(t)
+	require.Nil(t, setupRPCRecorder(Config{Exemplar: ExemplarConfig{
+		Default: ExemplarPolicyConfig{Policy: ExemplarPolicyNone},
+		Metrics: map[string]ExemplarPolicyConfig{
+			"rpc_server_handled_total": {
+				Policy: ExemplarPolicySampled,
+				Labels: []string{ExemplarLabelDyeingKey, ExemplarLabelSpanID},
+			},
+		},
+	}}))
+	defer defaultRPCExemplarPolicies.Store(&rpcExemplarPolicies{})
+
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext(true))
+	NewServerReporter("trpc", "caller", "/caller", "callee", "exemplar",
+		WithServerDyeingKey("user1")).Handled(ctx, "0")
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.Nil(t, err)
+	var counter, histogram *dto.Metric
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			if !hasLabelValue(m, "exemplar") {
+				continue
+			}
+			switch f.GetName() {
+			case "rpc_server_handled_total":
+				counter = m
+			case "rpc_server_handled_seconds":
+				histogram = m
+			}
+		}
+	}
+	require.NotNil(t, counter)
+	require.NotNil(t, counter.GetCounter().GetExemplar())
+	labels := map[string]string{}
+	for _, l := range counter.GetCounter().GetExemplar().GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	assert.Equal(t, map[string]string{
+		"traceID":              testSpanContext(true).TraceID().String(),
+		ExemplarLabelDyeingKey: "user1",
+	}, labels)
+	require.NotNil(t, histogram)
+	for _, b := range histogram.GetHistogram().GetBucket() {
+		assert.Nil(t, b.GetExemplar())
+	}
+}
+
+func hasLabelValue(m *dto.Metric, value string) bool {
+	for _, l := range m.GetLabel() {
+		if l.GetValue() == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Handle the registration and the Prometheus push started by SetupByConfigWithHandle
+type Handle struct {
+	registry   Registry
+	deregister context.CancelFunc
+
+	pusher           *push.Pusher
+	deleteOnShutdown bool
+	cancelPush       context.CancelFunc
+	pushDone         chan struct{}
+	stopPushOnce     sync.Once
+
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+var (
+	defaultHandleMu sync.Mutex
+	defaultHandle   *Handle
+	// registerShutdownHookOnce registers Shutdown as the shutdown hook of opentelemetry.Shutdown once
+	registerShutdownHookOnce sync.Once
+)
+
+func setDefaultHandle(h *Handle) {
+	defaultHandleMu.Lock()
+	defer defaultHandleMu.Unlock()
+	defaultHandle = h
+}
+
+func getDefaultHandle() *Handle {
+	defaultHandleMu.Lock()
+	defer defaultHandleMu.Unlock()
+	return defaultHandle
+}
+
+// Shutdown shuts down the handle started by SetupByConfig, SetupByConfig registers it as the shutdown hook
+// of opentelemetry.Shutdown
+func Shutdown(ctx context.Context) error {
+	if h := getDefaultHandle(); h != nil {
+		return h.Shutdown(ctx)
+	}
+	return nil
+}
+
+// startPush pushes every interval until stopped
+func startPush(pusher *push.Pusher, cfg PrometheusPushConfig) *Handle {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Handle{
+		pusher:           pusher,
+		deleteOnShutdown: cfg.DeleteOnShutdown,
+		cancelPush:       cancel,
+		pushDone:         make(chan struct{}),
+	}
+	if cfg.Interval <= 0 {
+		close(h.pushDone)
+		return h
+	}
+	go func() {
+		defer close(h.pushDone)
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = pusher.Push()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return h
+}
+
+// stopPush stops the push goroutine and waits for it
+func (h *Handle) stopPush() {
+	h.stopPushOnce.Do(func() {
+		h.cancelPush()
+		<-h.pushDone
+	})
+}
+
+// Shutdown deregisters the instance from the registry, pushes the metrics for the last time and deletes them
+// if DeleteOnShutdown, and stops all the background goroutines. Only the first call takes effect, and it is safe to
+// call on a nil handle.
+func (h *Handle) Shutdown(ctx context.Context) error {
+	if h == nil {
+		return nil
+	}
+	h.shutdownOnce.Do(func() {
+		var errs []error
+		if h.deregister != nil {
+			if err := runWithContext(ctx, func() error {
+				h.deregister()
+				return nil
+			}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if h.registry != nil {
+			if err := h.registry.Shutdown(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if h.pusher != nil {
+			h.stopPush()
+			if err := runWithContext(ctx, h.pusher.Push); err != nil {
+				errs = append(errs, err)
+			}
+			if h.deleteOnShutdown {
+				if err := runWithContext(ctx, h.pusher.Delete); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		h.shutdownErr = joinErrors(errs)
+	})
+	return h.shutdownErr
+}
+
+// runWithContext runs f and returns early if ctx is done
+func runWithContext(ctx context.Context, f func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// joinErrors joins the errors into one, errors.Join requires go 1.20
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	msg := errs[0].Error()
+	for _, err := range errs[1:] {
+		msg += "; " + err.Error()
+	}
+	return errors.New(msg)
+}
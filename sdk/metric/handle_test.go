@@ -0,0 +1,155 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	opentelemetry "trpc-system/go-opentelemetry"
+)
+
+func resetDefaultRegistry(t *testing.T) {
+	registerer, gatherer := prometheus.DefaultRegisterer, prometheus.DefaultGatherer
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = reg
+	prometheus.DefaultGatherer = reg
+	t.Cleanup(func() {
+		prometheus.DefaultRegisterer, prometheus.DefaultGatherer = registerer, gatherer
+	})
+}
+
+func TestHandle_ShutdownDeregisters(t *testing.T) {
+	resetDefaultRegistry(t)
+	path := filepath.Join(t.TempDir(), "targets.json")
+	h, err := SetupByConfigWithHandle(Config{
+		Enabled:         true,
+		EnabledRegister: true,
+		RegistryType:    RegistryTypeFileSD,
+		FileSDPath:      path,
+		Instance:        *testRegistryInstance(),
+	})
+	require.Nil(t, err)
+	require.NotNil(t, h)
+	_, err = os.Stat(path)
+	require.Nil(t, err)
+
+	require.Nil(t, h.Shutdown(context.Background()))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, h.Shutdown(context.Background()))
+}
+
+func TestHandle_ShutdownPush(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	for _, deleteOnShutdown := range []bool{false, true} {
+		resetDefaultRegistry(t)
+		mu.Lock()
+		methods = nil
+		mu.Unlock()
+		h, err := SetupByConfigWithHandle(Config{
+			Enabled: true,
+			PrometheusPush: PrometheusPushConfig{
+				Enabled:          true,
+				URL:              s.URL,
+				Job:              "test",
+				Interval:         time.Hour,
+				DeleteOnShutdown: deleteOnShutdown,
+			},
+		})
+		require.Nil(t, err)
+		require.Nil(t, h.Shutdown(context.Background()))
+
+		want := []string{http.MethodPut, http.MethodPut}
+		if deleteOnShutdown {
+			want = append(want, http.MethodDelete)
+		}
+		mu.Lock()
+		assert.Equal(t, want, methods)
+		mu.Unlock()
+	}
+}
+
+func TestSetupByConfig_ShutdownHook(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+	defer setDefaultHandle(nil)
+
+	resetDefaultRegistry(t)
+	require.Nil(t, SetupByConfig(Config{
+		Enabled: true,
+		PrometheusPush: PrometheusPushConfig{
+			Enabled:          true,
+			URL:              s.URL,
+			Job:              "test",
+			Interval:         time.Hour,
+			DeleteOnShutdown: true,
+		},
+	}))
+	require.Nil(t, opentelemetry.Shutdown(context.Background()))
+	mu.Lock()
+	assert.Equal(t, []string{http.MethodPut, http.MethodPut, http.MethodDelete}, methods)
+	mu.Unlock()
+}
+
+func TestHandle_ShutdownContextDone(t *testing.T) {
+	block := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer s.Close()
+	defer close(block)
+
+	resetDefaultRegistry(t)
+	h := startPush(push.New(s.URL, "test"), PrometheusPushConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, h.Shutdown(ctx))
+}
+
+func TestHandle_Nil(t *testing.T) {
+	var h *Handle
+	assert.Nil(t, h.Shutdown(context.Background()))
+}
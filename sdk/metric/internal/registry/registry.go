@@ -26,6 +26,8 @@ type Instance interface {
 
 // Registry register or unregister instance to registry
 type Registry interface {
-	// Register register a instance to the registry.
+	// Register register a instance to the registry, it is kept alive until the returned func is called.
 	Register(ctx context.Context, ins Instance, ttl time.Duration) (context.CancelFunc, error)
+	// Shutdown deregisters all the registered instances and releases the resources.
+	Shutdown(ctx context.Context) error
 }
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -39,6 +40,9 @@ type etcdRegistry struct {
 	tenantID    string
 	registerTTL time.Duration
 	err         error
+
+	mu      sync.Mutex
+	cancels map[*int]context.CancelFunc
 }
 
 // EtcdOption etcd config option
@@ -56,6 +60,7 @@ func NewEtcdRegistry(etcdEndpoints []string, tenantID string, opts ...EtcdOption
 	r := &etcdRegistry{
 		registerTTL: DefaultRegisterTTL,
 		tenantID:    tenantID,
+		cancels:     make(map[*int]context.CancelFunc),
 	}
 	cfg := clientv3.Config{
 		Endpoints: etcdEndpoints,
@@ -84,10 +89,20 @@ func (e *etcdRegistry) Register(ctx context.Context, ins Instance, ttl time.Dura
 		return nil, err
 	}
 	ch := make(chan struct{}, 1)
+	id := new(int)
+	var once sync.Once
 	cancelFunc := func() {
-		cancel()
-		<-ch
+		once.Do(func() {
+			cancel()
+			<-ch
+			e.mu.Lock()
+			delete(e.cancels, id)
+			e.mu.Unlock()
+		})
 	}
+	e.mu.Lock()
+	e.cancels[id] = cancelFunc
+	e.mu.Unlock()
 	go func() {
 		leaseID := leaseID
 		for {
@@ -110,6 +125,32 @@ func (e *etcdRegistry) Register(ctx context.Context, ins Instance, ttl time.Dura
 	return cancelFunc, nil
 }
 
+// Shutdown deregisters all the registered instances and closes the etcd client
+func (e *etcdRegistry) Shutdown(ctx context.Context) error {
+	if err := e.err; err != nil {
+		return err
+	}
+	e.mu.Lock()
+	cancels := make([]context.CancelFunc, 0, len(e.cancels))
+	for _, cancel := range e.cancels {
+		cancels = append(cancels, cancel)
+	}
+	e.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return e.cli.Close()
+}
+
 func (e *etcdRegistry) register(ctx context.Context, ins Instance, ttl time.Duration) (clientv3.LeaseID, error) {
 	if err := e.err; err != nil {
 		return 0, err
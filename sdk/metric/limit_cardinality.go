@@ -18,13 +18,13 @@ import (
 	"log"
 	"net/http"
 	"sort"
-	"strings"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -39,6 +39,14 @@ var (
 			Help: "high cardinality metrics",
 		},
 		[]string{"name"})
+	// cardinalityFoldedTotal the records folded by the cardinality limiter, label is the label whose values are
+	// folded, empty for the whole series
+	cardinalityFoldedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "high_cardinality_folded_total",
+			Help: "the records folded into __overflow__ by the cardinality limiter",
+		},
+		[]string{"name", "label"})
 )
 
 var (
@@ -95,57 +103,59 @@ type LimitCardinalityGatherer struct {
 	TotalMetricLimit int
 }
 
-// Gather implements prometheus.Gatherer
+// Gather implements prometheus.Gatherer, the series beyond the limits are folded into the overflow series
+// as the cardinality limiter does, so the totals remain correct. Once the total limit is reached,
+// each of the remaining metrics keeps its overflow series only.
 func (l *LimitCardinalityGatherer) Gather() ([]*dto.MetricFamily, error) {
 	res, err := l.Gatherer.Gather()
 	if err != nil {
 		return nil, err
 	}
 	var (
-		total                      int
-		resetClientM, resetServerM sync.Once
+		total         int
+		totalExceeded bool
 	)
-	for i, v := range res {
+	for _, v := range res {
 		if l.PerMetirclimit > 0 && len(v.GetMetric()) > l.PerMetirclimit {
 			log.Printf("opentelemetry: high cardinality metric '%s', value:%d, limit:%d",
 				v.GetName(), len(v.GetMetric()), l.PerMetirclimit)
 			highCardinalityMetrics.WithLabelValues(v.GetName()).Set(float64(len(v.GetMetric())))
-			// get topN
-			v.Metric = v.Metric[:l.PerMetirclimit]
-			if strings.HasPrefix(v.GetName(), "rpc_client") {
-				resetClientM.Do(func() {
-					log.Printf("opentelemetry: reset rpc_client metric when high cardinality(>%d)",
-						l.PerMetirclimit)
-					clientStartedCounter.Reset()
-					clientHandledCounter.Reset()
-					clientHandledHistogram.Reset()
-				})
-			}
-			if strings.HasPrefix(v.GetName(), "rpc_server") {
-				resetServerM.Do(func() {
-					log.Printf("opentelemetry: reset rpc_server metric when high cardinality(>%d)",
-						l.PerMetirclimit)
-					serverStartedCounter.Reset()
-					serverHandledCounter.Reset()
-					serverHandledHistogram.Reset()
-				})
-			}
+			// keep topN
+			v.Metric = foldMetricFamily(v.Metric, l.PerMetirclimit)
 		} else if m, _ := highCardinalityMetrics.GetMetricWithLabelValues(v.GetName()); m != nil {
 			m.Set(0)
 		}
-		total += len(v.GetMetric())
-		if l.TotalMetricLimit > 0 && total > l.TotalMetricLimit {
-			log.Printf("opentelemetry: high cardinality metric '%s', value:%d %d, limit:%d",
-				"all", total, len(res), l.TotalMetricLimit)
-			highCardinalityMetrics.WithLabelValues("total").Set(float64(len(res)))
-			return res[:i], nil
-		} else if m, _ := highCardinalityMetrics.GetMetricWithLabelValues("total"); m != nil {
-			m.Set(0)
+		if l.TotalMetricLimit > 0 && total+len(v.GetMetric()) > l.TotalMetricLimit {
+			if !totalExceeded {
+				log.Printf("opentelemetry: high cardinality metric '%s', value:%d %d, limit:%d",
+					"all", total+len(v.GetMetric()), len(res), l.TotalMetricLimit)
+				totalExceeded = true
+			}
+			v.Metric = foldMetricFamily(v.Metric, l.TotalMetricLimit-total)
 		}
+		total += len(v.GetMetric())
+	}
+	if totalExceeded {
+		highCardinalityMetrics.WithLabelValues("total").Set(float64(len(res)))
+	} else if m, _ := highCardinalityMetrics.GetMetricWithLabelValues("total"); m != nil {
+		m.Set(0)
 	}
 	return processor(res), nil
 }
 
+// foldMetricFamily keeps the limit-1 series of the largest values and folds the others into the overflow series
+func foldMetricFamily(metrics []*dto.Metric, limit int) []*dto.Metric {
+	if len(metrics) <= limit {
+		return metrics
+	}
+	overflow := &dto.Metric{Label: overflowLabels(metrics, nil)}
+	kept, folded := foldMetrics(metrics, limit, overflow.Label)
+	for _, m := range folded {
+		mergeMetric(overflow, m)
+	}
+	return append(kept, overflow)
+}
+
 type metricCollector interface {
 	prometheus.Collector
 	Delete(labels prometheus.Labels) bool
@@ -156,47 +166,61 @@ type LimitCardinalityCollector struct {
 	metricCollector
 	desc  string
 	limit int
+
+	mu sync.Mutex
+	// folded the values of the series deleted by the collector, which are added to the overflow series
+	folded       *dto.Metric
+	overflowDesc *prometheus.Desc
 }
 
-// Collect called when pull metrics
+// Collect called when pull metrics, the series beyond the limit are deleted and folded into the overflow series,
+// so the totals remain correct
 func (c *LimitCardinalityCollector) Collect(ch chan<- prometheus.Metric) {
 	results := c.collect()
-	if len(results) <= c.limit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(results) <= c.limit && c.folded == nil {
 		for _, v := range results {
 			ch <- v
 		}
 		return
 	}
-	metrics := map[*dto.Metric]prometheus.Metric{}
-	var m []*dto.Metric
+	metrics := make(map[*dto.Metric]prometheus.Metric, len(results))
+	m := make([]*dto.Metric, 0, len(results))
 	for _, v := range results {
 		mm := &dto.Metric{}
 		_ = v.Write(mm)
 		m = append(m, mm)
 		metrics[mm] = v
+		c.overflowDesc = v.Desc()
 	}
 	if len(m) > c.limit {
 		highCardinalityMetrics.WithLabelValues(c.desc).Set(float64(len(m)))
 		log.Printf("opentelemetry: metric '%s' high cardinality, limit:%d", c.desc, c.limit)
-		sort.Slice(m, func(i, j int) bool {
-			return getMetricValue(m[i]) >
-				getMetricValue(m[j])
-		})
-		for i := 0; i < len(m); i++ {
-			shouldDelete := getMetricValue(m[i]) <= 1 || i >= c.limit
-			if shouldDelete {
-				labels := prometheus.Labels{}
-				for _, vv := range m[i].GetLabel() {
-					labels[vv.GetName()] = vv.GetValue()
-				}
-				c.Delete(labels)
-				delete(metrics, m[i])
-			}
+	}
+	var labels []*dto.LabelPair
+	if c.folded != nil {
+		labels = c.folded.Label
+	}
+	labels = overflowLabels(m, labels)
+	if c.folded == nil {
+		c.folded = &dto.Metric{}
+	}
+	c.folded.Label = labels
+	kept, folded := foldMetrics(m, c.limit, labels)
+	overflow := &dto.Metric{Label: labels}
+	mergeMetric(overflow, c.folded)
+	for _, mm := range folded {
+		mergeMetric(overflow, mm)
+		// the overflow series recorded by the collector itself is kept, e.g. by the cardinality limiter
+		if !equalLabels(mm.GetLabel(), labels) && c.Delete(metricLabels(mm)) {
+			mergeMetric(c.folded, mm)
 		}
 	}
-	for _, v := range metrics {
-		ch <- v
+	for _, mm := range kept {
+		ch <- metrics[mm]
 	}
+	ch <- dtoMetric{desc: c.overflowDesc, metric: overflow}
 }
 
 func (c *LimitCardinalityCollector) collect() []prometheus.Metric {
@@ -217,6 +241,140 @@ func (c *LimitCardinalityCollector) collect() []prometheus.Metric {
 	return results
 }
 
+// overflowLabels returns the labels of the overflow series: the values shared by all the series and prev,
+// e.g. the const labels, are kept, and the others are OverflowLabelValue
+func overflowLabels(metrics []*dto.Metric, prev []*dto.LabelPair) []*dto.LabelPair {
+	var labels []*dto.LabelPair
+	if len(prev) > 0 {
+		labels = prev
+	} else if len(metrics) > 0 {
+		labels = metrics[0].GetLabel()
+	}
+	overflow := make([]*dto.LabelPair, 0, len(labels))
+	for i, l := range labels {
+		value := l.GetValue()
+		for _, m := range metrics {
+			if ls := m.GetLabel(); i >= len(ls) || ls[i].GetValue() != value {
+				value = OverflowLabelValue
+				break
+			}
+		}
+		overflow = append(overflow, &dto.LabelPair{Name: proto.String(l.GetName()), Value: proto.String(value)})
+	}
+	return overflow
+}
+
+// foldMetrics returns the limit-1 series of the largest values to keep and the others to fold, the series of
+// the overflow labels is always folded
+func foldMetrics(metrics []*dto.Metric, limit int, overflow []*dto.LabelPair) (kept, folded []*dto.Metric) {
+	kept = make([]*dto.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		if equalLabels(m.GetLabel(), overflow) {
+			folded = append(folded, m)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) < limit {
+		return kept, folded
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return getMetricValue(kept[i]) > getMetricValue(kept[j])
+	})
+	n := limit - 1
+	if n < 0 {
+		n = 0
+	}
+	return kept[:n], append(folded, kept[n:]...)
+}
+
+// mergeMetric adds the values of src to dst, the quantiles of the summaries and the exemplars are dropped
+func mergeMetric(dst, src *dto.Metric) {
+	switch {
+	case src.Counter != nil:
+		if dst.Counter == nil {
+			dst.Counter = &dto.Counter{Value: proto.Float64(0)}
+		}
+		dst.Counter.Value = proto.Float64(dst.Counter.GetValue() + src.Counter.GetValue())
+	case src.Gauge != nil:
+		if dst.Gauge == nil {
+			dst.Gauge = &dto.Gauge{Value: proto.Float64(0)}
+		}
+		dst.Gauge.Value = proto.Float64(dst.Gauge.GetValue() + src.Gauge.GetValue())
+	case src.Untyped != nil:
+		if dst.Untyped == nil {
+			dst.Untyped = &dto.Untyped{Value: proto.Float64(0)}
+		}
+		dst.Untyped.Value = proto.Float64(dst.Untyped.GetValue() + src.Untyped.GetValue())
+	case src.Summary != nil:
+		if dst.Summary == nil {
+			dst.Summary = &dto.Summary{SampleCount: proto.Uint64(0), SampleSum: proto.Float64(0)}
+		}
+		dst.Summary.SampleCount = proto.Uint64(dst.Summary.GetSampleCount() + src.Summary.GetSampleCount())
+		dst.Summary.SampleSum = proto.Float64(dst.Summary.GetSampleSum() + src.Summary.GetSampleSum())
+	case src.Histogram != nil:
+		if dst.Histogram == nil {
+			dst.Histogram = &dto.Histogram{SampleCount: proto.Uint64(0), SampleSum: proto.Float64(0)}
+			for _, b := range src.Histogram.GetBucket() {
+				dst.Histogram.Bucket = append(dst.Histogram.Bucket, &dto.Bucket{
+					UpperBound:      proto.Float64(b.GetUpperBound()),
+					CumulativeCount: proto.Uint64(0),
+				})
+			}
+		}
+		dst.Histogram.SampleCount = proto.Uint64(dst.Histogram.GetSampleCount() + src.Histogram.GetSampleCount())
+		dst.Histogram.SampleSum = proto.Float64(dst.Histogram.GetSampleSum() + src.Histogram.GetSampleSum())
+		for i, b := range src.Histogram.GetBucket() {
+			if i < len(dst.Histogram.Bucket) && dst.Histogram.Bucket[i].GetUpperBound() == b.GetUpperBound() {
+				dst.Histogram.Bucket[i].CumulativeCount = proto.Uint64(
+					dst.Histogram.Bucket[i].GetCumulativeCount() + b.GetCumulativeCount())
+			}
+		}
+	}
+}
+
+func equalLabels(a, b []*dto.LabelPair) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].GetName() != b[i].GetName() || a[i].GetValue() != b[i].GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+func metricLabels(m *dto.Metric) prometheus.Labels {
+	labels := make(prometheus.Labels, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	return labels
+}
+
+// dtoMetric the metric of the written dto.Metric
+type dtoMetric struct {
+	desc   *prometheus.Desc
+	metric *dto.Metric
+}
+
+// Desc implements prometheus.Metric
+func (m dtoMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+// Write implements prometheus.Metric
+func (m dtoMetric) Write(out *dto.Metric) error {
+	out.Label = m.metric.Label
+	out.Counter = m.metric.Counter
+	out.Gauge = m.metric.Gauge
+	out.Untyped = m.metric.Untyped
+	out.Summary = m.metric.Summary
+	out.Histogram = m.metric.Histogram
+	return nil
+}
+
 func getMetricValue(m *dto.Metric) float64 {
 	return m.GetCounter().GetValue() +
 		m.GetGauge().GetValue() +
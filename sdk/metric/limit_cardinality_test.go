@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,7 +47,37 @@ func TestLimitCardinalityCollector_Collect(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(metrics))
 		assert.Equal(t, 2, len(metrics[0].GetMetric()))
+		assert.Equal(t, float64(6), counterTotal(metrics[0]))
+		assert.Equal(t, float64(4), counterValue(metrics[0], OverflowLabelValue))
 	})
+	t.Run("folded series kept", func(t *testing.T) {
+		clientStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m4").Inc()
+		metrics, err := reg.Gather()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(metrics))
+		assert.Equal(t, 2, len(metrics[0].GetMetric()))
+		assert.Equal(t, float64(7), counterTotal(metrics[0]))
+	})
+}
+
+func counterTotal(mf *dto.MetricFamily) float64 {
+	var total float64
+	for _, m := range mf.GetMetric() {
+		total += m.GetCounter().GetValue()
+	}
+	return total
+}
+
+// counterValue returns the value of the series of the callee method
+func counterValue(mf *dto.MetricFamily, method string) float64 {
+	for _, m := range mf.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "callee_method" && l.GetValue() == method {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
 }
 
 func TestLimitCardinalityGatherer_Gather(t *testing.T) {
@@ -76,7 +107,7 @@ func TestLimitCardinalityGatherer_Gather(t *testing.T) {
 	t.Run("over limit for single metric", func(t *testing.T) {
 		clientStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m1")
 		clientStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m2")
-		clientStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m3")
+		clientStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m3").Add(3)
 		metrics, err := gatherer.Gather()
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(metrics))
@@ -85,18 +116,23 @@ func TestLimitCardinalityGatherer_Gather(t *testing.T) {
 			total += len(v.GetMetric())
 		}
 		assert.Equal(t, gatherer.PerMetirclimit, total)
+		assert.Equal(t, float64(3), counterValue(metrics[0], "m3"))
+		assert.Equal(t, float64(0), counterValue(metrics[0], OverflowLabelValue))
 	})
 	t.Run("over limit for all metrics", func(t *testing.T) {
 		clientStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m2")
 		clientHandledCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m1", "code", "code_type", "code_desc")
 		serverStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m2")
+		serverStartedCounter.WithLabelValues("trpc", "s1", "m1", "s1", "m3").Add(2)
 		metrics, err := gatherer.Gather()
 		assert.NoError(t, err)
+		// the metrics beyond the total limit keep their overflow series only
 		assert.Equal(t, 3, len(metrics))
-		var total int
 		for _, v := range metrics {
-			total += len(v.GetMetric())
+			if v.GetName() == "rpc_server_started_total" {
+				assert.Equal(t, 1, len(v.GetMetric()))
+				assert.Equal(t, float64(2), counterValue(v, OverflowLabelValue))
+			}
 		}
-		assert.Equal(t, gatherer.TotalMetricLimit, total)
 	})
 }
@@ -0,0 +1,184 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxLearnedTemplates the max number of the templates learned by the automatic mode,
+// the paths of new templates beyond it are reported as default_pattern_method
+const maxLearnedTemplates = 1000
+
+// RegisterRouteTemplate registers the route template as the method pattern, e.g. /user/{id}/orders matches
+// /user/1/orders, {name} matches a path segment, * matches a segment, or the rest of the path if it is the last one
+func RegisterRouteTemplate(template string) error {
+	regex, err := compileRouteTemplate(template)
+	if err != nil {
+		return err
+	}
+	methodMappings = append(methodMappings, &MethodMapping{
+		Regex:   regex,
+		Pattern: template,
+	})
+	return nil
+}
+
+// compileRouteTemplate compiles the route template into the regex of the full match
+func compileRouteTemplate(template string) (*regexp.Regexp, error) {
+	if !strings.HasPrefix(template, "/") {
+		return nil, fmt.Errorf("metric: route template %q should start with /", template)
+	}
+	segments := strings.Split(template[1:], "/")
+	var b strings.Builder
+	b.WriteString("^")
+	for i, seg := range segments {
+		b.WriteString("/")
+		switch {
+		case seg == "*" && i == len(segments)-1:
+			b.WriteString(".*")
+		case seg == "*":
+			b.WriteString("[^/]+")
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			if len(seg) == 2 {
+				return nil, fmt.Errorf("metric: route template %q has empty parameter name", template)
+			}
+			b.WriteString("[^/]+")
+		case strings.ContainsAny(seg, "{}*"):
+			return nil, fmt.Errorf("metric: route template %q has invalid segment %q", template, seg)
+		default:
+			b.WriteString(regexp.QuoteMeta(seg))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+var autoMethodTemplate atomic.Value
+
+// SetAutoMethodTemplate enables learning the templates of the paths not matched by the registered mappings,
+// the numeric, UUID and hex segments are replaced by {id}, {uuid} and {hex}
+func SetAutoMethodTemplate(enabled bool) {
+	autoMethodTemplate.Store(enabled)
+}
+
+var learnedTemplates = struct {
+	sync.RWMutex
+	m map[string]struct{}
+}{m: make(map[string]struct{})}
+
+// MethodPattern returns the pattern of the http path by the registered mappings, or the template learned by the
+// automatic mode, it is used by both the metrics and the span names so that they are grouped identically
+func MethodPattern(path string) (string, bool) {
+	if idx := strings.IndexByte(path, '?'); idx > 0 {
+		path = path[:idx]
+	}
+	if v, ok := methodToPattern(path); ok {
+		return v, true
+	}
+	if enabled, _ := autoMethodTemplate.Load().(bool); !enabled {
+		return path, false
+	}
+	return learnTemplate(path)
+}
+
+// learnTemplate replaces the id-like segments of path, the number of the learned templates is limited
+func learnTemplate(path string) (string, bool) {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		switch {
+		case isNumericSegment(seg):
+			segments[i] = "{id}"
+		case isUUIDSegment(seg):
+			segments[i] = "{uuid}"
+		case isHexSegment(seg):
+			segments[i] = "{hex}"
+		}
+	}
+	template := strings.Join(segments, "/")
+	learnedTemplates.RLock()
+	_, ok := learnedTemplates.m[template]
+	learnedTemplates.RUnlock()
+	if ok {
+		return template, true
+	}
+	learnedTemplates.Lock()
+	defer learnedTemplates.Unlock()
+	if _, ok := learnedTemplates.m[template]; ok {
+		return template, true
+	}
+	if len(learnedTemplates.m) >= maxLearnedTemplates {
+		return path, false
+	}
+	learnedTemplates.m[template] = struct{}{}
+	return template, true
+}
+
+func isNumericSegment(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUIDSegment matches 8-4-4-4-12 hex digits
+func isUUIDSegment(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isHexSegment matches the hex digits no shorter than 8 with at least a decimal digit, e.g. the object id and hash,
+// so the words like "deadbeef" are kept
+func isHexSegment(s string) bool {
+	if len(s) < 8 {
+		return false
+	}
+	var digit bool
+	for i := 0; i < len(s); i++ {
+		if !isHexDigit(s[i]) {
+			return false
+		}
+		if s[i] >= '0' && s[i] <= '9' {
+			digit = true
+		}
+	}
+	return digit
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,73 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRouteTemplate(t *testing.T) {
+	mappings := methodMappings
+	defer func() { methodMappings = mappings }()
+	methodMappings = nil
+
+	require.Nil(t, RegisterRouteTemplate("/user/{id}/orders"))
+	require.Nil(t, RegisterRouteTemplate("/files/*"))
+	require.Nil(t, RegisterRouteTemplate("/repo/*/tags"))
+	assert.NotNil(t, RegisterRouteTemplate("user"))
+	assert.NotNil(t, RegisterRouteTemplate("/user/{}"))
+	assert.NotNil(t, RegisterRouteTemplate("/user/a{id}"))
+
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"/user/1/orders", "/user/{id}/orders"},
+		{"/user/1/orders?page=2", "/user/{id}/orders"},
+		{"/user/1/orders/2", "default_pattern_method"},
+		{"/files/a/b.txt", "/files/*"},
+		{"/repo/r1/tags", "/repo/*/tags"},
+		{"/repo/r1/r2/tags", "default_pattern_method"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, defaultCleanRPCMethod(tt.method), tt.method)
+	}
+}
+
+func TestMethodPattern_Auto(t *testing.T) {
+	SetAutoMethodTemplate(true)
+	defer SetAutoMethodTemplate(false)
+
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"/user/123/orders", "/user/{id}/orders"},
+		{"/order/3f2504e0-4f89-11d3-9a0c-0305e82c3301", "/order/{uuid}"},
+		{"/blob/5f2b6c1e9d3a4b7c8e0f1a2b", "/blob/{hex}"},
+		{"/feed/deadbeef", "/feed/deadbeef"},
+	}
+	for _, tt := range tests {
+		got, ok := MethodPattern(tt.method)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, got, tt.method)
+	}
+
+	SetAutoMethodTemplate(false)
+	_, ok := MethodPattern("/user/123/orders")
+	assert.False(t, ok)
+}
@@ -14,10 +14,17 @@
 package metric
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+
 	"trpc-system/go-opentelemetry/sdk/metric/internal/registry"
 )
 
+// Registry registers the metrics scrape target, implement it to supply another backend
 type Registry = registry.Registry
+
+// RegistryInstance the instance registered
 type RegistryInstance = registry.Instance
 
 var (
@@ -30,3 +37,62 @@ var (
 var (
 	NewEtcdRegistry = registry.NewEtcdRegistry
 )
+
+// types of the built-in registries
+const (
+	// RegistryTypeEtcd the etcd registry, the default one
+	RegistryTypeEtcd = "etcd"
+	// RegistryTypeConsul the agent service of consul with the TTL check
+	RegistryTypeConsul = "consul"
+	// RegistryTypeFileSD the JSON file of the Prometheus file_sd_configs
+	RegistryTypeFileSD = "file_sd"
+	// RegistryTypeHTTPSD the endpoint of the Prometheus http_sd_configs served by HTTPSDHandler
+	RegistryTypeHTTPSD = "http_sd"
+)
+
+// RegistryFactory creates the registry by the metric config
+type RegistryFactory func(cfg Config) (Registry, error)
+
+var (
+	registryFactoriesMu sync.RWMutex
+	registryFactories   = map[string]RegistryFactory{
+		RegistryTypeEtcd:   newEtcdRegistry,
+		RegistryTypeConsul: newConsulRegistry,
+		RegistryTypeFileSD: newFileSDRegistry,
+		RegistryTypeHTTPSD: func(Config) (Registry, error) { return defaultHTTPSDRegistry, nil },
+	}
+)
+
+// RegisterRegistryFactory registers the factory of the registry type set by Config.RegistryType,
+// the registered one with the same type is replaced
+func RegisterRegistryFactory(registryType string, f RegistryFactory) {
+	registryFactoriesMu.Lock()
+	defer registryFactoriesMu.Unlock()
+	registryFactories[registryType] = f
+}
+
+// newRegistry returns Config.Registry if set, or creates the registry of Config.RegistryType
+func newRegistry(cfg Config) (Registry, error) {
+	if cfg.Registry != nil {
+		return cfg.Registry, nil
+	}
+	registryType := cfg.RegistryType
+	if registryType == "" {
+		registryType = RegistryTypeEtcd
+	}
+	registryFactoriesMu.RLock()
+	f, ok := registryFactories[registryType]
+	registryFactoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("metric: unknown registry type %q", registryType)
+	}
+	return f(cfg)
+}
+
+func newEtcdRegistry(cfg Config) (Registry, error) {
+	if len(cfg.RegistryEndpoints) == 0 {
+		return nil, errors.New("metric: registry endpoints nil")
+	}
+	return NewEtcdRegistry(cfg.RegistryEndpoints, cfg.Instance.TenantID,
+		registry.WithTLS(newTLSConfig(cfg.TLSCert))), nil
+}
@@ -0,0 +1,158 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// consulServiceName the service name of the registered instances
+	consulServiceName = "opentelemetry-metrics"
+	// minConsulDeregisterAfter the minimum of DeregisterCriticalServiceAfter accepted by consul
+	minConsulDeregisterAfter = time.Minute
+)
+
+// ConsulRegistry registers the instances as the agent services of consul with the TTL checks,
+// the checks are passed every ttl/3 and the service is deregistered by consul after it is critical for 3 ttl.
+type ConsulRegistry struct {
+	*targetRegistry
+	addr   string
+	client *http.Client
+}
+
+var _ Registry = (*ConsulRegistry)(nil)
+
+// NewConsulRegistry creates the consul registry of the agent addr, e.g. http://127.0.0.1:8500
+func NewConsulRegistry(addr string, tlsConfig *tls.Config) *ConsulRegistry {
+	if !strings.Contains(addr, "://") {
+		scheme := "http://"
+		if tlsConfig != nil {
+			scheme = "https://"
+		}
+		addr = scheme + addr
+	}
+	r := &ConsulRegistry{
+		addr: strings.TrimRight(addr, "/"),
+		client: &http.Client{
+			Timeout:   DefaultDialTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+	r.targetRegistry = newTargetRegistry(r)
+	return r
+}
+
+func newConsulRegistry(cfg Config) (Registry, error) {
+	if len(cfg.RegistryEndpoints) == 0 {
+		return nil, errors.New("metric: registry endpoints nil")
+	}
+	return NewConsulRegistry(cfg.RegistryEndpoints[0], newTLSConfig(cfg.TLSCert)), nil
+}
+
+// consulService the body of /v1/agent/service/register
+type consulService struct {
+	ID      string
+	Name    string
+	Address string
+	Port    int
+	Tags    []string
+	Meta    map[string]string
+	Check   consulCheck
+}
+
+type consulCheck struct {
+	CheckID                        string
+	TTL                            string
+	DeregisterCriticalServiceAfter string
+}
+
+func (r *ConsulRegistry) register(ctx context.Context, g targetGroup, ttl time.Duration) error {
+	host, port, err := net.SplitHostPort(g.Targets[0])
+	if err != nil {
+		return err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return err
+	}
+	deregisterAfter := 3 * ttl
+	if deregisterAfter < minConsulDeregisterAfter {
+		deregisterAfter = minConsulDeregisterAfter
+	}
+	id := consulServiceID(g)
+	return r.put(ctx, "/v1/agent/service/register", consulService{
+		ID:      id,
+		Name:    consulServiceName,
+		Address: host,
+		Port:    p,
+		Tags:    []string{"tenant_id=" + g.Labels["tenant_id"]},
+		Meta:    g.Labels,
+		Check: consulCheck{
+			CheckID:                        "service:" + id,
+			TTL:                            ttl.String(),
+			DeregisterCriticalServiceAfter: deregisterAfter.String(),
+		},
+	})
+}
+
+func (r *ConsulRegistry) keepAlive(ctx context.Context, g targetGroup, _ time.Duration) error {
+	return r.put(ctx, "/v1/agent/check/pass/"+url.PathEscape("service:"+consulServiceID(g)), nil)
+}
+
+func (r *ConsulRegistry) deregister(ctx context.Context, g targetGroup) error {
+	return r.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(consulServiceID(g)), nil)
+}
+
+func (r *ConsulRegistry) put(ctx context.Context, path string, body interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.addr+path, reader)
+	if err != nil {
+		return err
+	}
+	rsp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return fmt.Errorf("consul %s: %s %s", path, rsp.Status, msg)
+	}
+	return nil
+}
+
+// consulServiceID the service ID is the key without the slashes
+func consulServiceID(g targetGroup) string {
+	return strings.Trim(strings.ReplaceAll(g.key, "/", "-"), "-")
+}
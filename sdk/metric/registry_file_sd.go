@@ -0,0 +1,97 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileSDRegistry writes the registered instances to the JSON file of the Prometheus file_sd_configs.
+// The file is owned by the process and rewritten every ttl/3, so the files not modified within the ttl
+// are stale and can be cleaned up, it is removed after all the instances are deregistered.
+type FileSDRegistry struct {
+	*targetRegistry
+	path string
+
+	mu     sync.Mutex
+	groups map[string]targetGroup
+}
+
+var _ Registry = (*FileSDRegistry)(nil)
+
+// NewFileSDRegistry creates the file_sd registry writing to path
+func NewFileSDRegistry(path string) *FileSDRegistry {
+	r := &FileSDRegistry{path: path, groups: make(map[string]targetGroup)}
+	r.targetRegistry = newTargetRegistry(r)
+	return r
+}
+
+func newFileSDRegistry(cfg Config) (Registry, error) {
+	if cfg.FileSDPath == "" {
+		return nil, errors.New("metric: file_sd path nil")
+	}
+	return NewFileSDRegistry(cfg.FileSDPath), nil
+}
+
+func (r *FileSDRegistry) register(_ context.Context, g targetGroup, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups[g.key] = g
+	return r.write()
+}
+
+func (r *FileSDRegistry) keepAlive(ctx context.Context, g targetGroup, ttl time.Duration) error {
+	return r.register(ctx, g, ttl)
+}
+
+func (r *FileSDRegistry) deregister(_ context.Context, g targetGroup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.groups, g.key)
+	if len(r.groups) == 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return r.write()
+}
+
+// write replaces the file atomically, so Prometheus never reads a partial file
+func (r *FileSDRegistry) write() error {
+	groups := make([]targetGroup, 0, len(r.groups))
+	for _, g := range r.groups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].key < groups[j].key })
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
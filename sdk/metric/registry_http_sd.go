@@ -0,0 +1,88 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var defaultHTTPSDRegistry = NewHTTPSDRegistry()
+
+// HTTPSDHandler serves the instances of the http_sd registry, e.g. by the admin server
+func HTTPSDHandler() http.Handler {
+	return defaultHTTPSDRegistry
+}
+
+// HTTPSDRegistry serves the registered instances as the Prometheus http_sd endpoint,
+// an instance expires if not kept alive within the ttl
+type HTTPSDRegistry struct {
+	*targetRegistry
+
+	mu      sync.RWMutex
+	groups  map[string]targetGroup
+	expires map[string]time.Time
+}
+
+var _ Registry = (*HTTPSDRegistry)(nil)
+
+// NewHTTPSDRegistry creates the http_sd registry
+func NewHTTPSDRegistry() *HTTPSDRegistry {
+	r := &HTTPSDRegistry{
+		groups:  make(map[string]targetGroup),
+		expires: make(map[string]time.Time),
+	}
+	r.targetRegistry = newTargetRegistry(r)
+	return r
+}
+
+// ServeHTTP responds the unexpired target groups in JSON
+func (r *HTTPSDRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now()
+	r.mu.RLock()
+	groups := make([]targetGroup, 0, len(r.groups))
+	for key, g := range r.groups {
+		if now.Before(r.expires[key]) {
+			groups = append(groups, g)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(groups, func(i, j int) bool { return groups[i].key < groups[j].key })
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(groups)
+}
+
+func (r *HTTPSDRegistry) register(_ context.Context, g targetGroup, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups[g.key] = g
+	r.expires[g.key] = time.Now().Add(ttl)
+	return nil
+}
+
+func (r *HTTPSDRegistry) keepAlive(ctx context.Context, g targetGroup, ttl time.Duration) error {
+	return r.register(ctx, g, ttl)
+}
+
+func (r *HTTPSDRegistry) deregister(_ context.Context, g targetGroup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.groups, g.key)
+	delete(r.expires, g.key)
+	return nil
+}
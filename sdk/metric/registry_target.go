@@ -0,0 +1,161 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// targetGroup the target group of the Prometheus file_sd and http_sd,
+// see https://prometheus.io/docs/prometheus/latest/http_sd/
+type targetGroup struct {
+	key     string
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// newTargetGroup builds the group by the value of the instance, which is the JSON of Instance
+func newTargetGroup(ins RegistryInstance) (targetGroup, error) {
+	var i Instance
+	if err := json.Unmarshal([]byte(ins.GetValue()), &i); err != nil {
+		return targetGroup{}, err
+	}
+	labels := make(map[string]string, len(i.Metadata)+1)
+	for k, v := range i.Metadata {
+		labels[sanitizeLabelName(k)] = v
+	}
+	if i.TenantID != "" {
+		labels["tenant_id"] = i.TenantID
+	}
+	return targetGroup{key: ins.GetKey(), Targets: []string{i.Addr}, Labels: labels}, nil
+}
+
+// sanitizeLabelName replaces the characters invalid in the Prometheus label names with _
+func sanitizeLabelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// targetBackend the backend of targetRegistry
+type targetBackend interface {
+	// register registers or refreshes the group
+	register(ctx context.Context, g targetGroup, ttl time.Duration) error
+	// keepAlive keeps the registered group alive, every ttl/3
+	keepAlive(ctx context.Context, g targetGroup, ttl time.Duration) error
+	deregister(ctx context.Context, g targetGroup) error
+}
+
+// targetRegistry registers the instances as target groups to the backend, and keeps them alive
+// until canceled or shut down
+type targetRegistry struct {
+	backend targetBackend
+
+	mu      sync.Mutex
+	cancels map[*int]context.CancelFunc
+}
+
+func newTargetRegistry(backend targetBackend) *targetRegistry {
+	return &targetRegistry{backend: backend, cancels: make(map[*int]context.CancelFunc)}
+}
+
+// Register registers the instance and keeps it alive until the returned func is called
+func (r *targetRegistry) Register(ctx context.Context, ins RegistryInstance,
+	ttl time.Duration) (context.CancelFunc, error) {
+	g, err := newTargetGroup(ins)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.backend.register(ctx, g, ttl); err != nil {
+		return nil, err
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(keepAliveInterval(ttl))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.backend.keepAlive(cctx, g, ttl); err != nil {
+					log.Printf("opentelemetry: keep %s alive error: %v, register again", g.key, err)
+					_ = r.backend.register(cctx, g, ttl)
+				}
+			case <-cctx.Done():
+				if err := r.backend.deregister(context.Background(), g); err != nil {
+					log.Printf("opentelemetry: deregister %s error: %v", g.key, err)
+				}
+				return
+			}
+		}
+	}()
+	id := new(int)
+	var once sync.Once
+	cancelFunc := func() {
+		once.Do(func() {
+			cancel()
+			<-done
+			r.mu.Lock()
+			delete(r.cancels, id)
+			r.mu.Unlock()
+		})
+	}
+	r.mu.Lock()
+	r.cancels[id] = cancelFunc
+	r.mu.Unlock()
+	return cancelFunc, nil
+}
+
+// Shutdown deregisters all the registered instances
+func (r *targetRegistry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	cancels := make([]context.CancelFunc, 0, len(r.cancels))
+	for _, cancel := range r.cancels {
+		cancels = append(cancels, cancel)
+	}
+	r.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// keepAliveInterval keeps alive 3 times per ttl
+func keepAliveInterval(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		ttl = DefaultRegisterTTL
+	}
+	if interval := ttl / 3; interval > 0 {
+		return interval
+	}
+	return ttl
+}
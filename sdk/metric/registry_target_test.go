@@ -0,0 +1,145 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRegistryInstance() *Instance {
+	return &Instance{
+		Addr:     "127.0.0.1:9090",
+		TenantID: "default",
+		Metadata: map[string]string{"app": "test", "container.name": "c1"},
+	}
+}
+
+func TestHTTPSDRegistry(t *testing.T) {
+	r := NewHTTPSDRegistry()
+	cancel, err := r.Register(context.Background(), testRegistryInstance(), time.Minute)
+	require.Nil(t, err)
+
+	var groups []targetGroup
+	serve := func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics/http_sd", nil))
+		groups = nil
+		require.Nil(t, json.Unmarshal(w.Body.Bytes(), &groups))
+	}
+	serve()
+	require.Len(t, groups, 1)
+	assert.Equal(t, []string{"127.0.0.1:9090"}, groups[0].Targets)
+	assert.Equal(t, map[string]string{"app": "test", "container_name": "c1", "tenant_id": "default"},
+		groups[0].Labels)
+
+	cancel()
+	serve()
+	assert.Empty(t, groups)
+}
+
+func TestHTTPSDRegistry_Expire(t *testing.T) {
+	r := NewHTTPSDRegistry()
+	g, err := newTargetGroup(testRegistryInstance())
+	require.Nil(t, err)
+	require.Nil(t, r.register(context.Background(), g, -time.Second))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.JSONEq(t, "[]", w.Body.String())
+}
+
+func TestFileSDRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sd", "targets.json")
+	r := NewFileSDRegistry(path)
+	_, err := r.Register(context.Background(), testRegistryInstance(), time.Minute)
+	require.Nil(t, err)
+
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	var groups []targetGroup
+	require.Nil(t, json.Unmarshal(data, &groups))
+	require.Len(t, groups, 1)
+	assert.Equal(t, []string{"127.0.0.1:9090"}, groups[0].Targets)
+
+	require.Nil(t, r.Shutdown(context.Background()))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConsulRegistry(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+		svc   consulService
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, http.MethodPut, req.Method)
+		paths = append(paths, req.URL.Path)
+		if req.URL.Path == "/v1/agent/service/register" {
+			assert.Nil(t, json.NewDecoder(req.Body).Decode(&svc))
+		}
+	}))
+	defer s.Close()
+
+	r := NewConsulRegistry(s.URL, nil)
+	_, err := r.Register(context.Background(), testRegistryInstance(), 30*time.Millisecond)
+	require.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(paths) > 1
+	}, time.Second, 5*time.Millisecond)
+	require.Nil(t, r.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	id := "opentelemetry-metrics-services-default-127.0.0.1:9090"
+	assert.Equal(t, id, svc.ID)
+	assert.Equal(t, "127.0.0.1", svc.Address)
+	assert.Equal(t, 9090, svc.Port)
+	assert.Equal(t, "1m0s", svc.Check.DeregisterCriticalServiceAfter)
+	assert.Equal(t, "/v1/agent/check/pass/service:"+id, paths[1])
+	assert.Equal(t, "/v1/agent/service/deregister/"+id, paths[len(paths)-1])
+}
+
+func TestNewRegistry(t *testing.T) {
+	_, err := newRegistry(Config{RegistryType: "unknown"})
+	assert.NotNil(t, err)
+	_, err = newRegistry(Config{})
+	assert.NotNil(t, err) // etcd endpoints nil
+	_, err = newRegistry(Config{RegistryType: RegistryTypeFileSD})
+	assert.NotNil(t, err)
+
+	reg := NewHTTPSDRegistry()
+	got, err := newRegistry(Config{Registry: reg, RegistryType: "unknown"})
+	assert.Nil(t, err)
+	assert.Equal(t, Registry(reg), got)
+
+	RegisterRegistryFactory("custom", func(Config) (Registry, error) { return reg, nil })
+	got, err = newRegistry(Config{RegistryType: "custom"})
+	assert.Nil(t, err)
+	assert.Equal(t, Registry(reg), got)
+}
@@ -86,18 +86,8 @@ func SetClientLabelsOption(f func(name string) []string) {
 
 func registerRPCClientCounter() {
 	initClientCollectors()
-	prometheus.MustRegister(
-		&LimitCardinalityCollector{
-			clientStartedCounter,
-			"clientStartedCounter",
-			rpcMetricsCardinalityLimit,
-		})
-	prometheus.MustRegister(
-		&LimitCardinalityCollector{
-			clientHandledCounter,
-			"clientHandledCounter",
-			rpcMetricsCardinalityLimit,
-		})
+	// limited by the cardinality limiter when recorded, deleting the series loses the counts
+	prometheus.MustRegister(clientStartedCounter, clientHandledCounter)
 }
 
 func initClientCollectors() {
@@ -135,15 +125,15 @@ var DefaultClientMetrics = NewClientMetrics()
 func init() {
 	prometheus.MustRegister(
 		&LimitCardinalityCollector{
-			DefaultClientMetrics.clientStreamMsgReceived,
-			"clientStreamMsgReceived",
-			rpcMetricsCardinalityLimit,
+			metricCollector: DefaultClientMetrics.clientStreamMsgReceived,
+			desc:            "clientStreamMsgReceived",
+			limit:           rpcMetricsCardinalityLimit,
 		})
 	prometheus.MustRegister(
 		&LimitCardinalityCollector{
-			DefaultClientMetrics.clientStreamMsgSent,
-			"clientStreamMsgSent",
-			rpcMetricsCardinalityLimit,
+			metricCollector: DefaultClientMetrics.clientStreamMsgSent,
+			desc:            "clientStreamMsgSent",
+			limit:           rpcMetricsCardinalityLimit,
 		})
 }
 
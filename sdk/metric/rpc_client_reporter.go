@@ -41,6 +41,7 @@ type ClientReporter struct {
 	enableDeferredSample       bool
 	deferredSampleError        bool
 	deferredSampleSlowDuration time.Duration
+	dyeingKey                  string
 }
 
 // ClientOption Client 调用参数工具函数。
@@ -63,6 +64,13 @@ func WithClientTraceConfig(enableDeferredSample, deferredSampleError bool,
 	}
 }
 
+// WithClientDyeingKey set the dyeing key, attached as the exemplar label dyeing_key if configured
+func WithClientDyeingKey(dyeingKey string) ClientOption {
+	return func(clientReporter *ClientReporter) {
+		clientReporter.dyeingKey = dyeingKey
+	}
+}
+
 // WithClientStartTime 设置startTime
 func WithClientStartTime(startTime time.Time) ClientOption {
 	return func(clientReporter *ClientReporter) {
@@ -110,7 +118,8 @@ func NewClientReporter(systemName, callerService, callerMethod, calleeService, c
 	}
 	labelValues := []string{r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod}
 	labelValues = append(labelValues, r.extraLabels...)
-	clientStartedCounter.WithLabelValues(labelValues...).Inc()
+	labelValues = getRPCLimiters().clientStarted.limit(labelValues)
+	getRPCRecorder().clientStarted(context.Background(), labelValues)
 	return r
 }
 
@@ -120,41 +129,49 @@ func NewClientReporter(systemName, callerService, callerMethod, calleeService, c
 // Add labels as extended fields. Note that using extended fields requires redefining the initialization function where sdk/metric/rpc_client_metrics.go:40 is located.
 func (r *ClientReporter) Handled(ctx context.Context, code string) {
 	codeType := codes.CodeMapping(code, r.calleeService, r.calleeMethod)
-	counterLabelValues := []string{
-		r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
-		code, codeType.Type, codeType.Description,
-	}
-	counterLabelValues = append(counterLabelValues, r.extraLabels...)
-	c := clientHandledCounter.WithLabelValues(counterLabelValues...)
-	histogramLabelValues := []string{
+	labelValues := []string{
 		r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
 		code, codeType.Type, codeType.Description,
 	}
-	histogramLabelValues = append(histogramLabelValues, r.extraLabels...)
-	h := clientHandledHistogram.WithLabelValues(histogramLabelValues...)
+	labelValues = append(labelValues, r.extraLabels...)
+	labelValues = getRPCLimiters().clientHandled.limit(labelValues)
 
 	if r.endTime.IsZero() {
 		r.endTime = time.Now()
 	}
 	sp := trace.SpanFromContext(ctx).SpanContext()
-	exemplar := prometheus.Labels{
-		"traceID": sp.TraceID().String(),
-	}
 	costSecs := r.endTime.Sub(r.startTime).Seconds()
-	if r.counterNeedUseExemplar(sp, codeType.Type) {
-		if v, ok := c.(prometheus.ExemplarAdder); ok {
-			v.AddWithExemplar(1, exemplar)
-		}
-	} else {
-		c.Inc()
-	}
-	if r.histogramNeedUseExemplar(sp, costSecs) {
-		if v, ok := h.(prometheus.ExemplarObserver); ok {
-			v.ObserveWithExemplar(costSecs, exemplar)
-		}
-	} else {
-		h.Observe(costSecs)
-	}
+	candidate := exemplarCandidate{
+		span:        sp,
+		retained:    r.traceRetained(sp, codeType.Type, costSecs),
+		labelValues: labelValues,
+		codeType:    codeType.Type,
+		costSecs:    costSecs,
+	}
+	policies := getRPCExemplarPolicies()
+	getRPCRecorder().clientHandled(ctx, handledRecord{
+		labelValues:             labelValues,
+		costSecs:                costSecs,
+		counterExemplar:         policies.clientCounter.attach(candidate, r.counterNeedUseExemplar(sp, codeType.Type)),
+		histogramExemplar:       policies.clientHistogram.attach(candidate, r.histogramNeedUseExemplar(sp, costSecs)),
+		counterExemplarLabels:   policies.clientCounter.labels(),
+		histogramExemplarLabels: policies.clientHistogram.labels(),
+		dyeingKey:               r.dyeingKey,
+	})
+}
+
+// traceRetained Check whether the trace is kept, by the sampler or by the deferred sampling
+func (r *ClientReporter) traceRetained(sp trace.SpanContext, codeType string, costSecs float64) bool {
+	if sp.IsSampled() {
+		return true
+	}
+	if !r.enableDeferredSample {
+		return false
+	}
+	if r.deferredSampleError && codeType != CodeTypeSuccess.String() {
+		return true
+	}
+	return r.deferredSampleSlowDuration != 0 && costSecs >= r.deferredSampleSlowDuration.Seconds()
 }
 
 // counterNeedUseExemplar Check whether counter needs to be reported exemplar
@@ -0,0 +1,150 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName the meter name of the OpenTelemetry backend
+const instrumentationName = "trpc-system/go-opentelemetry/sdk/metric"
+
+// instrument names of the OpenTelemetry backend, the same as the Prometheus metrics
+const (
+	otelServerStartedCounter   = "rpc_server_started_total"
+	otelServerHandledCounter   = "rpc_server_handled_total"
+	otelServerHandledHistogram = "rpc_server_handled_seconds"
+	otelClientStartedCounter   = "rpc_client_started_total"
+	otelClientHandledCounter   = "rpc_client_handled_total"
+	otelClientHandledHistogram = "rpc_client_handled_seconds"
+)
+
+// otelRecorder records by the instruments of the OpenTelemetry meter with the same label set as Prometheus.
+// The span of ctx is passed to the instruments if the exemplar is attached, and removed otherwise.
+type otelRecorder struct {
+	serverStartedCounter   otelmetric.Int64Counter
+	serverHandledCounter   otelmetric.Int64Counter
+	serverHandledHistogram otelmetric.Float64Histogram
+	clientStartedCounter   otelmetric.Int64Counter
+	clientHandledCounter   otelmetric.Int64Counter
+	clientHandledHistogram otelmetric.Float64Histogram
+}
+
+func newOTelRecorder(meter otelmetric.Meter) (*otelRecorder, error) {
+	r := &otelRecorder{}
+	var err error
+	if r.serverStartedCounter, err = meter.Int64Counter(otelServerStartedCounter,
+		otelmetric.WithDescription("Total number of RPCs started on the server.")); err != nil {
+		return nil, err
+	}
+	if r.serverHandledCounter, err = meter.Int64Counter(otelServerHandledCounter,
+		otelmetric.WithDescription("Total number of RPCs completed on the server, "+
+			"regardless of success or failure.")); err != nil {
+		return nil, err
+	}
+	if r.serverHandledHistogram, err = meter.Float64Histogram(otelServerHandledHistogram,
+		otelmetric.WithDescription("Histogram of response latency (seconds) of RPC that had been "+
+			"application-level handled by the server."),
+		otelmetric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if r.clientStartedCounter, err = meter.Int64Counter(otelClientStartedCounter,
+		otelmetric.WithDescription("Total number of RPCs started on the client.")); err != nil {
+		return nil, err
+	}
+	if r.clientHandledCounter, err = meter.Int64Counter(otelClientHandledCounter,
+		otelmetric.WithDescription("Total number of RPCs completed by the client, "+
+			"regardless of success or failure.")); err != nil {
+		return nil, err
+	}
+	if r.clientHandledHistogram, err = meter.Float64Histogram(otelClientHandledHistogram,
+		otelmetric.WithDescription("Histogram of response latency (seconds) of the RPC "+
+			"until it is finished by the application."),
+		otelmetric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *otelRecorder) serverStarted(ctx context.Context, labelValues []string) {
+	r.serverStartedCounter.Add(ctx, 1,
+		otelmetric.WithAttributes(toAttributes(serverLabelsOption(ServerStartedCounter), labelValues)...))
+}
+
+func (r *otelRecorder) serverHandled(ctx context.Context, record handledRecord) {
+	r.serverHandledCounter.Add(exemplarContext(ctx, record.counterExemplar), 1,
+		otelmetric.WithAttributes(toAttributes(serverLabelsOption(ServerHandledCounter), record.labelValues)...))
+	r.serverHandledHistogram.Record(exemplarContext(ctx, record.histogramExemplar), record.costSecs,
+		otelmetric.WithAttributes(toAttributes(serverLabelsOption(ServerHandledHistogram), record.labelValues)...))
+}
+
+func (r *otelRecorder) clientStarted(ctx context.Context, labelValues []string) {
+	r.clientStartedCounter.Add(ctx, 1,
+		otelmetric.WithAttributes(toAttributes(clientLabelsOption(ClientStartedCounter), labelValues)...))
+}
+
+func (r *otelRecorder) clientHandled(ctx context.Context, record handledRecord) {
+	r.clientHandledCounter.Add(exemplarContext(ctx, record.counterExemplar), 1,
+		otelmetric.WithAttributes(toAttributes(clientLabelsOption(ClientHandledCounter), record.labelValues)...))
+	r.clientHandledHistogram.Record(exemplarContext(ctx, record.histogramExemplar), record.costSecs,
+		otelmetric.WithAttributes(toAttributes(clientLabelsOption(ClientHandledHistogram), record.labelValues)...))
+}
+
+// exemplarContext removes the span from ctx if the exemplar is not attached
+func exemplarContext(ctx context.Context, attach bool) context.Context {
+	if attach {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, trace.SpanContext{})
+}
+
+// toAttributes pairs the label names with the values, the extra ones of either side are ignored
+func toAttributes(names, values []string) []attribute.KeyValue {
+	n := len(names)
+	if len(values) < n {
+		n = len(values)
+	}
+	attrs := make([]attribute.KeyValue, 0, n)
+	for i := 0; i < n; i++ {
+		attrs = append(attrs, attribute.String(names[i], values[i]))
+	}
+	return attrs
+}
+
+// OpenTelemetryViews returns the views of the RPC latency histograms with the buckets, the invalid or empty
+// buckets fall back to the Prometheus ones. They should be set to the meter provider of BackendOpenTelemetry.
+func OpenTelemetryViews(serverBuckets, clientBuckets []float64) []sdkmetric.View {
+	if b, ok := validateBuckets(serverBuckets); ok {
+		serverBuckets = b
+	} else {
+		serverBuckets = serverHandledHistogramBuckets
+	}
+	if b, ok := validateBuckets(clientBuckets); ok {
+		clientBuckets = b
+	} else {
+		clientBuckets = clientHandledHistogramBuckets
+	}
+	return []sdkmetric.View{
+		sdkmetric.NewView(sdkmetric.Instrument{Name: otelServerHandledHistogram},
+			sdkmetric.Stream{Aggregation: aggregation.ExplicitBucketHistogram{Boundaries: serverBuckets}}),
+		sdkmetric.NewView(sdkmetric.Instrument{Name: otelClientHandledHistogram},
+			sdkmetric.Stream{Aggregation: aggregation.ExplicitBucketHistogram{Boundaries: clientBuckets}}),
+	}
+}
@@ -0,0 +1,74 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestOTelRecorder(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader),
+		sdkmetric.WithView(OpenTelemetryViews([]float64{0.1, 1}, nil)...))
+	require.Nil(t, setupRPCRecorder(Config{Backend: BackendOpenTelemetry, MeterProvider: provider}))
+	defer setRPCRecorder(prometheusRecorder{})
+
+	start := time.Now()
+	r := NewServerReporter("trpc", "caller", "/caller", "callee", "/callee",
+		WithServerStartTime(start), WithServerEndTime(start.Add(500*time.Millisecond)))
+	r.Handled(context.Background(), "0")
+	NewClientReporter("trpc", "caller", "/caller", "callee", "/callee").Handled(context.Background(), "0")
+
+	var rm metricdata.ResourceMetrics
+	require.Nil(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	got := make(map[string]metricdata.Aggregation)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		got[m.Name] = m.Data
+	}
+	for _, name := range []string{otelServerStartedCounter, otelServerHandledCounter, otelServerHandledHistogram,
+		otelClientStartedCounter, otelClientHandledCounter, otelClientHandledHistogram} {
+		assert.Contains(t, got, name)
+	}
+
+	h, ok := got[otelServerHandledHistogram].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, h.DataPoints, 1)
+	dp := h.DataPoints[0]
+	assert.Equal(t, []float64{0.1, 1}, dp.Bounds)
+	assert.Equal(t, []uint64{0, 1, 0}, dp.BucketCounts)
+	v, ok := dp.Attributes.Value(attribute.Key("callee_service"))
+	assert.True(t, ok)
+	assert.Equal(t, "callee", v.AsString())
+	v, ok = dp.Attributes.Value(attribute.Key("code_type"))
+	assert.True(t, ok)
+	assert.Equal(t, CodeTypeSuccess.String(), v.AsString())
+}
+
+func TestSetupRPCRecorder_UnknownBackend(t *testing.T) {
+	assert.NotNil(t, setupRPCRecorder(Config{Backend: "unknown"}))
+}
+
+func Test_toAttributes(t *testing.T) {
+	attrs := toAttributes([]string{"a", "b"}, []string{"1"})
+	assert.Equal(t, []attribute.KeyValue{attribute.String("a", "1")}, attrs)
+}
@@ -0,0 +1,105 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// backends of the RPC metrics reported by ServerReporter and ClientReporter
+const (
+	// BackendPrometheus the Prometheus collectors scraped from /metrics, the default backend
+	BackendPrometheus = "prometheus"
+	// BackendOpenTelemetry the instruments of the OpenTelemetry meter, exported by the meter provider
+	BackendOpenTelemetry = "opentelemetry"
+)
+
+// handledRecord the record of a handled RPC
+type handledRecord struct {
+	labelValues []string
+	costSecs    float64
+	// counterExemplar, histogramExemplar whether the span of ctx is attached as the exemplar
+	counterExemplar   bool
+	histogramExemplar bool
+	// counterExemplarLabels, histogramExemplarLabels the extra exemplar labels
+	counterExemplarLabels   []string
+	histogramExemplarLabels []string
+	dyeingKey               string
+}
+
+// rpcRecorder records the started and handled RPC metrics of the reporters
+type rpcRecorder interface {
+	serverStarted(ctx context.Context, labelValues []string)
+	serverHandled(ctx context.Context, r handledRecord)
+	clientStarted(ctx context.Context, labelValues []string)
+	clientHandled(ctx context.Context, r handledRecord)
+}
+
+var defaultRPCRecorder atomic.Value
+
+func init() {
+	setRPCRecorder(prometheusRecorder{})
+}
+
+func setRPCRecorder(r rpcRecorder) {
+	defaultRPCRecorder.Store(&r)
+}
+
+func getRPCRecorder() rpcRecorder {
+	if r, ok := defaultRPCRecorder.Load().(*rpcRecorder); ok {
+		return *r
+	}
+	return prometheusRecorder{}
+}
+
+// prometheusRecorder records by the Prometheus collectors, the exemplar is the trace ID
+type prometheusRecorder struct{}
+
+func (prometheusRecorder) serverStarted(_ context.Context, labelValues []string) {
+	serverStartedCounter.WithLabelValues(labelValues...).Inc()
+}
+
+func (prometheusRecorder) serverHandled(ctx context.Context, r handledRecord) {
+	observeHandled(ctx, serverHandledCounter.WithLabelValues(r.labelValues...),
+		serverHandledHistogram.WithLabelValues(r.labelValues...), r)
+}
+
+func (prometheusRecorder) clientStarted(_ context.Context, labelValues []string) {
+	clientStartedCounter.WithLabelValues(labelValues...).Inc()
+}
+
+func (prometheusRecorder) clientHandled(ctx context.Context, r handledRecord) {
+	observeHandled(ctx, clientHandledCounter.WithLabelValues(r.labelValues...),
+		clientHandledHistogram.WithLabelValues(r.labelValues...), r)
+}
+
+func observeHandled(ctx context.Context, c prometheus.Counter, h prometheus.Observer, r handledRecord) {
+	if r.counterExemplar {
+		if v, ok := c.(prometheus.ExemplarAdder); ok {
+			v.AddWithExemplar(1, exemplarLabels(ctx, r.counterExemplarLabels, r.dyeingKey))
+		}
+	} else {
+		c.Inc()
+	}
+	if r.histogramExemplar {
+		if v, ok := h.(prometheus.ExemplarObserver); ok {
+			v.ObserveWithExemplar(r.costSecs, exemplarLabels(ctx, r.histogramExemplarLabels, r.dyeingKey))
+		}
+	} else {
+		h.Observe(r.costSecs)
+	}
+}
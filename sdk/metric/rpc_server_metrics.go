@@ -99,10 +99,8 @@ func SetServerLabelsOption(f func(name string) []string) {
 
 func registerRPCServerCounter() {
 	initServerCollectors()
-	prometheus.MustRegister(
-		&LimitCardinalityCollector{serverStartedCounter, "serverStartedCounter", rpcMetricsCardinalityLimit})
-	prometheus.MustRegister(
-		&LimitCardinalityCollector{serverHandledCounter, "serverHandledCounter", rpcMetricsCardinalityLimit})
+	// limited by the cardinality limiter when recorded, deleting the series loses the counts
+	prometheus.MustRegister(serverStartedCounter, serverHandledCounter)
 }
 
 func initServerCollectors() {
@@ -145,10 +143,7 @@ func registerRPCHandledHistograms() {
 		clientLabelsOption(ClientHandledHistogram),
 	)
 
-	prometheus.MustRegister(
-		&LimitCardinalityCollector{serverHandledHistogram, "serverHandledHistogram", rpcMetricsCardinalityLimit})
-	prometheus.MustRegister(
-		&LimitCardinalityCollector{clientHandledHistogram, "clientHandledHistogram", rpcMetricsCardinalityLimit})
+	prometheus.MustRegister(serverHandledHistogram, clientHandledHistogram)
 }
 
 // setServerHandledHistogramBuckets user customizes serverHandledHistogramBuckets through configuration
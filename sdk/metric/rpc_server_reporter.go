@@ -17,7 +17,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 
 	"trpc-system/go-opentelemetry/config/codes"
@@ -41,6 +40,7 @@ type ServerReporter struct {
 	enableDeferredSample       bool
 	deferredSampleError        bool
 	deferredSampleSlowDuration time.Duration
+	dyeingKey                  string
 }
 
 // ServerOption Server option
@@ -63,6 +63,13 @@ func WithServerTraceConfig(enableDeferredSample, deferredSampleError bool,
 	}
 }
 
+// WithServerDyeingKey set the dyeing key, attached as the exemplar label dyeing_key if configured
+func WithServerDyeingKey(dyeingKey string) ServerOption {
+	return func(serverReporter *ServerReporter) {
+		serverReporter.dyeingKey = dyeingKey
+	}
+}
+
 // WithServerStartTime set startTime
 func WithServerStartTime(startTime time.Time) ServerOption {
 	return func(serverReporter *ServerReporter) {
@@ -111,7 +118,8 @@ func NewServerReporter(systemName, callerService, callerMethod, calleeService, c
 	}
 	labelValues := []string{r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod}
 	labelValues = append(labelValues, r.extraLabels...)
-	serverStartedCounter.WithLabelValues(labelValues...).Inc()
+	labelValues = getRPCLimiters().serverStarted.limit(labelValues)
+	getRPCRecorder().serverStarted(context.Background(), labelValues)
 	return r
 }
 
@@ -122,41 +130,49 @@ func NewServerReporter(systemName, callerService, callerMethod, calleeService, c
 // in sdk/metric/rpc_server_metrics.go.
 func (r *ServerReporter) Handled(ctx context.Context, code string) {
 	codeType := codes.CodeMapping(code, r.calleeService, r.calleeMethod)
-	counterLabelValues := []string{
-		r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
-		code, codeType.Type, codeType.Description,
-	}
-	counterLabelValues = append(counterLabelValues, r.extraLabels...)
-	c := serverHandledCounter.WithLabelValues(counterLabelValues...)
-	histogramLabelValues := []string{
+	labelValues := []string{
 		r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
 		code, codeType.Type, codeType.Description,
 	}
-	histogramLabelValues = append(histogramLabelValues, r.extraLabels...)
-	h := serverHandledHistogram.WithLabelValues(histogramLabelValues...)
+	labelValues = append(labelValues, r.extraLabels...)
+	labelValues = getRPCLimiters().serverHandled.limit(labelValues)
 
 	if r.endTime.IsZero() {
 		r.endTime = time.Now()
 	}
 	sp := trace.SpanFromContext(ctx).SpanContext()
-	exemplar := prometheus.Labels{
-		"traceID": sp.TraceID().String(),
-	}
 	costSecs := r.endTime.Sub(r.startTime).Seconds()
-	if r.counterNeedUseExemplar(sp, codeType.Type) {
-		if v, ok := c.(prometheus.ExemplarAdder); ok {
-			v.AddWithExemplar(1, exemplar)
-		}
-	} else {
-		c.Inc()
-	}
-	if r.histogramNeedUseExemplar(sp, costSecs) {
-		if v, ok := h.(prometheus.ExemplarObserver); ok {
-			v.ObserveWithExemplar(costSecs, exemplar)
-		}
-	} else {
-		h.Observe(costSecs)
+	candidate := exemplarCandidate{
+		span:        sp,
+		retained:    r.traceRetained(sp, codeType.Type, costSecs),
+		labelValues: labelValues,
+		codeType:    codeType.Type,
+		costSecs:    costSecs,
+	}
+	policies := getRPCExemplarPolicies()
+	getRPCRecorder().serverHandled(ctx, handledRecord{
+		labelValues:             labelValues,
+		costSecs:                costSecs,
+		counterExemplar:         policies.serverCounter.attach(candidate, r.counterNeedUseExemplar(sp, codeType.Type)),
+		histogramExemplar:       policies.serverHistogram.attach(candidate, r.histogramNeedUseExemplar(sp, costSecs)),
+		counterExemplarLabels:   policies.serverCounter.labels(),
+		histogramExemplarLabels: policies.serverHistogram.labels(),
+		dyeingKey:               r.dyeingKey,
+	})
+}
+
+// traceRetained Check whether the trace is kept, by the sampler or by the deferred sampling
+func (r *ServerReporter) traceRetained(sp trace.SpanContext, codeType string, costSecs float64) bool {
+	if sp.IsSampled() {
+		return true
+	}
+	if !r.enableDeferredSample {
+		return false
+	}
+	if r.deferredSampleError && codeType != CodeTypeSuccess.String() {
+		return true
 	}
+	return r.deferredSampleSlowDuration != 0 && costSecs >= r.deferredSampleSlowDuration.Seconds()
 }
 
 // counterNeedUseExemplar Check whether counter needs to be reported exemplar
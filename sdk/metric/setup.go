@@ -17,15 +17,18 @@ package metric
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
+	"go.opentelemetry.io/otel"
+	otelmetric "go.opentelemetry.io/otel/metric"
 
+	opentelemetry "trpc-system/go-opentelemetry"
 	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
-	"trpc-system/go-opentelemetry/sdk/metric/internal/registry"
 	"trpc-system/go-opentelemetry/sdk/remote"
 )
 
@@ -39,13 +42,23 @@ func Setup(opts ...SetupOption) error {
 	return SetupByConfig(*cfg)
 }
 
-var pushStopFunc func()
-var defaultPusher *push.Pusher
-
-// SetupByConfig setup by config
+// SetupByConfig setup by config, the started registration and Prometheus push are stopped by Shutdown
 func SetupByConfig(cfg Config) error {
+	h, err := SetupByConfigWithHandle(cfg)
+	if h != nil {
+		setDefaultHandle(h)
+		registerShutdownHookOnce.Do(func() {
+			opentelemetry.RegisterShutdownHook(Shutdown)
+		})
+	}
+	return err
+}
+
+// SetupByConfigWithHandle setup by config, and returns the handle to stop the registration and the
+// Prometheus push, the handle is nil if nothing is started
+func SetupByConfigWithHandle(cfg Config) (*Handle, error) {
 	if !cfg.Enabled {
-		return nil
+		return nil, nil
 	}
 	if len(cfg.ClientHistogramBuckets) != 0 {
 		setClientHandledHistogramBuckets(cfg.ClientHistogramBuckets)
@@ -53,36 +66,35 @@ func SetupByConfig(cfg Config) error {
 	if len(cfg.ServerHistogramBuckets) != 0 {
 		setServerHandledHistogramBuckets(cfg.ServerHistogramBuckets)
 	}
-	registerRPCServerCounter()
-	registerRPCClientCounter()
-	registerRPCHandledHistograms()
-	enableClientStreamHistograms()
+	if err := setupRPCRecorder(cfg); err != nil {
+		return nil, err
+	}
 	if cfg.ServerOwner != "" {
 		serverMetadata.WithLabelValues(cfg.ServerOwner, cfg.CmdbID).Set(1)
 	}
 	if cfg.Configurator != nil {
 		cfg.Configurator.RegisterConfigApplyFunc(genConfigApplyFunc(cfg))
 	}
-	// Etcd registration
+	// scrape target registration
 	if cfg.EnabledRegister {
-		if len(cfg.RegistryEndpoints) == 0 {
-			return errors.New("metric: registry endpoints nil")
-		}
 		if cfg.Instance.TenantID == "" {
-			return errors.New("metric: tenant id nil")
+			return nil, errors.New("metric: tenant id nil")
 		}
 		if cfg.Instance.Addr == "" {
-			return errors.New("metric: exporter addr nil")
+			return nil, errors.New("metric: exporter addr nil")
 		}
 		if cfg.TTL == 0 {
 			cfg.TTL = DefaultRegisterTTL
 		}
-		opts := []registry.EtcdOption{
-			registry.WithTLS(newTLSConfig(cfg.TLSCert)),
+		reg, err := newRegistry(cfg)
+		if err != nil {
+			return nil, err
 		}
-		reg := NewEtcdRegistry(cfg.RegistryEndpoints, cfg.Instance.TenantID, opts...)
-		_, err := reg.Register(context.Background(), &cfg.Instance, cfg.TTL)
-		return err
+		cancel, err := reg.Register(context.Background(), &cfg.Instance, cfg.TTL)
+		if err != nil {
+			return &Handle{registry: reg}, err
+		}
+		return &Handle{registry: reg, deregister: cancel}, nil
 	}
 	// prometheus push
 	if cfg.PrometheusPush.Enabled {
@@ -98,35 +110,52 @@ func SetupByConfig(cfg Config) error {
 		}
 		err := pusher.Push()
 		if err != nil {
-			return err
-		}
-		ctx, cancel := context.WithCancel(context.Background())
-		pushStopFunc = cancel
-		defaultPusher = pusher
-		if cfg.PrometheusPush.Interval > 0 {
-			ticker := time.NewTicker(cfg.PrometheusPush.Interval)
-			go func() {
-				for {
-					select {
-					case <-ticker.C:
-						_ = pusher.Push()
-					case <-ctx.Done():
-						return
-					}
-				}
-			}()
+			return nil, err
 		}
+		return startPush(pusher, cfg.PrometheusPush), nil
 	}
-	return nil
+	return nil, nil
 }
 
-// DeletePrometheusPush send delete request to prometheus push gateway
+// DeletePrometheusPush stops the Prometheus push started by SetupByConfig and
+// sends delete request to prometheus push gateway
 func DeletePrometheusPush() error {
-	if defaultPusher == nil {
+	h := getDefaultHandle()
+	if h == nil || h.pusher == nil {
 		return nil
 	}
-	pushStopFunc()
-	return defaultPusher.Delete()
+	h.stopPush()
+	return h.pusher.Delete()
+}
+
+// setupRPCRecorder registers the Prometheus collectors or creates the instruments of the backend
+func setupRPCRecorder(cfg Config) error {
+	setupRPCCardinalityLimiters(cfg.CardinalityLimit)
+	if err := setupRPCExemplarPolicies(cfg.Exemplar); err != nil {
+		return err
+	}
+	switch cfg.Backend {
+	case "", BackendPrometheus:
+		registerRPCServerCounter()
+		registerRPCClientCounter()
+		registerRPCHandledHistograms()
+		enableClientStreamHistograms()
+		setRPCRecorder(prometheusRecorder{})
+		return nil
+	case BackendOpenTelemetry:
+		provider := cfg.MeterProvider
+		if provider == nil {
+			provider = otel.GetMeterProvider()
+		}
+		r, err := newOTelRecorder(provider.Meter(instrumentationName))
+		if err != nil {
+			return err
+		}
+		setRPCRecorder(r)
+		return nil
+	default:
+		return fmt.Errorf("metric: unknown backend %q", cfg.Backend)
+	}
 }
 
 type pushHTTPDoer struct {
@@ -171,6 +200,13 @@ func WithEnabledZPage(enabled bool) SetupOption {
 	}
 }
 
+// WithEnabledServiceGraph serves the service graph by the admin server started by the metrics
+func WithEnabledServiceGraph(enabled bool) SetupOption {
+	return func(config *Config) {
+		config.EnabledServiceGraph = enabled
+	}
+}
+
 // WithInstance .
 func WithInstance(ins *Instance) SetupOption {
 	return func(config *Config) {
@@ -192,6 +228,27 @@ func WithRegistryEndpoints(endpoints []string) SetupOption {
 	}
 }
 
+// WithRegistryType set the type of the built-in or registered registry
+func WithRegistryType(registryType string) SetupOption {
+	return func(config *Config) {
+		config.RegistryType = registryType
+	}
+}
+
+// WithRegistry set the user defined registry, which takes precedence over the registry type
+func WithRegistry(reg Registry) SetupOption {
+	return func(config *Config) {
+		config.Registry = reg
+	}
+}
+
+// WithFileSDPath set the file written by the file_sd registry
+func WithFileSDPath(path string) SetupOption {
+	return func(config *Config) {
+		config.FileSDPath = path
+	}
+}
+
 // WithTLSCert .
 func WithTLSCert(cert TLSCert) SetupOption {
 	return func(config *Config) {
@@ -241,6 +298,34 @@ func genConfigApplyFunc(cfg Config) remote.ConfigApplyFunc {
 	}
 }
 
+// WithCardinalityLimit set the cardinality budgets of the RPC metrics
+func WithCardinalityLimit(c CardinalityLimitConfig) SetupOption {
+	return func(config *Config) {
+		config.CardinalityLimit = c
+	}
+}
+
+// WithExemplar set the exemplar policies of the RPC handled metrics
+func WithExemplar(c ExemplarConfig) SetupOption {
+	return func(config *Config) {
+		config.Exemplar = c
+	}
+}
+
+// WithBackend set the backend of the RPC metrics
+func WithBackend(backend string) SetupOption {
+	return func(config *Config) {
+		config.Backend = backend
+	}
+}
+
+// WithMeterProvider set the meter provider of BackendOpenTelemetry
+func WithMeterProvider(provider otelmetric.MeterProvider) SetupOption {
+	return func(config *Config) {
+		config.MeterProvider = provider
+	}
+}
+
 // WithMetricsPrometheusPush prometheus push config
 func WithMetricsPrometheusPush(p PrometheusPushConfig) SetupOption {
 	return func(config *Config) {
@@ -272,6 +357,9 @@ func WithMetricsPrometheusPush(p PrometheusPushConfig) SetupOption {
 		if len(p.HTTPHeaders) > 0 {
 			c.HTTPHeaders = p.HTTPHeaders
 		}
+		if p.DeleteOnShutdown {
+			c.DeleteOnShutdown = true
+		}
 		config.PrometheusPush = c
 	}
 }
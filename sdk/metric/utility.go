@@ -39,14 +39,11 @@ func defaultCleanRPCMethod(method string) string {
 		return strings.ToValidUTF8(method, "")
 	}
 	if method[0] == '/' { // http path
-		// 1. trim http query params (after char '?')
-		if idx := strings.IndexByte(method, '?'); idx > 0 {
-			method = method[:idx]
-		}
-		if v, ok := methodToPattern(method); ok {
+		// 1. trim http query params (after char '?'), 2. match the registered mappings or the learned templates
+		if v, ok := MethodPattern(method); ok {
 			return strings.ToValidUTF8(v, "")
 		}
-		// http服务只信任通过RegisterMethodMapping的pattern, 避免高基数问题
+		// http服务只信任通过RegisterMethodMapping/RegisterRouteTemplate的pattern, 避免高基数问题
 		return "default_pattern_method"
 	}
 	// 3. limit length<64
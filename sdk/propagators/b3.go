@@ -0,0 +1,140 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package propagators
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// B3 headers, see https://github.com/openzipkin/b3-propagation
+const (
+	b3ContextHeader      = "b3"
+	b3TraceIDHeader      = "x-b3-traceid"
+	b3SpanIDHeader       = "x-b3-spanid"
+	b3SampledHeader      = "x-b3-sampled"
+	b3ParentSpanIDHeader = "x-b3-parentspanid"
+	b3FlagsHeader        = "x-b3-flags"
+)
+
+// B3Encoding B3 header encoding
+type B3Encoding int
+
+// B3 encodings
+const (
+	// B3SingleHeader the single b3 header
+	B3SingleHeader B3Encoding = iota
+	// B3MultipleHeader the x-b3-* headers
+	B3MultipleHeader
+)
+
+// B3 propagates the Zipkin B3 headers, both encodings are extracted and the single header takes precedence
+type B3 struct {
+	InjectEncoding B3Encoding
+}
+
+var _ propagation.TextMapPropagator = B3{}
+
+// Inject injects the span context of ctx
+func (b B3) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	if b.InjectEncoding == B3MultipleHeader {
+		carrier.Set(b3TraceIDHeader, sc.TraceID().String())
+		carrier.Set(b3SpanIDHeader, sc.SpanID().String())
+		carrier.Set(b3SampledHeader, sampled)
+		return
+	}
+	carrier.Set(b3ContextHeader, sc.TraceID().String()+"-"+sc.SpanID().String()+"-"+sampled)
+}
+
+// Extract extracts the span context into ctx
+func (b B3) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	sc, ok := extractB3Single(carrier.Get(b3ContextHeader))
+	if !ok {
+		sc, ok = extractB3Multiple(carrier)
+	}
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the injected keys
+func (b B3) Fields() []string {
+	if b.InjectEncoding == B3MultipleHeader {
+		return []string{b3TraceIDHeader, b3SpanIDHeader, b3SampledHeader}
+	}
+	return []string{b3ContextHeader}
+}
+
+// extractB3Single extracts {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}, the last two are optional
+func extractB3Single(value string) (trace.SpanContext, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}, false
+	}
+	var sampling string
+	if len(parts) > 2 {
+		sampling = parts[2]
+	}
+	return newB3SpanContext(parts[0], parts[1], sampling, "")
+}
+
+func extractB3Multiple(carrier propagation.TextMapCarrier) (trace.SpanContext, bool) {
+	traceID, spanID := carrier.Get(b3TraceIDHeader), carrier.Get(b3SpanIDHeader)
+	if traceID == "" || spanID == "" {
+		return trace.SpanContext{}, false
+	}
+	return newB3SpanContext(traceID, spanID, carrier.Get(b3SampledHeader), carrier.Get(b3FlagsHeader))
+}
+
+func newB3SpanContext(traceID, spanID, sampling, flags string) (trace.SpanContext, bool) {
+	if len(traceID) != 16 && len(traceID) != 32 {
+		return trace.SpanContext{}, false
+	}
+	cfg := trace.SpanContextConfig{Remote: true}
+	var err error
+	if cfg.TraceID, err = parseTraceID(traceID); err != nil {
+		return trace.SpanContext{}, false
+	}
+	if cfg.SpanID, err = trace.SpanIDFromHex(spanID); err != nil {
+		return trace.SpanContext{}, false
+	}
+	switch {
+	case flags == "1", sampling == "d", sampling == "1", strings.EqualFold(sampling, "true"):
+		cfg.TraceFlags = trace.FlagsSampled
+	case sampling == "", sampling == "0", strings.EqualFold(sampling, "false"):
+	default:
+		return trace.SpanContext{}, false
+	}
+	return trace.NewSpanContext(cfg), true
+}
+
+// parseTraceID parses the 64 or 128 bits hex trace ID, the 64 bits one is left padded with zeros
+func parseTraceID(hex string) (trace.TraceID, error) {
+	if len(hex) < 32 {
+		hex = strings.Repeat("0", 32-len(hex)) + hex
+	}
+	return trace.TraceIDFromHex(hex)
+}
@@ -0,0 +1,82 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package propagators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	jaegerHeader = "uber-trace-id"
+	// jaegerFlagSampled the sampled bit of the flags, the debug bit implies sampled
+	jaegerFlagSampled = 0x01
+	jaegerFlagDebug   = 0x02
+)
+
+// Jaeger propagates the uber-trace-id header {trace-id}:{span-id}:{parent-span-id}:{flags}
+type Jaeger struct{}
+
+var _ propagation.TextMapPropagator = Jaeger{}
+
+// Inject injects the span context of ctx
+func (Jaeger) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	flags := 0
+	if sc.IsSampled() {
+		flags = jaegerFlagSampled
+	}
+	carrier.Set(jaegerHeader, fmt.Sprintf("%s:%s:0:%d", sc.TraceID(), sc.SpanID(), flags))
+}
+
+// Extract extracts the span context into ctx
+func (Jaeger) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	value, err := url.QueryUnescape(carrier.Get(jaegerHeader))
+	if err != nil || value == "" {
+		return ctx
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 || len(parts[0]) > 32 || len(parts[1]) > 16 {
+		return ctx
+	}
+	cfg := trace.SpanContextConfig{Remote: true}
+	if cfg.TraceID, err = parseTraceID(parts[0]); err != nil {
+		return ctx
+	}
+	if cfg.SpanID, err = trace.SpanIDFromHex(strings.Repeat("0", 16-len(parts[1])) + parts[1]); err != nil {
+		return ctx
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return ctx
+	}
+	if flags&(jaegerFlagSampled|jaegerFlagDebug) != 0 {
+		cfg.TraceFlags = trace.FlagsSampled
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(cfg))
+}
+
+// Fields returns the injected keys
+func (Jaeger) Fields() []string {
+	return []string{jaegerHeader}
+}
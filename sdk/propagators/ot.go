@@ -0,0 +1,98 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package propagators
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	otTraceIDHeader     = "ot-tracer-traceid"
+	otSpanIDHeader      = "ot-tracer-spanid"
+	otSampledHeader     = "ot-tracer-sampled"
+	otBaggageHeaderPref = "ot-baggage-"
+)
+
+// OT propagates the ot-tracer-* headers of the OpenTracing basic tracers, and the baggage as ot-baggage-*.
+// The lower 64 bits of the trace ID are injected.
+type OT struct{}
+
+var _ propagation.TextMapPropagator = OT{}
+
+// Inject injects the span context and the baggage of ctx
+func (OT) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	carrier.Set(otTraceIDHeader, sc.TraceID().String()[16:])
+	carrier.Set(otSpanIDHeader, sc.SpanID().String())
+	if sc.IsSampled() {
+		carrier.Set(otSampledHeader, "true")
+	} else {
+		carrier.Set(otSampledHeader, "false")
+	}
+	for _, m := range baggage.FromContext(ctx).Members() {
+		carrier.Set(otBaggageHeaderPref+m.Key(), m.Value())
+	}
+}
+
+// Extract extracts the span context and the baggage into ctx
+func (OT) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	traceID, spanID := carrier.Get(otTraceIDHeader), carrier.Get(otSpanIDHeader)
+	if len(traceID) != 16 && len(traceID) != 32 {
+		return ctx
+	}
+	cfg := trace.SpanContextConfig{Remote: true}
+	var err error
+	if cfg.TraceID, err = parseTraceID(traceID); err != nil {
+		return ctx
+	}
+	if cfg.SpanID, err = trace.SpanIDFromHex(spanID); err != nil {
+		return ctx
+	}
+	if strings.EqualFold(carrier.Get(otSampledHeader), "true") {
+		cfg.TraceFlags = trace.FlagsSampled
+	}
+	ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(cfg))
+
+	bag := baggage.FromContext(ctx)
+	for _, k := range carrier.Keys() {
+		key := strings.ToLower(k)
+		if !strings.HasPrefix(key, otBaggageHeaderPref) {
+			continue
+		}
+		m, err := baggage.NewMember(strings.TrimPrefix(key, otBaggageHeaderPref), carrier.Get(k))
+		if err != nil {
+			continue
+		}
+		if b, err := bag.SetMember(m); err == nil {
+			bag = b
+		}
+	}
+	if bag.Len() == 0 {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// Fields returns the injected keys, the baggage keys are not included
+func (OT) Fields() []string {
+	return []string{otTraceIDHeader, otSpanIDHeader, otSampledHeader}
+}
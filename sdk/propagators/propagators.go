@@ -0,0 +1,140 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package propagators provides the B3, Jaeger and OT trace context formats, and builds the propagator
+// whose extract order and inject set are configured separately by the registered names.
+package propagators
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// names of the built-in propagators
+const (
+	NameTraceContext = "tracecontext"
+	NameBaggage      = "baggage"
+	NameB3           = "b3"
+	NameB3Multi      = "b3multi"
+	NameJaeger       = "jaeger"
+	NameOT           = "ot"
+)
+
+var (
+	mu         sync.RWMutex
+	registered = map[string]propagation.TextMapPropagator{
+		NameTraceContext: propagation.TraceContext{},
+		NameBaggage:      propagation.Baggage{},
+		NameB3:           B3{InjectEncoding: B3SingleHeader},
+		NameB3Multi:      B3{InjectEncoding: B3MultipleHeader},
+		NameJaeger:       Jaeger{},
+		NameOT:           OT{},
+	}
+)
+
+// Register registers the propagator by name, the registered one with the same name is replaced
+func Register(name string, p propagation.TextMapPropagator) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered[name] = p
+}
+
+// Get returns the propagator registered by name
+func Get(name string) (propagation.TextMapPropagator, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := registered[name]
+	return p, ok
+}
+
+// New creates a propagator which extracts by the extract propagators in order and injects by all the
+// inject propagators. The inject set is the same as the extract one if empty, so the format can be
+// migrated gradually by extracting both the old and the new formats before injecting the new one only.
+func New(extract, inject []string) (propagation.TextMapPropagator, error) {
+	if len(inject) == 0 {
+		inject = extract
+	}
+	extractors, err := getAll(extract)
+	if err != nil {
+		return nil, err
+	}
+	injectors, err := getAll(inject)
+	if err != nil {
+		return nil, err
+	}
+	return &propagator{extractors: extractors, injectors: injectors}, nil
+}
+
+func getAll(names []string) ([]propagation.TextMapPropagator, error) {
+	result := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		p, ok := Get(name)
+		if !ok {
+			return nil, fmt.Errorf("propagator %q is not registered", name)
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// propagator the span context is extracted by the first extractor which finds a valid one,
+// the others still extract the baggage.
+type propagator struct {
+	extractors []propagation.TextMapPropagator
+	injectors  []propagation.TextMapPropagator
+}
+
+// Inject injects by all the inject propagators
+func (p *propagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	for _, i := range p.injectors {
+		i.Inject(ctx, carrier)
+	}
+}
+
+// Extract extracts by the extract propagators in order
+func (p *propagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	var extracted trace.SpanContext
+	for _, e := range p.extractors {
+		ctx = e.Extract(ctx, carrier)
+		if extracted.IsValid() {
+			// keep the span context extracted first
+			ctx = trace.ContextWithRemoteSpanContext(ctx, extracted)
+			continue
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() && sc.IsRemote() {
+			extracted = sc
+		}
+	}
+	return ctx
+}
+
+// Fields returns the keys of all the propagators
+func (p *propagator) Fields() []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, ps := range [][]propagation.TextMapPropagator{p.extractors, p.injectors} {
+		for _, prop := range ps {
+			for _, f := range prop.Fields() {
+				if !seen[f] {
+					seen[f] = true
+					fields = append(fields, f)
+				}
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,128 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package propagators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var testSpanContext = trace.NewSpanContext(trace.SpanContextConfig{
+	TraceID:    trace.TraceID{0x01, 0x02, 15: 0x0f},
+	SpanID:     trace.SpanID{0x03, 7: 0x07},
+	TraceFlags: trace.FlagsSampled,
+})
+
+func TestPropagators_RoundTrip(t *testing.T) {
+	for name, want := range map[string]map[string]string{
+		NameB3: {"b3": "0102000000000000000000000000000f-0300000000000007-1"},
+		NameB3Multi: {
+			"x-b3-traceid": "0102000000000000000000000000000f",
+			"x-b3-spanid":  "0300000000000007",
+			"x-b3-sampled": "1",
+		},
+		NameJaeger: {"uber-trace-id": "0102000000000000000000000000000f:0300000000000007:0:1"},
+		NameOT: {
+			"ot-tracer-traceid": "000000000000000f",
+			"ot-tracer-spanid":  "0300000000000007",
+			"ot-tracer-sampled": "true",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, ok := Get(name)
+			require.True(t, ok)
+			carrier := propagation.MapCarrier{}
+			p.Inject(trace.ContextWithSpanContext(context.Background(), testSpanContext), carrier)
+			assert.Equal(t, propagation.MapCarrier(want), carrier)
+
+			sc := trace.SpanContextFromContext(p.Extract(context.Background(), carrier))
+			assert.True(t, sc.IsRemote())
+			assert.True(t, sc.IsSampled())
+			assert.Equal(t, testSpanContext.SpanID(), sc.SpanID())
+			if name == NameOT {
+				assert.Equal(t, "0000000000000000000000000000000f", sc.TraceID().String(), "64 bits trace ID")
+				return
+			}
+			assert.Equal(t, testSpanContext.TraceID(), sc.TraceID())
+		})
+	}
+}
+
+func TestPropagators_Extract(t *testing.T) {
+	b3 := B3{}
+	sc := trace.SpanContextFromContext(b3.Extract(context.Background(), propagation.MapCarrier{
+		"b3": "000000000000000f-0300000000000007-d",
+	}))
+	assert.Equal(t, "0000000000000000000000000000000f", sc.TraceID().String())
+	assert.True(t, sc.IsSampled(), "debug implies sampled")
+	sc = trace.SpanContextFromContext(b3.Extract(context.Background(), propagation.MapCarrier{
+		"b3": "000000000000000f-0300000000000007-x",
+	}))
+	assert.False(t, sc.IsValid())
+
+	sc = trace.SpanContextFromContext(Jaeger{}.Extract(context.Background(), propagation.MapCarrier{
+		"uber-trace-id": "f%3A7%3A0%3A2",
+	}))
+	assert.Equal(t, "0000000000000000000000000000000f", sc.TraceID().String())
+	assert.Equal(t, "0000000000000007", sc.SpanID().String())
+	assert.True(t, sc.IsSampled())
+
+	ctx := OT{}.Extract(context.Background(), propagation.MapCarrier{
+		"ot-tracer-traceid":  "000000000000000f",
+		"ot-tracer-spanid":   "0300000000000007",
+		"ot-tracer-sampled":  "false",
+		"ot-baggage-user-id": "1",
+	})
+	assert.False(t, trace.SpanContextFromContext(ctx).IsSampled())
+	assert.Equal(t, "1", baggage.FromContext(ctx).Member("user-id").Value())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New([]string{NameTraceContext, "unknown"}, nil)
+	assert.Error(t, err)
+
+	p, err := New([]string{NameB3, NameTraceContext, NameBaggage}, []string{NameTraceContext, NameBaggage})
+	require.NoError(t, err)
+	member, err := baggage.NewMember("uid", "1")
+	require.NoError(t, err)
+	bag, err := baggage.New(member)
+	require.NoError(t, err)
+	ctx := baggage.ContextWithBaggage(trace.ContextWithSpanContext(context.Background(), testSpanContext), bag)
+
+	carrier := propagation.MapCarrier{}
+	p.Inject(ctx, carrier)
+	assert.ElementsMatch(t, []string{"traceparent", "baggage"}, carrier.Keys(), "b3 is extracted only")
+
+	// b3 is extracted first
+	carrier["b3"] = "0000000000000000000000000000000a-000000000000000b-0"
+	ctx = p.Extract(context.Background(), carrier)
+	sc := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, "0000000000000000000000000000000a", sc.TraceID().String())
+	assert.Equal(t, "1", baggage.FromContext(ctx).Member("uid").Value(), "baggage is still extracted")
+
+	delete(carrier, "b3")
+	sc = trace.SpanContextFromContext(p.Extract(context.Background(), carrier))
+	assert.Equal(t, testSpanContext.TraceID(), sc.TraceID(), "falls back to tracecontext")
+	assert.ElementsMatch(t, []string{"b3", "traceparent", "tracestate", "baggage"}, p.Fields())
+
+	Register("custom", Jaeger{})
+	_, err = New([]string{"custom"}, nil)
+	assert.NoError(t, err)
+}
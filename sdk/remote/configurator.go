@@ -16,15 +16,20 @@ package remote
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
 )
@@ -32,11 +37,25 @@ import (
 // ConfigApplyFunc ...
 type ConfigApplyFunc func(config *operation.Operation) error
 
+// ConfigPrepareFunc validates the config and returns the function to apply it.
+// A config is applied atomically: the apply functions are called only if all
+// prepare functions succeed, otherwise the config is rejected and none of them is called.
+type ConfigPrepareFunc func(config *operation.Operation) (apply func(), err error)
+
 // Configurator called when config changed
 type Configurator interface {
 	RegisterConfigApplyFunc(fn ConfigApplyFunc)
+	RegisterConfigPrepareFunc(fn ConfigPrepareFunc)
+	// Close stops watching the remote config.
+	Close() error
 }
 
+const (
+	// minRetryInterval the first retry interval after the remote service fails
+	minRetryInterval = 500 * time.Millisecond
+	requestTimeout   = 5 * time.Second
+)
+
 type remoteConfigurator struct {
 	remoteServiceAddr string
 	syncInterval      time.Duration
@@ -45,16 +64,34 @@ type remoteConfigurator struct {
 	server            string
 	debug             bool
 
-	client              operation.OperationServiceClient
-	lastConfig          *operation.Operation
-	configApplyFuncList []ConfigApplyFunc
-	// mu Protect lastConfig/configApplyFuncList.
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{} // closed when syncDaemon exits, nil if not started
+	conn   *grpc.ClientConn
+
+	client                operation.OperationServiceClient
+	lastConfig            *operation.Operation
+	lastVersion           string
+	configApplyFuncList   []ConfigApplyFunc
+	configPrepareFuncList []ConfigPrepareFunc
+	// mu Protect lastConfig/lastVersion/configApplyFuncList/configPrepareFuncList.
 	mu sync.Mutex
 }
 
-// NewRemoteConfigurator create a new remoteConfigurator
+// NewRemoteConfigurator create a new remoteConfigurator. The config is pushed by WatchOperation,
+// and polled every syncInterval if the remote service doesn't implement it.
 func NewRemoteConfigurator(remoteServiceAddr string, syncInterval time.Duration,
 	tenantID, app, server string) Configurator {
+	rc := newRemoteConfigurator(remoteServiceAddr, syncInterval, tenantID, app, server)
+	if rc.remoteServiceAddr != "" {
+		rc.done = make(chan struct{})
+		go rc.syncDaemon()
+	}
+	return rc
+}
+
+func newRemoteConfigurator(remoteServiceAddr string, syncInterval time.Duration,
+	tenantID, app, server string) *remoteConfigurator {
 	if syncInterval == 0 {
 		syncInterval = time.Minute
 	}
@@ -65,65 +102,224 @@ func NewRemoteConfigurator(remoteServiceAddr string, syncInterval time.Duration,
 		app:               app,
 		server:            server,
 	}
+	rc.ctx, rc.cancel = context.WithCancel(context.Background())
 	// export OTEL_TRACE=remote
 	if otelTraceEnv := os.Getenv("OTEL_TRACE"); strings.Contains(otelTraceEnv, "remote") {
 		log.Printf("opentelemetry: env OTEL_TRACE:%s", otelTraceEnv)
 		rc.debug = true
 	}
-	if rc.remoteServiceAddr != "" {
-		go rc.syncDaemon()
-	}
 	return rc
 }
 
+// Close stops the sync daemon and closes the connection to the remote service.
+func (rc *remoteConfigurator) Close() error {
+	rc.cancel()
+	if rc.done != nil {
+		<-rc.done
+	}
+	if rc.conn != nil {
+		return rc.conn.Close()
+	}
+	return nil
+}
+
 func (rc *remoteConfigurator) syncDaemon() {
+	defer close(rc.done)
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = minRetryInterval
+	b.MaxInterval = rc.syncInterval
+	b.MaxElapsedTime = 0
 	for {
-		rc.sync()
-		time.Sleep(rc.syncInterval)
+		var wait time.Duration
+		err := rc.watch(b)
+		if status.Code(err) == codes.Unimplemented {
+			// fall back to polling, watch is retried on the next sync
+			if err = rc.sync(); err == nil {
+				b.Reset()
+				wait = rc.syncInterval
+			}
+		}
+		if err != nil {
+			// the retry interval is reset by received configs, so a broken stream is reconnected quickly
+			wait = b.NextBackOff()
+			if rc.debug {
+				log.Printf("opentelemetry: remote sync err:%v, retry after %s", err, wait)
+			}
+		}
+		select {
+		case <-rc.ctx.Done():
+			return
+		case <-time.After(wait):
+		}
 	}
 }
 
-func (rc *remoteConfigurator) sync() {
+func (rc *remoteConfigurator) getClient() (operation.OperationServiceClient, error) {
 	if rc.client == nil {
 		cc, err := grpc.Dial(rc.remoteServiceAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		if err != nil {
-			if rc.debug {
-				log.Printf("opentelemetry: remote dial err:%v", err)
-			}
-			return
+			return nil, err
 		}
+		rc.conn = cc
 		rc.client = operation.NewOperationServiceClient(cc)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-	ctx = metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
+	return rc.client, nil
+}
+
+func (rc *remoteConfigurator) outgoingContext(ctx context.Context) context.Context {
+	return metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
 		"x-tps-tenantid": rc.tenantID,
 	}))
+}
+
+// watch receives the configs pushed by the remote service until the stream is broken.
+func (rc *remoteConfigurator) watch(b backoff.BackOff) error {
+	client, err := rc.getClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(rc.outgoingContext(rc.ctx))
+	defer cancel()
+	rc.mu.Lock()
+	version := rc.lastVersion
+	rc.mu.Unlock()
+	stream, err := client.WatchOperation(ctx, &operation.WatchOperationRequest{
+		Tenant:  rc.tenantID,
+		App:     rc.app,
+		Server:  rc.server,
+		Version: version,
+	}, grpc.WaitForReady(true))
+	if err != nil {
+		return err
+	}
+	for {
+		rsp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		b.Reset()
+		if rc.debug {
+			log.Printf("opentelemetry: remote WatchOperation result:%+v", rsp)
+		}
+		rc.update(rsp.GetOperation())
+	}
+}
+
+func (rc *remoteConfigurator) sync() error {
+	client, err := rc.getClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(rc.outgoingContext(rc.ctx), requestTimeout)
+	defer cancel()
 	req := &operation.GetOperationRequest{
 		Tenant: rc.tenantID,
 		App:    rc.app,
 		Server: rc.server,
 	}
-	rsp, err := rc.client.GetOperation(ctx, req, grpc.WaitForReady(true))
+	rsp, err := client.GetOperation(ctx, req, grpc.WaitForReady(true))
 	if err != nil {
-		if rc.debug {
-			log.Printf("opentelemetry: remote GetOperation err:%v", err)
-		}
-		return
+		return err
 	}
 	if rc.debug {
 		log.Printf("opentelemetry: remote GetOperation result:%+v", rsp)
 	}
+	rc.update(rsp.GetOperation())
+	return nil
+}
+
+// update applies the config if its version is changed, and reports the result.
+func (rc *remoteConfigurator) update(config *operation.Operation) {
 	rc.mu.Lock()
-	defer rc.mu.Unlock()
-	rc.lastConfig = rsp.GetOperation()
+	version := config.GetVersion()
+	if version != "" && version == rc.lastVersion {
+		// applied or rejected already
+		rc.mu.Unlock()
+		return
+	}
+	rc.lastVersion = version
+	err := rc.applyLocked(config)
+	rc.mu.Unlock()
+	if err != nil && rc.debug {
+		log.Printf("opentelemetry: remote apply version:%s err:%v", version, err)
+	}
+	if version != "" {
+		rc.report(version, err)
+	}
+}
+
+// applyLocked prepares the config by all prepare functions, and applies it only if all of them succeed.
+func (rc *remoteConfigurator) applyLocked(config *operation.Operation) error {
+	applies, err := prepare(rc.configPrepareFuncList, config)
+	if err != nil {
+		return &rejectedError{err: err}
+	}
+	for _, apply := range applies {
+		apply()
+	}
+	rc.lastConfig = config
+	var errs []string
 	for _, v := range rc.configApplyFuncList {
-		if err = v(rsp.GetOperation()); err != nil {
-			if rc.debug {
-				log.Printf("opentelemetry: remote apply err:%v", err)
-			}
+		if err := v(config); err != nil {
+			errs = append(errs, err.Error())
 		}
 	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func prepare(fns []ConfigPrepareFunc, config *operation.Operation) ([]func(), error) {
+	applies := make([]func(), 0, len(fns))
+	for _, fn := range fns {
+		apply, err := fn(config)
+		if err != nil {
+			return nil, err
+		}
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+	return applies, nil
+}
+
+// rejectedError the config is rejected by a prepare function, nothing is applied.
+type rejectedError struct {
+	err error
+}
+
+func (e *rejectedError) Error() string {
+	return fmt.Sprintf("config rejected: %v", e.err)
+}
+
+func (e *rejectedError) Unwrap() error {
+	return e.err
+}
+
+// report reports the result of applying the config version back to the remote service.
+func (rc *remoteConfigurator) report(version string, applyErr error) {
+	ctx, cancel := context.WithTimeout(rc.outgoingContext(rc.ctx), requestTimeout)
+	defer cancel()
+	hostname, _ := os.Hostname()
+	req := &operation.ReportOperationStatusRequest{
+		Tenant:   rc.tenantID,
+		App:      rc.app,
+		Server:   rc.server,
+		Instance: hostname,
+		Version:  version,
+		Applied:  true,
+	}
+	var rejected *rejectedError
+	if errors.As(applyErr, &rejected) {
+		req.Applied = false
+	}
+	if applyErr != nil {
+		req.Error = applyErr.Error()
+	}
+	if _, err := rc.client.ReportOperationStatus(ctx, req); err != nil && rc.debug {
+		log.Printf("opentelemetry: remote ReportOperationStatus err:%v", err)
+	}
 }
 
 // RegisterConfigApplyFunc register config change handler
@@ -138,3 +334,23 @@ func (rc *remoteConfigurator) RegisterConfigApplyFunc(fn ConfigApplyFunc) {
 		}
 	}
 }
+
+// RegisterConfigPrepareFunc register config change handler which is applied atomically with the others
+func (rc *remoteConfigurator) RegisterConfigPrepareFunc(fn ConfigPrepareFunc) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.configPrepareFuncList = append(rc.configPrepareFuncList, fn)
+	// Apply on register for async setup.
+	if rc.lastConfig != nil {
+		apply, err := fn(rc.lastConfig)
+		if err != nil {
+			if rc.debug {
+				log.Printf("opentelemetry: remote apply err:%v", err)
+			}
+			return
+		}
+		if apply != nil {
+			apply()
+		}
+	}
+}
@@ -0,0 +1,233 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc-system/go-opentelemetry/sdk/remote/remotetest"
+)
+
+type fakeOperationClient struct {
+	operation.OperationServiceClient
+
+	mu      sync.Mutex
+	config  *operation.Operation
+	reports []*operation.ReportOperationStatusRequest
+}
+
+func (c *fakeOperationClient) GetOperation(context.Context, *operation.GetOperationRequest,
+	...grpc.CallOption) (*operation.GetOperationResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &operation.GetOperationResponse{Operation: c.config}, nil
+}
+
+func (c *fakeOperationClient) ReportOperationStatus(_ context.Context, req *operation.ReportOperationStatusRequest,
+	_ ...grpc.CallOption) (*operation.ReportOperationStatusResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reports = append(c.reports, req)
+	return &operation.ReportOperationStatusResponse{}, nil
+}
+
+func (c *fakeOperationClient) setConfig(config *operation.Operation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config = config
+}
+
+func newTestConfigurator(client operation.OperationServiceClient) *remoteConfigurator {
+	rc := newRemoteConfigurator("", 0, "tenant", "app", "server")
+	rc.client = client
+	return rc
+}
+
+func TestRemoteConfigurator_PrepareAtomically(t *testing.T) {
+	client := &fakeOperationClient{}
+	rc := newTestConfigurator(client)
+
+	var levels []string
+	rc.RegisterConfigPrepareFunc(func(config *operation.Operation) (func(), error) {
+		level := config.GetLog().GetLevel()
+		return func() { levels = append(levels, level) }, nil
+	})
+	rc.RegisterConfigPrepareFunc(func(config *operation.Operation) (func(), error) {
+		if config.GetSampler().GetFraction() > 1 {
+			return nil, errors.New("invalid fraction")
+		}
+		return nil, nil
+	})
+	var applied int
+	rc.RegisterConfigApplyFunc(func(*operation.Operation) error {
+		applied++
+		return nil
+	})
+
+	client.setConfig(&operation.Operation{
+		Version: "1",
+		Sampler: &operation.Sampler{Fraction: 0.5},
+		Log:     &operation.Log{Level: "debug"},
+	})
+	require.NoError(t, rc.sync())
+	assert.Equal(t, []string{"debug"}, levels)
+	assert.Equal(t, 1, applied)
+
+	client.setConfig(&operation.Operation{
+		Version: "2",
+		Sampler: &operation.Sampler{Fraction: 2},
+		Log:     &operation.Log{Level: "error"},
+	})
+	require.NoError(t, rc.sync())
+	assert.Equal(t, []string{"debug"}, levels, "rejected config must not be applied partially")
+	assert.Equal(t, 1, applied)
+
+	require.Len(t, client.reports, 2)
+	assert.Equal(t, "1", client.reports[0].GetVersion())
+	assert.True(t, client.reports[0].GetApplied())
+	assert.Equal(t, "2", client.reports[1].GetVersion())
+	assert.False(t, client.reports[1].GetApplied())
+	assert.Contains(t, client.reports[1].GetError(), "invalid fraction")
+}
+
+func TestRemoteConfigurator_SkipSameVersion(t *testing.T) {
+	client := &fakeOperationClient{}
+	rc := newTestConfigurator(client)
+	var prepared int
+	rc.RegisterConfigPrepareFunc(func(*operation.Operation) (func(), error) {
+		prepared++
+		return nil, nil
+	})
+
+	client.setConfig(&operation.Operation{Version: "1"})
+	require.NoError(t, rc.sync())
+	require.NoError(t, rc.sync())
+	assert.Equal(t, 1, prepared)
+	assert.Len(t, client.reports, 1)
+
+	client.setConfig(&operation.Operation{Version: "2"})
+	require.NoError(t, rc.sync())
+	assert.Equal(t, 2, prepared)
+	assert.Len(t, client.reports, 2)
+}
+
+func TestRemoteConfigurator_ApplyOnRegister(t *testing.T) {
+	client := &fakeOperationClient{}
+	rc := newTestConfigurator(client)
+	client.setConfig(&operation.Operation{Version: "1", Log: &operation.Log{Level: "warn"}})
+	require.NoError(t, rc.sync())
+
+	var level string
+	rc.RegisterConfigPrepareFunc(func(config *operation.Operation) (func(), error) {
+		return func() { level = config.GetLog().GetLevel() }, nil
+	})
+	assert.Equal(t, "warn", level)
+}
+
+func startTestServer(t *testing.T, opts ...remotetest.Option) (*remotetest.Server, string) {
+	s := remotetest.NewServer(opts...)
+	addr, err := s.Start()
+	require.NoError(t, err)
+	t.Cleanup(s.Stop)
+	return s, addr
+}
+
+func setOperation(t *testing.T, addr string, op *operation.Operation) {
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-tps-tenantid", "tenant"))
+	_, err = operation.NewOperationServiceClient(cc).SetOperation(ctx, &operation.SetOperationRequest{Operation: op})
+	require.NoError(t, err)
+}
+
+func TestRemoteConfigurator_Watch(t *testing.T) {
+	s, addr := startTestServer(t)
+	setOperation(t, addr, &operation.Operation{
+		Version: "1",
+		Service: &operation.Service{Name: "app.server"},
+		Log:     &operation.Log{Level: "debug"},
+	})
+
+	var level atomic.Value
+	rc := NewRemoteConfigurator(addr, time.Hour, "tenant", "app", "server")
+	defer rc.Close()
+	rc.RegisterConfigPrepareFunc(func(config *operation.Operation) (func(), error) {
+		return func() { level.Store(config.GetLog().GetLevel()) }, nil
+	})
+	assert.Eventually(t, func() bool { return level.Load() == "debug" }, 5*time.Second, 10*time.Millisecond)
+
+	// pushed without waiting for the sync interval
+	setOperation(t, addr, &operation.Operation{
+		Version: "2",
+		Service: &operation.Service{Name: "app.server"},
+		Log:     &operation.Log{Level: "error"},
+	})
+	assert.Eventually(t, func() bool { return level.Load() == "error" }, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		status := s.OperationStatus("tenant", "app", "server")
+		return len(status) == 1 && status[0].GetVersion() == "2" && status[0].GetApplied()
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRemoteConfigurator_PollingFallback(t *testing.T) {
+	_, addr := startTestServer(t, remotetest.WithWatchDisabled())
+	setOperation(t, addr, &operation.Operation{
+		Version: "1",
+		Service: &operation.Service{Name: "app.server"},
+		Log:     &operation.Log{Level: "debug"},
+	})
+
+	var level atomic.Value
+	rc := NewRemoteConfigurator(addr, 50*time.Millisecond, "tenant", "app", "server")
+	defer rc.Close()
+	rc.RegisterConfigPrepareFunc(func(config *operation.Operation) (func(), error) {
+		return func() { level.Store(config.GetLog().GetLevel()) }, nil
+	})
+	assert.Eventually(t, func() bool { return level.Load() == "debug" }, 5*time.Second, 10*time.Millisecond)
+
+	setOperation(t, addr, &operation.Operation{
+		Version: "2",
+		Service: &operation.Service{Name: "app.server"},
+		Log:     &operation.Log{Level: "error"},
+	})
+	assert.Eventually(t, func() bool { return level.Load() == "error" }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRemoteConfigurator_Close(t *testing.T) {
+	_, addr := startTestServer(t)
+	rc := NewRemoteConfigurator(addr, time.Hour, "tenant", "app", "server")
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, rc.Close())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close is blocked by the sync daemon")
+	}
+}
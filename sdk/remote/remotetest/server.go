@@ -0,0 +1,94 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package remotetest provides an in-memory OperationService and SamplerService server,
+// which stands in for the remote services to test how the config is applied locally.
+package remotetest
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+	"trpc-system/go-opentelemetry/pkg/remoteserver"
+)
+
+// Server the reference server on the memory store, served on a random local port
+type Server struct {
+	*remoteserver.Server
+
+	watchDisabled bool
+	grpcServer    *grpc.Server
+}
+
+// Option server option
+type Option func(*Server)
+
+// WithWatchDisabled makes WatchOperation and WatchSampler return Unimplemented like an old server
+func WithWatchDisabled() Option {
+	return func(s *Server) {
+		s.watchDisabled = true
+	}
+}
+
+// NewServer create a new in-memory server
+func NewServer(opts ...Option) *Server {
+	s := &Server{Server: remoteserver.NewServer(remoteserver.NewMemoryStore())}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start serves on a random local port and returns the address
+func (s *Server) Start() (string, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	s.grpcServer = grpc.NewServer()
+	operation.RegisterOperationServiceServer(s.grpcServer, s)
+	sampler.RegisterSamplerServiceServer(s.grpcServer, s)
+	go func() {
+		_ = s.grpcServer.Serve(lis)
+	}()
+	return lis.Addr().String(), nil
+}
+
+// Stop stops the server and closes all watch streams
+func (s *Server) Stop() {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+}
+
+// WatchOperation returns Unimplemented if watch is disabled
+func (s *Server) WatchOperation(req *operation.WatchOperationRequest,
+	stream operation.OperationService_WatchOperationServer) error {
+	if s.watchDisabled {
+		return status.Error(codes.Unimplemented, "method WatchOperation not implemented")
+	}
+	return s.Server.WatchOperation(req, stream)
+}
+
+// WatchSampler returns Unimplemented if watch is disabled
+func (s *Server) WatchSampler(req *sampler.WatchSamplerRequest, stream sampler.SamplerService_WatchSamplerServer) error {
+	if s.watchDisabled {
+		return status.Error(codes.Unimplemented, "method WatchSampler not implemented")
+	}
+	return s.Server.WatchSampler(req, stream)
+}
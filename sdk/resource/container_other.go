@@ -0,0 +1,31 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build !linux
+// +build !linux
+
+package resource
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// ContainerDetector detects the container ID from /proc/self/cgroup, which is only available on linux
+type ContainerDetector struct{}
+
+// Detect implements resource.Detector
+func (ContainerDetector) Detect(context.Context) (*resource.Resource, error) {
+	return resource.Empty(), nil
+}
@@ -0,0 +1,188 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package resource detects the resource of the host, process, container and Kubernetes pod
+package resource
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+
+	"trpc-system/go-opentelemetry/api"
+	ecosystemruntime "trpc-system/go-opentelemetry/pkg/runtime"
+)
+
+// names of the detectors of NamedDetectors
+const (
+	DetectorHost       = "host"
+	DetectorProcess    = "process"
+	DetectorContainer  = "container"
+	DetectorKubernetes = "kubernetes"
+	DetectorQuota      = "quota"
+)
+
+// DefaultDetectors returns the detectors of the host, process, container, Kubernetes pod and quota
+func DefaultDetectors() []resource.Detector {
+	return []resource.Detector{
+		HostDetector{},
+		ProcessDetector{},
+		ContainerDetector{},
+		KubernetesDetector{},
+		QuotaDetector{},
+	}
+}
+
+// NamedDetectors returns the detectors by name, podInfoDir is the downward API volume of the kubernetes detector
+func NamedDetectors(names []string, podInfoDir string) ([]resource.Detector, error) {
+	detectors := make([]resource.Detector, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case DetectorHost:
+			detectors = append(detectors, HostDetector{})
+		case DetectorProcess:
+			detectors = append(detectors, ProcessDetector{})
+		case DetectorContainer:
+			detectors = append(detectors, ContainerDetector{})
+		case DetectorKubernetes:
+			detectors = append(detectors, KubernetesDetector{PodInfoDir: podInfoDir})
+		case DetectorQuota:
+			detectors = append(detectors, QuotaDetector{})
+		default:
+			return nil, fmt.Errorf("resource: unknown detector %q", name)
+		}
+	}
+	return detectors, nil
+}
+
+// Detect returns the resource detected by the detectors in order, the later ones take precedence.
+// The errors of the detectors are logged, and the attributes detected are kept.
+func Detect(ctx context.Context, detectors ...resource.Detector) *resource.Resource {
+	res := resource.Empty()
+	for _, d := range detectors {
+		if d == nil {
+			continue
+		}
+		r, err := d.Detect(ctx)
+		if err != nil {
+			log.Printf("opentelemetry: detect resource by %T: %v", d, err)
+		}
+		res = Merge(res, r)
+	}
+	return res
+}
+
+// Merge returns the resource of the attributes of a and b, b takes precedence.
+// Unlike resource.Merge, the schema URLs are dropped instead of conflicting.
+func Merge(a, b *resource.Resource) *resource.Resource {
+	var kvs []attribute.KeyValue
+	if a != nil {
+		kvs = append(kvs, a.Attributes()...)
+	}
+	if b != nil {
+		kvs = append(kvs, b.Attributes()...)
+	}
+	return resource.NewSchemaless(kvs...)
+}
+
+// EnvDetector detects the resource from OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME
+type EnvDetector struct{}
+
+// Detect implements resource.Detector
+func (EnvDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	return resource.New(ctx, resource.WithFromEnv())
+}
+
+// HostDetector detects the host name, the first non-loopback IP, the architecture and the OS type
+type HostDetector struct{}
+
+// Detect implements resource.Detector
+func (HostDetector) Detect(context.Context) (*resource.Resource, error) {
+	kvs := []attribute.KeyValue{
+		semconv.HostArchKey.String(runtime.GOARCH),
+		semconv.OSTypeKey.String(runtime.GOOS),
+	}
+	if ip := hostIP(); ip != "" {
+		kvs = append(kvs, api.HostIPKey.String(ip))
+	}
+	name, err := os.Hostname()
+	if err == nil {
+		kvs = append(kvs, semconv.HostNameKey.String(name))
+	}
+	return resource.NewSchemaless(kvs...), err
+}
+
+// hostIP returns the first non-loopback IPv4, or the first non-loopback IPv6 if there is no IPv4
+func hostIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	var ipv6 string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if ip := ipNet.IP.To4(); ip != nil {
+			return ip.String()
+		}
+		if ipv6 == "" {
+			ipv6 = ipNet.IP.String()
+		}
+	}
+	return ipv6
+}
+
+// ProcessDetector detects the PID, the executable and the Go runtime of the process
+type ProcessDetector struct{}
+
+// Detect implements resource.Detector
+func (ProcessDetector) Detect(context.Context) (*resource.Resource, error) {
+	kvs := []attribute.KeyValue{
+		semconv.ProcessPIDKey.Int(os.Getpid()),
+		semconv.ProcessRuntimeNameKey.String("go"),
+		semconv.ProcessRuntimeVersionKey.String(runtime.Version()),
+	}
+	path, err := os.Executable()
+	if err == nil {
+		kvs = append(kvs,
+			semconv.ProcessExecutableNameKey.String(filepath.Base(path)),
+			semconv.ProcessExecutablePathKey.String(path))
+	}
+	return resource.NewSchemaless(kvs...), err
+}
+
+// QuotaDetector detects the CPU cores and the memory bytes available to the process by pkg/runtime,
+// which are the cgroup quota in container. The quota not available on the platform is skipped.
+type QuotaDetector struct{}
+
+// Detect implements resource.Detector
+func (QuotaDetector) Detect(context.Context) (*resource.Resource, error) {
+	var kvs []attribute.KeyValue
+	if cpu, _ := ecosystemruntime.CPUQuota(); cpu > 0 {
+		kvs = append(kvs, api.TpsCPUQuotaKey.Float64(cpu))
+	}
+	if memory, _ := ecosystemruntime.MemoryQuota(); memory > 0 {
+		kvs = append(kvs, api.TpsMemoryQuotaKey.Int64(memory))
+	}
+	return resource.NewSchemaless(kvs...), nil
+}
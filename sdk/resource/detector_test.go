@@ -0,0 +1,94 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package resource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+func resourceValue(res *resource.Resource, key attribute.Key) (string, bool) {
+	v, ok := res.Set().Value(key)
+	return v.Emit(), ok
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "host.name=from-env,deployment.environment=test")
+	res := Detect(context.Background(), HostDetector{}, ProcessDetector{}, nil, EnvDetector{})
+
+	v, _ := resourceValue(res, semconv.HostNameKey)
+	assert.Equal(t, "from-env", v)
+	v, _ = resourceValue(res, semconv.DeploymentEnvironmentKey)
+	assert.Equal(t, "test", v)
+	v, _ = resourceValue(res, semconv.ProcessPIDKey)
+	assert.Equal(t, attribute.IntValue(os.Getpid()).Emit(), v)
+	_, ok := resourceValue(res, semconv.ProcessRuntimeVersionKey)
+	assert.True(t, ok)
+	assert.Equal(t, "", res.SchemaURL())
+}
+
+func TestMerge(t *testing.T) {
+	a := resource.NewWithAttributes(semconv.SchemaURL, attribute.String("k1", "a"), attribute.String("k2", "a"))
+	b := resource.NewWithAttributes("https://opentelemetry.io/schemas/1.17.0", attribute.String("k2", "b"))
+	res := Merge(a, b)
+	v, _ := resourceValue(res, "k1")
+	assert.Equal(t, "a", v)
+	v, _ = resourceValue(res, "k2")
+	assert.Equal(t, "b", v)
+	assert.Equal(t, 2, Merge(res, nil).Len())
+}
+
+func TestKubernetesDetector(t *testing.T) {
+	res, err := KubernetesDetector{}.Detect(context.Background())
+	require.Nil(t, err)
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		assert.Equal(t, 0, res.Len())
+	}
+
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "k8s.pod.uid"), []byte("uid-1\n"), 0o644))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "k8s.namespace.name"), []byte("from-file"), 0o644))
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("POD_NAME", "pod-1")
+	t.Setenv("POD_NAMESPACE", "ns-1")
+	t.Setenv("NODE_NAME", "node-1")
+	res, err = KubernetesDetector{PodInfoDir: dir}.Detect(context.Background())
+	require.Nil(t, err)
+	for key, expected := range map[attribute.Key]string{
+		semconv.K8SPodNameKey:       "pod-1",
+		semconv.K8SPodUIDKey:        "uid-1",
+		semconv.K8SNamespaceNameKey: "ns-1",
+		semconv.K8SNodeNameKey:      "node-1",
+	} {
+		v, _ := resourceValue(res, key)
+		assert.Equal(t, expected, v, key)
+	}
+}
+
+func TestNamedDetectors(t *testing.T) {
+	detectors, err := NamedDetectors([]string{DetectorHost, DetectorKubernetes}, "/podinfo")
+	require.Nil(t, err)
+	assert.Equal(t, []resource.Detector{HostDetector{}, KubernetesDetector{PodInfoDir: "/podinfo"}}, detectors)
+	_, err = NamedDetectors([]string{"unknown"}, "")
+	assert.NotNil(t, err)
+	assert.Len(t, DefaultDetectors(), 5)
+}
@@ -0,0 +1,103 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package resource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+const (
+	// DefaultPodInfoDir the default mount path of the downward API volume
+	DefaultPodInfoDir = "/etc/podinfo"
+	// serviceAccountNamespaceFile the namespace of the service account mounted in every pod
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// kubernetesAttributes the attributes detected from the downward API, the environment variables are
+// looked up in order, and the file of the attribute name in the pod info directory is read if none is set
+var kubernetesAttributes = []struct {
+	key  attribute.Key
+	envs []string
+}{
+	{key: semconv.K8SPodNameKey, envs: []string{"K8S_POD_NAME", "POD_NAME"}},
+	{key: semconv.K8SPodUIDKey, envs: []string{"K8S_POD_UID", "POD_UID"}},
+	{key: semconv.K8SNamespaceNameKey, envs: []string{"K8S_NAMESPACE_NAME", "POD_NAMESPACE"}},
+	{key: semconv.K8SNodeNameKey, envs: []string{"K8S_NODE_NAME", "NODE_NAME"}},
+}
+
+// KubernetesDetector detects the pod name, pod UID, namespace and node name when running in Kubernetes.
+// The values are read from the environment variables set by the downward API, e.g. K8S_POD_NAME or POD_NAME,
+// then from the files of the downward API volume named after the attributes, e.g. /etc/podinfo/k8s.pod.name.
+// The pod name falls back to the host name and the namespace to that of the service account.
+type KubernetesDetector struct {
+	// PodInfoDir the mount path of the downward API volume, default DefaultPodInfoDir
+	PodInfoDir string
+}
+
+// Detect implements resource.Detector
+func (d KubernetesDetector) Detect(context.Context) (*resource.Resource, error) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return resource.Empty(), nil
+	}
+	dir := d.PodInfoDir
+	if dir == "" {
+		dir = DefaultPodInfoDir
+	}
+	values := make(map[attribute.Key]string, len(kubernetesAttributes))
+	var kvs []attribute.KeyValue
+	for _, a := range kubernetesAttributes {
+		v := lookupEnv(a.envs)
+		if v == "" {
+			v = readFile(filepath.Join(dir, string(a.key)))
+		}
+		values[a.key] = v
+	}
+	if values[semconv.K8SPodNameKey] == "" {
+		values[semconv.K8SPodNameKey], _ = os.Hostname()
+	}
+	if values[semconv.K8SNamespaceNameKey] == "" {
+		values[semconv.K8SNamespaceNameKey] = readFile(serviceAccountNamespaceFile)
+	}
+	for _, a := range kubernetesAttributes {
+		if v := values[a.key]; v != "" {
+			kvs = append(kvs, a.key.String(v))
+		}
+	}
+	return resource.NewSchemaless(kvs...), nil
+}
+
+func lookupEnv(names []string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// readFile returns the trimmed content of the file, empty if the file can't be read
+func readFile(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
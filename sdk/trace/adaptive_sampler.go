@@ -0,0 +1,207 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"trpc-system/go-opentelemetry/pkg/metrics"
+)
+
+const (
+	defaultAdaptiveInterval  = 10 * time.Second
+	defaultAdaptiveSmoothing = 0.5
+	// maxAdaptiveMethods the methods beyond it share the overflow key
+	maxAdaptiveMethods  = 1000
+	adaptiveOverflowKey = "__overflow__"
+)
+
+var (
+	adaptiveFractionGauge = metrics.AdaptiveSamplingGauge.WithLabelValues("fraction")
+	adaptiveTargetGauge   = metrics.AdaptiveSamplingGauge.WithLabelValues("target_traces_per_second")
+)
+
+// AdaptiveConfig adaptive sampling config, the fractions are adjusted every Interval to hit the export budget.
+// If both targets are set, the lower one in traces per second takes effect.
+type AdaptiveConfig struct {
+	// TargetSpansPerSecond target exported spans per second, 0 means not limited by spans
+	TargetSpansPerSecond float64
+	// TargetBytesPerSecond target exported bytes per second measured by calcSpanSize, 0 means not limited by bytes
+	TargetBytesPerSecond float64
+	// Interval adjust interval, default 10s
+	Interval time.Duration
+	// Smoothing weight of the latest interval in (0, 1], lower is smoother, default 0.5
+	Smoothing float64
+}
+
+// adaptiveSampler adjusts the fraction of each callee method, the budget of traces per second is shared
+// by max-min fairness so that the low traffic methods are fully sampled before the hot ones get more.
+type adaptiveSampler struct {
+	cfg AdaptiveConfig
+
+	mu      sync.Mutex
+	last    time.Time
+	methods map[string]*adaptiveMethod
+	// traces, spans and bytes sampled and exported in the current interval
+	traces, spans, bytes float64
+	// spansPerTrace and bytesPerTrace smoothed, 0 until measured
+	spansPerTrace, bytesPerTrace float64
+	initialFraction              float64
+}
+
+type adaptiveMethod struct {
+	requests float64 // root requests in the current interval
+	rate     float64 // smoothed root requests per second
+	fraction float64
+}
+
+func newAdaptiveSampler(cfg *AdaptiveConfig, fraction float64) *adaptiveSampler {
+	if cfg == nil || (cfg.TargetSpansPerSecond <= 0 && cfg.TargetBytesPerSecond <= 0) {
+		return nil
+	}
+	c := *cfg
+	if c.Interval <= 0 {
+		c.Interval = defaultAdaptiveInterval
+	}
+	if c.Smoothing <= 0 || c.Smoothing > 1 {
+		c.Smoothing = defaultAdaptiveSmoothing
+	}
+	return &adaptiveSampler{
+		cfg:             c,
+		methods:         make(map[string]*adaptiveMethod),
+		initialFraction: math.Max(0, math.Min(fraction, 1)),
+	}
+}
+
+// fraction records a root request of the method and returns its current fraction,
+// it must be called for the local roots only, the local children follow their roots
+func (a *adaptiveSampler) fraction(key string, now time.Time) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.adjust(now)
+	m, ok := a.methods[key]
+	if !ok {
+		if len(a.methods) >= maxAdaptiveMethods {
+			key = adaptiveOverflowKey
+		}
+		if m, ok = a.methods[key]; !ok {
+			m = &adaptiveMethod{fraction: a.initialFraction}
+			a.methods[key] = m
+		}
+	}
+	m.requests++
+	return m.fraction
+}
+
+// sampled records a sampled root trace
+func (a *adaptiveSampler) sampled() {
+	a.mu.Lock()
+	a.traces++
+	a.mu.Unlock()
+}
+
+// observe records an exported span
+func (a *adaptiveSampler) observe(size int) {
+	a.mu.Lock()
+	a.spans++
+	a.bytes += float64(size)
+	a.mu.Unlock()
+}
+
+// adjust recalculates the fractions once the interval has elapsed
+func (a *adaptiveSampler) adjust(now time.Time) {
+	if a.last.IsZero() {
+		a.last = now
+		return
+	}
+	elapsed := now.Sub(a.last)
+	if elapsed < a.cfg.Interval {
+		return
+	}
+	a.last = now
+	seconds := elapsed.Seconds()
+	if a.traces > 0 {
+		if a.spansPerTrace == 0 {
+			a.spansPerTrace, a.bytesPerTrace = a.spans/a.traces, a.bytes/a.traces
+		} else {
+			a.spansPerTrace = a.smooth(a.spansPerTrace, a.spans/a.traces)
+			a.bytesPerTrace = a.smooth(a.bytesPerTrace, a.bytes/a.traces)
+		}
+	}
+	a.traces, a.spans, a.bytes = 0, 0, 0
+
+	methods := make([]*adaptiveMethod, 0, len(a.methods))
+	for k, m := range a.methods {
+		rate := m.requests / seconds
+		m.requests = 0
+		if m.rate == 0 {
+			m.rate = rate
+		} else {
+			m.rate = a.smooth(m.rate, rate)
+		}
+		if rate == 0 && m.rate < 1/seconds {
+			// idle for a while, starts over from the initial fraction
+			delete(a.methods, k)
+			continue
+		}
+		methods = append(methods, m)
+	}
+	target := a.targetTraces()
+	if math.IsInf(target, 1) {
+		// only limited by bytes and not measured yet, the fractions are kept
+		return
+	}
+	adaptiveTargetGauge.Set(target)
+
+	// max-min fairness: the methods with lower rate than the equal share are fully sampled,
+	// the rest is shared equally by the others.
+	sort.Slice(methods, func(i, j int) bool { return methods[i].rate < methods[j].rate })
+	var total, allocated float64
+	remaining := target
+	for i, m := range methods {
+		share := remaining / float64(len(methods)-i)
+		alloc := math.Min(m.rate, share)
+		remaining -= alloc
+		total += m.rate
+		allocated += alloc
+		fraction := 1.0
+		if m.rate > 0 {
+			fraction = alloc / m.rate
+		}
+		m.fraction = a.smooth(m.fraction, fraction)
+	}
+	if total > 0 {
+		adaptiveFractionGauge.Set(allocated / total)
+	}
+}
+
+// targetTraces the target root traces per second
+func (a *adaptiveSampler) targetTraces() float64 {
+	target := math.Inf(1)
+	if a.cfg.TargetSpansPerSecond > 0 {
+		// a trace has at least one span before measured
+		target = a.cfg.TargetSpansPerSecond / math.Max(a.spansPerTrace, 1)
+	}
+	if a.cfg.TargetBytesPerSecond > 0 && a.bytesPerTrace > 0 {
+		target = math.Min(target, a.cfg.TargetBytesPerSecond/a.bytesPerTrace)
+	}
+	return target
+}
+
+func (a *adaptiveSampler) smooth(old, latest float64) float64 {
+	return old + a.cfg.Smoothing*(latest-old)
+}
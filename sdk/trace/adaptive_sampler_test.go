@@ -0,0 +1,127 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestAdaptiveSampler(t *testing.T) {
+	assert.Nil(t, newAdaptiveSampler(nil, 0.1))
+	assert.Nil(t, newAdaptiveSampler(&AdaptiveConfig{}, 0.1))
+
+	a := newAdaptiveSampler(&AdaptiveConfig{TargetSpansPerSecond: 100, Interval: time.Second, Smoothing: 1}, 0.1)
+	require.NotNil(t, a)
+	now := time.Unix(1000, 0)
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, 0.1, a.fraction("hot", now))
+	}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 0.1, a.fraction("cold", now))
+	}
+	for i := 0; i < 50; i++ {
+		a.sampled()
+	}
+	for i := 0; i < 100; i++ {
+		a.observe(10)
+	}
+
+	// 2 spans per trace, 50 traces per second are shared by cold and hot
+	now = now.Add(time.Second)
+	assert.Equal(t, 1.0, a.fraction("cold", now), "cold method is fully sampled")
+	assert.InDelta(t, 0.04, a.fraction("hot", now), 1e-9, "hot method takes the rest")
+	assert.Equal(t, 2.0, a.spansPerTrace)
+	assert.Equal(t, 20.0, a.bytesPerTrace)
+}
+
+func TestAdaptiveSampler_BytesBudget(t *testing.T) {
+	a := newAdaptiveSampler(&AdaptiveConfig{
+		TargetSpansPerSecond: 1000,
+		TargetBytesPerSecond: 100,
+		Interval:             time.Second,
+		Smoothing:            0.5,
+	}, 1)
+	now := time.Unix(1000, 0)
+	for i := 0; i < 100; i++ {
+		a.fraction("method", now)
+		a.sampled()
+		a.observe(10)
+	}
+	now = now.Add(time.Second)
+	// the bytes budget allows 10 traces per second, smoothed from the initial fraction 1
+	assert.InDelta(t, 0.55, a.fraction("method", now), 1e-9)
+
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Second)
+		a.fraction("other", now)
+	}
+	_, ok := a.methods["method"]
+	assert.False(t, ok, "idle method is removed once its smoothed rate decays")
+}
+
+func TestSampler_Adaptive(t *testing.T) {
+	ws := NewSampler("", SamplerConfig{
+		Fraction: 0.5,
+		Adaptive: &AdaptiveConfig{TargetSpansPerSecond: 100},
+	}).(*Sampler)
+	require.True(t, ws.AdaptiveEnabled())
+	ws.ObserveExportedSpan(10)
+	assert.Equal(t, 1.0, ws.adaptive.spans)
+
+	got := ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background()})
+	assert.Equal(t, sdktrace.RecordAndSample, got.Decision)
+	assert.Equal(t, "0.5", got.Tracestate.Get(string(traceStateProbability)))
+	assert.Equal(t, 1.0, ws.adaptive.traces)
+
+	got = ws.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       [16]byte{0xff},
+	})
+	assert.Equal(t, sdktrace.Drop, got.Decision)
+	assert.False(t, NewSampler("", SamplerConfig{}).(*Sampler).AdaptiveEnabled())
+}
+
+func TestAdaptiveSampler_BytesNotMeasured(t *testing.T) {
+	a := newAdaptiveSampler(&AdaptiveConfig{TargetBytesPerSecond: 100, Interval: time.Second, Smoothing: 1}, 0.1)
+	now := time.Unix(1000, 0)
+	for i := 0; i < 100; i++ {
+		a.fraction("method", now)
+	}
+	now = now.Add(time.Second)
+	assert.Equal(t, 0.1, a.fraction("method", now), "the initial fraction is kept until the bytes are measured")
+}
+
+func TestSampler_AdaptiveRootOnly(t *testing.T) {
+	ws := NewSampler("", SamplerConfig{
+		Fraction: 0.5,
+		Adaptive: &AdaptiveConfig{TargetSpansPerSecond: 100},
+	}).(*Sampler)
+	parent := apitrace.ContextWithSpanContext(context.Background(), apitrace.NewSpanContext(
+		apitrace.SpanContextConfig{TraceID: [16]byte{1}, SpanID: [8]byte{1}}))
+	ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background()})
+	ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: parent})
+	ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: parent})
+	var requests float64
+	for _, m := range ws.adaptive.methods {
+		requests += m.requests
+	}
+	assert.Equal(t, 1.0, requests, "the local children are not counted")
+}
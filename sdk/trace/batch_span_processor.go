@@ -87,6 +87,14 @@ type BatchSpanProcessorOptions struct {
 	// Blocking option should be used carefully as it can severely affect the performance of an
 	// application.
 	BlockOnQueueFull bool
+
+	// ExportObserver is called with the size calculated by calcSpanSize for each span batched for export,
+	// it must not block.
+	ExportObserver func(size int)
+
+	// Destination is the name of the fan-out destination of the processor, whose exported, failed and
+	// dropped spans are counted by metrics.DestinationCounter as well if not empty.
+	Destination string
 }
 
 // batchSpanProcessor is a SpanProcessor that batches asynchronously-received
@@ -265,6 +273,20 @@ func WithMaxPacketSize(size int) BatchSpanProcessorOption {
 	}
 }
 
+// WithExportObserver set ExportObserver helper
+func WithExportObserver(observer func(size int)) BatchSpanProcessorOption {
+	return func(o *BatchSpanProcessorOptions) {
+		o.ExportObserver = observer
+	}
+}
+
+// WithDestination set Destination helper
+func WithDestination(name string) BatchSpanProcessorOption {
+	return func(o *BatchSpanProcessorOptions) {
+		o.Destination = name
+	}
+}
+
 // exportSpans is a subroutine of processing and draining the queue.
 func (bsp *batchSpanProcessor) exportSpans(ctx context.Context) error {
 	bsp.timer.Reset(bsp.o.BatchTimeout)
@@ -293,13 +315,22 @@ func (bsp *batchSpanProcessor) exportSpans(ctx context.Context) error {
 
 		if err != nil {
 			failedExportCounter.Add(float64(size))
+			bsp.countDestination("failed", size)
 			return err
 		}
 		succeededExportCounter.Add(float64(size))
+		bsp.countDestination("success", size)
 	}
 	return nil
 }
 
+// countDestination counts the spans of the fan-out destination by status
+func (bsp *batchSpanProcessor) countDestination(status string, n int) {
+	if bsp.o.Destination != "" {
+		metrics.DestinationCounter.WithLabelValues(bsp.o.Destination, status, "traces").Add(float64(n))
+	}
+}
+
 // processQueue removes spans from the `queue` channel until processor
 // is shut down. It calls the exporter in batches of up to MaxExportBatchSize
 // waiting up to BatchTimeout to form a batch.
@@ -324,9 +355,13 @@ func (bsp *batchSpanProcessor) processQueue() {
 			}
 			bsp.batchMutex.Lock()
 			bsp.batch = append(bsp.batch, sd)
-			bsp.batchedSize += calcSpanSize(sd)
+			size := calcSpanSize(sd)
+			bsp.batchedSize += size
 			shouldExport := bsp.shouldProcessInBatch()
 			bsp.batchMutex.Unlock()
+			if bsp.o.ExportObserver != nil {
+				bsp.o.ExportObserver(size)
+			}
 			if shouldExport {
 				if !bsp.timer.Stop() {
 					<-bsp.timer.C
@@ -413,6 +448,7 @@ func (bsp *batchSpanProcessor) enqueueBlockOnQueueFull(ctx context.Context, sd s
 	default:
 		atomic.AddUint32(&bsp.dropped, 1)
 		dropCounter.Inc()
+		bsp.countDestination("dropped", 1)
 	}
 	return false
 }
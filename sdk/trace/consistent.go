@@ -0,0 +1,128 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// consistent probability sampling of OTEP 235, the decision is made by comparing the 56 bits randomness
+// with the rejection threshold, so the services with different fractions sample consistently.
+const (
+	otTraceStateKey = "ot"
+	thresholdKey    = "th"
+	randomnessKey   = "rv"
+	// randomnessHexDigits 56 bits randomness and threshold in hex
+	randomnessHexDigits = 14
+	maxThreshold        = uint64(1) << 56
+)
+
+// otTraceState the ot entry of the tracestate, e.g. th:c;rv:0123456789abcd
+type otTraceState struct {
+	threshold     uint64
+	hasThreshold  bool
+	randomness    uint64
+	hasRandomness bool
+	// others the sub-keys of the other specifications, preserved as is
+	others []string
+}
+
+func parseOTTraceState(value string) otTraceState {
+	var ot otTraceState
+	if value == "" {
+		return ot
+	}
+	for _, kv := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(kv, ":")
+		switch k {
+		case thresholdKey:
+			if t, ok := parseThreshold(v); ok {
+				ot.threshold, ot.hasThreshold = t, true
+			}
+		case randomnessKey:
+			if len(v) != randomnessHexDigits {
+				continue
+			}
+			if r, err := strconv.ParseUint(v, 16, 64); err == nil {
+				ot.randomness, ot.hasRandomness = r, true
+			}
+		default:
+			ot.others = append(ot.others, kv)
+		}
+	}
+	return ot
+}
+
+// parseThreshold parses the hex threshold whose trailing zeros are removed
+func parseThreshold(v string) (uint64, bool) {
+	if v == "" || len(v) > randomnessHexDigits {
+		return 0, false
+	}
+	t, err := strconv.ParseUint(v, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	return t << (4 * (randomnessHexDigits - len(v))), true
+}
+
+func (ot otTraceState) String() string {
+	var kvs []string
+	if ot.hasThreshold {
+		th := strings.TrimRight(strconv.FormatUint(ot.threshold|maxThreshold, 16)[1:], "0")
+		if th == "" {
+			th = "0"
+		}
+		kvs = append(kvs, thresholdKey+":"+th)
+	}
+	if ot.hasRandomness {
+		kvs = append(kvs, randomnessKey+":"+strconv.FormatUint(ot.randomness|maxThreshold, 16)[1:])
+	}
+	return strings.Join(append(kvs, ot.others...), ";")
+}
+
+// randomnessOf returns the explicit randomness, or the least significant 56 bits of the trace ID
+func (ot otTraceState) randomnessOf(traceID trace.TraceID) uint64 {
+	if ot.hasRandomness {
+		return ot.randomness
+	}
+	return binary.BigEndian.Uint64(traceID[8:16]) & (maxThreshold - 1)
+}
+
+// getThreshold returns the rejection threshold of the fraction, false if never sampled
+func getThreshold(fraction float64) (uint64, bool) {
+	if fraction <= 0 || math.IsNaN(fraction) {
+		return 0, false
+	}
+	if fraction >= 1 {
+		return 0, true
+	}
+	return maxThreshold - uint64(math.Round(fraction*float64(maxThreshold))), true
+}
+
+// insert sets the ot entry into the tracestate, the entry is removed if empty
+func (ot otTraceState) insert(ts trace.TraceState) trace.TraceState {
+	value := ot.String()
+	if value == "" {
+		return ts.Delete(otTraceStateKey)
+	}
+	if inserted, err := ts.Insert(otTraceStateKey, value); err == nil {
+		return inserted
+	}
+	return ts
+}
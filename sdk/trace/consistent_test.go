@@ -0,0 +1,110 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOTTraceState(t *testing.T) {
+	ot := parseOTTraceState("th:c;rv:0123456789abcd;xx:1")
+	assert.True(t, ot.hasThreshold)
+	assert.Equal(t, uint64(0xc0000000000000), ot.threshold)
+	assert.True(t, ot.hasRandomness)
+	assert.Equal(t, uint64(0x0123456789abcd), ot.randomness)
+	assert.Equal(t, "th:c;rv:0123456789abcd;xx:1", ot.String())
+
+	ot = parseOTTraceState("th:zz;rv:123")
+	assert.False(t, ot.hasThreshold)
+	assert.False(t, ot.hasRandomness)
+	assert.Equal(t, "", ot.String())
+
+	for fraction, th := range map[float64]string{1: "0", 0.5: "8", 0.25: "c", 0.1: "e6666666666666"} {
+		threshold, ok := getThreshold(fraction)
+		require.True(t, ok)
+		assert.Equal(t, "th:"+th, otTraceState{threshold: threshold, hasThreshold: true}.String())
+	}
+	_, ok := getThreshold(0)
+	assert.False(t, ok)
+}
+
+func TestSampler_Consistent(t *testing.T) {
+	defer func(f GetCalleeMethodInfo) { DefaultGetCalleeMethodInfo = f }(DefaultGetCalleeMethodInfo)
+	DefaultGetCalleeMethodInfo = func(ctx context.Context) MethodInfo {
+		return MethodInfo{CalleeService: "service", CalleeMethod: "method"}
+	}
+	low := NewSampler("", SamplerConfig{Fraction: 0.1, Consistent: true}).(*Sampler)
+	high := NewSampler("", SamplerConfig{Fraction: 0.1, Consistent: true, SpecialFractions: map[string]SpecialFraction{
+		"service": {DefaultFraction: 0.1, Methods: map[string]MethodFraction{"method": {Fraction: 0.5}}},
+	}}).(*Sampler)
+
+	r := rand.New(rand.NewSource(1))
+	var sampledLow, sampledHigh int
+	for i := 0; i < 10000; i++ {
+		var traceID trace.TraceID
+		r.Read(traceID[:])
+		p := sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: traceID}
+		l, h := low.ShouldSample(p), high.ShouldSample(p)
+		if l.Decision == sdktrace.RecordAndSample {
+			sampledLow++
+			assert.Equal(t, sdktrace.RecordAndSample, h.Decision, "sampled by the higher fraction too")
+			assert.Equal(t, "th:e6666666666666", l.Tracestate.Get(otTraceStateKey))
+		}
+		if h.Decision == sdktrace.RecordAndSample {
+			sampledHigh++
+			assert.Equal(t, "th:8", h.Tracestate.Get(otTraceStateKey))
+		}
+	}
+	assert.InDelta(t, 1000, sampledLow, 100)
+	assert.InDelta(t, 5000, sampledHigh, 200)
+}
+
+func TestSampler_ConsistentRandomness(t *testing.T) {
+	ws := NewSampler("", SamplerConfig{Fraction: 0.5, Consistent: true}).(*Sampler)
+	newParams := func(rv string) sdktrace.SamplingParameters {
+		ts, err := trace.TraceState{}.Insert(otTraceStateKey, "rv:"+rv)
+		require.NoError(t, err)
+		ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceState: ts,
+		}))
+		// the randomness of the trace ID is ignored if rv is set
+		return sdktrace.SamplingParameters{ParentContext: ctx, TraceID: trace.TraceID{15: 0xff}}
+	}
+
+	got := ws.ShouldSample(newParams("80000000000000"))
+	assert.Equal(t, sdktrace.RecordAndSample, got.Decision)
+	assert.Equal(t, "th:8;rv:80000000000000", got.Tracestate.Get(otTraceStateKey))
+
+	got = ws.ShouldSample(newParams("7fffffffffffff"))
+	assert.Equal(t, sdktrace.Drop, got.Decision)
+	assert.Equal(t, "rv:7fffffffffffff", got.Tracestate.Get(otTraceStateKey), "rv is propagated")
+
+	sampled := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		TraceState: got.Tracestate,
+	}))
+	got = ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: sampled})
+	assert.Equal(t, "rv:7fffffffffffff", got.Tracestate.Get(otTraceStateKey), "parent tracestate is kept")
+}
@@ -16,6 +16,7 @@ package trace
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/codes"
@@ -37,8 +38,42 @@ type DeferredSampleConfig struct {
 	SampleSlowDuration time.Duration // Sampling slow operation
 }
 
+// DynamicDeferredSampleConfig deferred sampling configuration which can be changed at runtime,
+// e.g. by the remote config.
+type DynamicDeferredSampleConfig struct {
+	local DeferredSampleConfig
+	value atomic.Value // DeferredSampleConfig
+}
+
+// NewDynamicDeferredSampleConfig create a new dynamic deferred sampling configuration,
+// cfg is the local configuration restored when the remote config is unset.
+func NewDynamicDeferredSampleConfig(cfg DeferredSampleConfig) *DynamicDeferredSampleConfig {
+	c := &DynamicDeferredSampleConfig{local: cfg}
+	c.value.Store(cfg)
+	return c
+}
+
+// Load returns the current configuration.
+func (c *DynamicDeferredSampleConfig) Load() DeferredSampleConfig {
+	return c.value.Load().(DeferredSampleConfig)
+}
+
+// Store replaces the current configuration.
+func (c *DynamicDeferredSampleConfig) Store(cfg DeferredSampleConfig) {
+	c.value.Store(cfg)
+}
+
 // NewDeferredSampler crate a new deferred sampler
 func NewDeferredSampler(cfg DeferredSampleConfig) DeferredSampler {
+	return newDeferredSampler(func() DeferredSampleConfig { return cfg })
+}
+
+// NewDynamicDeferredSampler create a new deferred sampler following the changes of cfg
+func NewDynamicDeferredSampler(cfg *DynamicDeferredSampleConfig) DeferredSampler {
+	return newDeferredSampler(cfg.Load)
+}
+
+func newDeferredSampler(load func() DeferredSampleConfig) DeferredSampler {
 	sampledCounter := metrics.DeferredProcessCounter.WithLabelValues("sampled", "traces")
 	errorCounter := metrics.DeferredProcessCounter.WithLabelValues("deferred_error", "traces")
 	slowCounter := metrics.DeferredProcessCounter.WithLabelValues("deferred_slow", "traces")
@@ -49,6 +84,7 @@ func NewDeferredSampler(cfg DeferredSampleConfig) DeferredSampler {
 			sampledCounter.Inc()
 			return true
 		}
+		cfg := load()
 		if cfg.Enabled && cfg.SampleError && s.Status().Code != codes.Ok {
 			// error
 			errorCounter.Inc()
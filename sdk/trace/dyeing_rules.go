@@ -0,0 +1,101 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// GetDyeingMetadata returns the value of the request metadata key, e.g. the tRPC metadata
+type GetDyeingMetadata func(ctx context.Context, key string) (string, bool)
+
+// DefaultGetDyeingMetadata lets the dyeing rules match the request metadata, nil if not supported
+var DefaultGetDyeingMetadata GetDyeingMetadata = nil
+
+// dyeingRule dyeing rule of an attribute value
+type dyeingRule struct {
+	sampled  bool  // false means never sample
+	deadline int64 // unix timestamp in seconds when the rule expires, 0 means never
+}
+
+// dyeingRules key -> value -> rule
+type dyeingRules map[string]map[string]dyeingRule
+
+func newDyeingRules(kvs []*sampler.KeyValue) dyeingRules {
+	rules := make(dyeingRules)
+	for _, kv := range kvs {
+		rules.add(kv.GetKey(), kv.GetValue(), dyeingRule{sampled: kv.GetSampled(), deadline: kv.GetDeadline()})
+	}
+	return rules
+}
+
+// newSampledDyeingRules rules of the always sampled values returned by the V1 API
+func newSampledDyeingRules(attributes []*sampler.KeyValues) dyeingRules {
+	rules := make(dyeingRules)
+	for _, kv := range attributes {
+		for _, v := range kv.GetValues() {
+			rules.add(kv.GetKey(), v, dyeingRule{sampled: true})
+		}
+	}
+	return rules
+}
+
+func (r dyeingRules) add(key, value string, rule dyeingRule) {
+	values := r[key]
+	if values == nil {
+		values = make(map[string]dyeingRule)
+		r[key] = values
+	}
+	values[value] = rule
+}
+
+// match matches the span start attributes, the baggage members and the request metadata.
+// A never sample rule takes precedence over the always sample rules. Expired rules are ignored.
+func (r dyeingRules) match(p sdktrace.SamplingParameters, now int64) (sampled, matched bool) {
+	check := func(key, value string) bool {
+		rule, ok := r[key][value]
+		if !ok || (rule.deadline != 0 && rule.deadline <= now) {
+			return false
+		}
+		sampled, matched = rule.sampled, true
+		return !rule.sampled
+	}
+	for _, attr := range p.Attributes {
+		if check(string(attr.Key), attr.Value.Emit()) {
+			return false, true
+		}
+	}
+	if p.ParentContext == nil {
+		return sampled, matched
+	}
+	bag := baggage.FromContext(p.ParentContext)
+	for key := range r {
+		if m := bag.Member(key); m.Key() != "" && check(key, m.Value()) {
+			return false, true
+		}
+	}
+	if getMetadata := DefaultGetDyeingMetadata; getMetadata != nil {
+		for key := range r {
+			if v, ok := getMetadata(p.ParentContext, key); ok && check(key, v) {
+				return false, true
+			}
+		}
+	}
+	return sampled, matched
+}
@@ -19,16 +19,21 @@ import (
 	"encoding/binary"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"strconv"
 	"sync/atomic"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"go.opentelemetry.io/otel/attribute"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
 )
@@ -36,6 +41,8 @@ import (
 const (
 	dyeingHeader       = "x-tps-tenantid"
 	dyeingSamplerDebug = "DyeingSamplerDebug"
+	// minRetryInterval the first retry interval after the sampler service fails
+	minRetryInterval = 500 * time.Millisecond
 )
 
 var (
@@ -43,6 +50,9 @@ var (
 	ForceSamplerKey     = attribute.Key("trace.force.sample")
 	traceStateDyeing    = attribute.Key("trace_dyeing")
 	dyeingTraceState, _ = trace.TraceState{}.Insert(string(traceStateDyeing), "true")
+
+	// traceStateProbability the effective sampling probability when the sampling is rate limited or adaptive
+	traceStateProbability = attribute.Key("sampling_probability")
 )
 
 var _ sdktrace.Sampler = &Sampler{}
@@ -72,8 +82,19 @@ type SamplerConfig struct {
 	SamplerServiceAddr string
 	// SyncInterval sampler sync interval
 	SyncInterval time.Duration
+	// RateLimit max sampled traces per second, 0 means unlimited.
+	// SpecialFraction and MethodFraction can further limit the callee service and method.
+	RateLimit float64
+	// Consistent samples consistently across the services with different fractions by the
+	// threshold and randomness of the ot tracestate entry (OTEP 235) instead of the upper bits of the trace ID.
+	Consistent bool
+	// Adaptive adjusts the fraction of each callee method to hit the export budget instead of
+	// Fraction and SpecialFractions, which are the initial fraction, nil means disabled.
+	Adaptive *AdaptiveConfig
 	// traceIDUpperBound sampler traceIDUpperBound
 	traceIDUpperBound uint64
+	// rateLimiter created during initialization if RateLimit is set
+	rateLimiter *rateLimiter
 }
 
 // defaultSamplerOptions .
@@ -100,9 +121,16 @@ type Sampler struct {
 	description   string
 	tenantID      string
 	client        sampler.SamplerServiceClient
-	sampledKvs    atomic.Value // map[string]map[string]bool
-	debug         bool
-	opt           SamplerOptions
+	conn          *grpc.ClientConn
+	ctx           context.Context
+	cancel        context.CancelFunc
+	done          chan struct{} // closed when updateDyeingMetadataDaemon exits, nil if not started
+	rules         atomic.Value  // dyeingRules
+	// remoteConfig the sampler config overridden by the remote config, nil if not overridden.
+	remoteConfig atomic.Value // *SamplerConfig
+	adaptive     *adaptiveSampler
+	debug        bool
+	opt          SamplerOptions
 }
 
 // NewSampler .
@@ -116,14 +144,17 @@ func NewSampler(
 		tenantID:      tpsTenantID,
 		description: fmt.Sprintf("TpsSampler{fraction=%g,tenantID=%s}",
 			samplerConfig.Fraction, tpsTenantID),
-		sampledKvs: atomic.Value{},
-		opt:        defaultSamplerOptions,
+		rules:    atomic.Value{},
+		adaptive: newAdaptiveSampler(samplerConfig.Adaptive, samplerConfig.Fraction),
+		opt:      defaultSamplerOptions,
 	}
+	ws.ctx, ws.cancel = context.WithCancel(context.Background())
 	for _, v := range opts {
 		v(&ws.opt)
 	}
 	if ws.samplerConfig.SamplerServiceAddr != "" {
-		ws.sampledKvs.Store(make(map[string]map[string]bool))
+		ws.rules.Store(make(dyeingRules))
+		ws.done = make(chan struct{})
 		go ws.updateDyeingMetadataDaemon()
 	}
 
@@ -141,69 +172,148 @@ func (ws *Sampler) isDebugEnabled() bool {
 	return false
 }
 
+// Close stops syncing the dyeing metadata and closes the connection to the sampler service.
+func (ws *Sampler) Close() error {
+	if ws.cancel != nil {
+		ws.cancel()
+	}
+	if ws.done != nil {
+		<-ws.done
+	}
+	if ws.conn != nil {
+		return ws.conn.Close()
+	}
+	return nil
+}
+
+// updateDyeingMetadataDaemon receives the dyeing metadata pushed by WatchSampler,
+// and polls it every SyncInterval if the sampler service doesn't implement it.
 func (ws *Sampler) updateDyeingMetadataDaemon() {
+	defer close(ws.done)
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = minRetryInterval
+	b.MaxInterval = ws.samplerConfig.SyncInterval
+	b.MaxElapsedTime = 0
 	for {
-		ws.updateDyeingMetadata()
-		time.Sleep(ws.samplerConfig.SyncInterval)
+		var wait time.Duration
+		err := ws.watchDyeingMetadata(b)
+		if status.Code(err) == codes.Unimplemented {
+			// fall back to polling, watch is retried on the next update
+			if err = ws.updateDyeingMetadata(); err == nil {
+				b.Reset()
+				wait = ws.samplerConfig.SyncInterval
+			}
+		}
+		if err != nil {
+			wait = b.NextBackOff()
+			if ws.debug {
+				log.Printf("[opentelemetry][E] sync sampler err:%v, retry after %s", err, wait)
+			}
+		}
+		select {
+		case <-ws.ctx.Done():
+			return
+		case <-time.After(wait):
+		}
 	}
 }
 
-func (ws *Sampler) updateDyeingMetadata() {
+func (ws *Sampler) getClient() (sampler.SamplerServiceClient, error) {
 	if ws.client == nil {
 		cc, err := grpc.Dial(ws.samplerConfig.SamplerServiceAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		if err != nil {
-			return
+			return nil, err
 		}
+		ws.conn = cc
 		ws.client = sampler.NewSamplerServiceClient(cc)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-	ctx = metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
+	return ws.client, nil
+}
+
+func (ws *Sampler) outgoingContext(ctx context.Context) context.Context {
+	return metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
 		dyeingHeader: ws.tenantID,
 	}))
-	rsp, err := ws.client.GetSampler(ctx, &sampler.GetSamplerRequest{}, grpc.WaitForReady(true))
+}
+
+// watchDyeingMetadata receives the dyeing metadata until the stream is broken.
+func (ws *Sampler) watchDyeingMetadata(b backoff.BackOff) error {
+	client, err := ws.getClient()
 	if err != nil {
-		if ws.debug {
-			log.Printf("[opentelemetry][E] Get sampler err:%v", err)
+		return err
+	}
+	ctx, cancel := context.WithCancel(ws.outgoingContext(ws.ctx))
+	defer cancel()
+	stream, err := client.WatchSampler(ctx, &sampler.WatchSamplerRequest{}, grpc.WaitForReady(true))
+	if err != nil {
+		return err
+	}
+	for {
+		rsp, err := stream.Recv()
+		if err != nil {
+			return err
 		}
-		return
-	}
-	sampledKvs := make(map[string]map[string]bool)
-	for _, v := range rsp.GetAttributes() {
-		sampledKv := sampledKvs[v.Key]
-		if sampledKv == nil {
-			sampledKv = make(map[string]bool)
-			sampledKvs[v.Key] = sampledKv
+		b.Reset()
+		if len(rsp.GetRules()) == 0 && len(rsp.GetAttributes()) > 0 {
+			// the server doesn't support the rules
+			ws.storeRules(newSampledDyeingRules(rsp.GetAttributes()))
+			continue
 		}
-		for _, vv := range v.GetValues() {
-			sampledKv[vv] = true
+		ws.storeRules(newDyeingRules(rsp.GetRules()))
+	}
+}
+
+func (ws *Sampler) updateDyeingMetadata() error {
+	client, err := ws.getClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ws.outgoingContext(ws.ctx), time.Second*5)
+	defer cancel()
+	rsp, err := client.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{}, grpc.WaitForReady(true))
+	if status.Code(err) == codes.Unimplemented {
+		// fall back to the always sampled values
+		rspV1, err := client.GetSampler(ctx, &sampler.GetSamplerRequest{}, grpc.WaitForReady(true))
+		if err != nil {
+			return err
 		}
+		ws.storeRules(newSampledDyeingRules(rspV1.GetAttributes()))
+		return nil
+	}
+	if err != nil {
+		return err
 	}
+	ws.storeRules(newDyeingRules(rsp.GetAttributes()))
+	return nil
+}
+
+func (ws *Sampler) storeRules(rules dyeingRules) {
 	if ws.debug {
-		log.Printf("[opentelemetry][I] sampledKvs:%+v", sampledKvs)
+		log.Printf("[opentelemetry][I] dyeing rules:%+v", rules)
 	}
-	ws.sampledKvs.Store(sampledKvs)
+	ws.rules.Store(rules)
 }
 
 // ShouldSample sampler ShouldSample implementation
 func (ws *Sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
 	if psc := trace.SpanContextFromContext(p.ParentContext); psc.IsSampled() {
-		return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample}
+		return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: psc.TraceState()}
 	}
 	if ws.samplerConfig.SamplerServiceAddr != "" {
-		sampledKvs, ok := ws.sampledKvs.Load().(map[string]map[string]bool)
 		for _, attr := range p.Attributes {
-			key := string(attr.Key)
 			if attr.Key == ForceSamplerKey && attr.Value.Emit() != "" {
 				return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample,
 					Tracestate: dyeingTraceState}
 			}
-
-			if ok {
-				if values, ok2 := sampledKvs[key]; ok2 && values[attr.Value.Emit()] {
+		}
+		if rules, ok := ws.rules.Load().(dyeingRules); ok && len(rules) > 0 {
+			if sampled, matched := rules.match(p, time.Now().Unix()); matched {
+				if sampled {
 					return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample,
 						Tracestate: dyeingTraceState}
 				}
+				// never sampled regardless of the fraction
+				return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision}
 			}
 		}
 	}
@@ -217,33 +327,144 @@ func (ws *Sampler) Description() string {
 }
 
 func (ws *Sampler) shouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
-	x := binary.BigEndian.Uint64(p.TraceID[0:8]) >> 1
+	if psc := trace.SpanContextFromContext(p.ParentContext); psc.IsValid() && !psc.IsRemote() {
+		// the local child of an unsampled span follows the decision of its root
+		return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision, Tracestate: psc.TraceState()}
+	}
+	now := time.Now()
+	config := ws.fractionConfig()
+	var methodInfo MethodInfo
+	if DefaultGetCalleeMethodInfo != nil && (config.SpecialFractions != nil || ws.adaptive != nil) {
+		methodInfo = DefaultGetCalleeMethodInfo(p.ParentContext)
+	}
+	rule := getSamplingRule(methodInfo, config)
+	if ws.adaptive != nil {
+		rule.fraction = ws.adaptive.fraction(methodInfo.CalleeService+"/"+methodInfo.CalleeMethod, now)
+		rule.traceIDUpperBound = getTraceIDUpperBound(rule.fraction)
+	}
+	if config.Consistent {
+		return ws.shouldSampleConsistently(p, rule, now)
+	}
 
-	traceIDUpperBound := getSamplerTraceIDUpperBound(p.ParentContext, ws.samplerConfig)
-	if x < traceIDUpperBound {
+	x := binary.BigEndian.Uint64(p.TraceID[0:8]) >> 1
+	if x >= rule.traceIDUpperBound {
+		return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision}
+	}
+	if len(rule.rateLimiters) == 0 && ws.adaptive == nil {
 		return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample}
 	}
-	return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision}
+	admitted, ok := ws.takeRateLimiters(rule, now)
+	if !ok {
+		return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision}
+	}
+	ts := trace.SpanContextFromContext(p.ParentContext).TraceState()
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample,
+		Tracestate: insertProbability(ts, math.Min(rule.fraction, 1)*admitted)}
 }
 
-func getSamplerTraceIDUpperBound(ctx context.Context, config SamplerConfig) uint64 {
-	if DefaultGetCalleeMethodInfo == nil || config.SpecialFractions == nil {
-		return config.traceIDUpperBound
+// shouldSampleConsistently samples if the randomness of the ot tracestate entry or the trace ID reaches
+// the threshold of the fraction, the threshold is recorded as th so the backends can compute the adjusted count.
+func (ws *Sampler) shouldSampleConsistently(p sdktrace.SamplingParameters, rule samplingRule,
+	now time.Time) sdktrace.SamplingResult {
+	ts := trace.SpanContextFromContext(p.ParentContext).TraceState()
+	ot := parseOTTraceState(ts.Get(otTraceStateKey))
+	threshold, ok := getThreshold(rule.fraction)
+	if !ok || ot.randomnessOf(p.TraceID) < threshold {
+		return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision, Tracestate: ts}
 	}
-
-	methodInfo := DefaultGetCalleeMethodInfo(ctx)
-	serviceFraction, ok := config.SpecialFractions[methodInfo.CalleeService]
+	admitted, ok := ws.takeRateLimiters(rule, now)
 	if !ok {
-		return config.traceIDUpperBound
+		return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision, Tracestate: ts}
+	}
+	if admitted < 1 {
+		// the rate limited decision is not consistent, the threshold is erased
+		ot.hasThreshold = false
+		ts = insertProbability(ts, math.Min(rule.fraction, 1)*admitted)
+	} else {
+		ot.threshold, ot.hasThreshold = threshold, true
 	}
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: ot.insert(ts)}
+}
 
-	if serviceFraction.Methods == nil {
-		return serviceFraction.defaultTraceIDUpperBound
+// takeRateLimiters returns the probability of being admitted by all rate limiters, false if denied by any,
+// in which case the tokens taken from the other limiters are given back
+func (ws *Sampler) takeRateLimiters(rule samplingRule, now time.Time) (float64, bool) {
+	probability := 1.0
+	for i, l := range rule.rateLimiters {
+		ok, admitted := l.take(now)
+		if !ok {
+			for _, taken := range rule.rateLimiters[:i] {
+				taken.giveBack()
+			}
+			return 0, false
+		}
+		probability *= admitted
 	}
+	if ws.adaptive != nil {
+		ws.adaptive.sampled()
+	}
+	return probability, true
+}
 
-	methodFraction, ok := serviceFraction.Methods[methodInfo.CalleeMethod]
-	if !ok {
-		return serviceFraction.defaultTraceIDUpperBound
+func insertProbability(ts trace.TraceState, probability float64) trace.TraceState {
+	if probability >= 1 {
+		return ts
+	}
+	if inserted, err := ts.Insert(string(traceStateProbability),
+		strconv.FormatFloat(probability, 'g', 6, 64)); err == nil {
+		return inserted
+	}
+	return ts
+}
+
+// ObserveExportedSpan feeds the size of an exported span to the adaptive sampling,
+// it should be set as the ExportObserver of the batch span processor.
+func (ws *Sampler) ObserveExportedSpan(size int) {
+	if ws.adaptive != nil {
+		ws.adaptive.observe(size)
+	}
+}
+
+// AdaptiveEnabled returns whether the fractions are adjusted to hit the export budget
+func (ws *Sampler) AdaptiveEnabled() bool {
+	return ws.adaptive != nil
+}
+
+// fractionConfig returns the config of sampling fractions, the remote config takes precedence.
+func (ws *Sampler) fractionConfig() SamplerConfig {
+	if cfg, _ := ws.remoteConfig.Load().(*SamplerConfig); cfg != nil {
+		return *cfg
+	}
+	return ws.samplerConfig
+}
+
+// samplingRule the fraction and the rate limits applied to the callee method
+type samplingRule struct {
+	fraction          float64
+	traceIDUpperBound uint64
+	// rateLimiters from the method to the global one, a trace is sampled only if all of them admit it
+	rateLimiters []*rateLimiter
+}
+
+func getSamplingRule(methodInfo MethodInfo, config SamplerConfig) samplingRule {
+	rule := samplingRule{fraction: config.Fraction, traceIDUpperBound: config.traceIDUpperBound}
+	if config.SpecialFractions != nil {
+		if serviceFraction, ok := config.SpecialFractions[methodInfo.CalleeService]; ok {
+			rule.fraction, rule.traceIDUpperBound = serviceFraction.DefaultFraction, serviceFraction.defaultTraceIDUpperBound
+			if methodFraction, ok := serviceFraction.Methods[methodInfo.CalleeMethod]; ok {
+				rule.fraction, rule.traceIDUpperBound = methodFraction.Fraction, methodFraction.traceIDUpperBound
+				rule.rateLimiters = appendRateLimiter(rule.rateLimiters, methodFraction.rateLimiter)
+			}
+			rule.rateLimiters = appendRateLimiter(rule.rateLimiters, serviceFraction.rateLimiter)
+		}
+	}
+	rule.rateLimiters = appendRateLimiter(rule.rateLimiters, config.rateLimiter)
+	return rule
+}
+
+func appendRateLimiter(limiters []*rateLimiter, l *rateLimiter) []*rateLimiter {
+	if l == nil {
+		return limiters
 	}
-	return methodFraction.traceIDUpperBound
+	return append(limiters, l)
 }
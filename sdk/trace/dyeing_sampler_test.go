@@ -18,10 +18,19 @@ import (
 	"reflect"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+	"trpc-system/go-opentelemetry/sdk/remote/remotetest"
 )
 
 func TestSampler_shouldSample(t *testing.T) {
@@ -30,7 +39,7 @@ func TestSampler_shouldSample(t *testing.T) {
 		description   string
 		tenantID      string
 		client        sampler.SamplerServiceClient
-		sampledKvs    atomic.Value
+		rules         atomic.Value
 		debug         bool
 		opt           SamplerOptions
 	}
@@ -105,7 +114,7 @@ func TestSampler_shouldSample(t *testing.T) {
 				description:   tt.fields.description,
 				tenantID:      tt.fields.tenantID,
 				client:        tt.fields.client,
-				sampledKvs:    tt.fields.sampledKvs,
+				rules:         tt.fields.rules,
 				debug:         tt.fields.debug,
 				opt:           tt.fields.opt,
 			}
@@ -115,3 +124,116 @@ func TestSampler_shouldSample(t *testing.T) {
 		})
 	}
 }
+
+func newTestSamplerClient(t *testing.T, addr string) (context.Context, sampler.SamplerServiceClient) {
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(dyeingHeader, "tenant"))
+	return ctx, sampler.NewSamplerServiceClient(cc)
+}
+
+func testDyeingSampler(t *testing.T, opts ...remotetest.Option) {
+	s := remotetest.NewServer(opts...)
+	addr, err := s.Start()
+	require.NoError(t, err)
+	defer s.Stop()
+
+	ws := NewSampler("tenant", SamplerConfig{
+		SamplerServiceAddr: addr,
+		SyncInterval:       100 * time.Millisecond,
+	}).(*Sampler)
+	defer ws.Close()
+	p := trace.SamplingParameters{
+		ParentContext: context.Background(),
+		Attributes:    []attribute.KeyValue{attribute.String("uid", "42")},
+	}
+	assert.Equal(t, trace.Drop, ws.ShouldSample(p).Decision)
+
+	ctx, client := newTestSamplerClient(t, addr)
+	_, err = client.SetSampler(ctx, &sampler.SetSamplerRequest{
+		Attributes: []*sampler.KeyValues{{Key: "uid", Values: []string{"42"}}},
+	})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return ws.ShouldSample(p).Decision == trace.RecordAndSample
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = client.DelSampler(ctx, &sampler.DelSamplerRequest{Key: "uid", Value: "42"})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return ws.ShouldSample(p).Decision == trace.Drop
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSampler_WatchDyeingMetadata(t *testing.T) {
+	testDyeingSampler(t)
+}
+
+func TestSampler_PollDyeingMetadata(t *testing.T) {
+	testDyeingSampler(t, remotetest.WithWatchDisabled())
+}
+
+func TestSampler_DyeingRules(t *testing.T) {
+	s := remotetest.NewServer()
+	addr, err := s.Start()
+	require.NoError(t, err)
+	defer s.Stop()
+
+	ws := NewSampler("tenant", SamplerConfig{
+		Fraction:           1,
+		SamplerServiceAddr: addr,
+	}).(*Sampler)
+	defer ws.Close()
+	ctx, client := newTestSamplerClient(t, addr)
+	_, err = client.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{
+		{Key: "uid", Value: "blocked", Sampled: false, Comment: "never sample"},
+		{Key: "uid", Value: "expired", Sampled: true, Deadline: time.Now().Add(-time.Second).Unix()},
+		{Key: "bag", Value: "1", Sampled: true},
+		{Key: "md", Value: "1", Sampled: true},
+	}})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		rules, _ := ws.rules.Load().(dyeingRules)
+		return len(rules) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	ws.samplerConfig.traceIDUpperBound = 0 // the fraction never samples
+	params := func(ctx context.Context, attrs ...attribute.KeyValue) trace.SamplingParameters {
+		return trace.SamplingParameters{ParentContext: ctx, Attributes: attrs}
+	}
+	assert.Equal(t, trace.Drop, ws.ShouldSample(params(context.Background(),
+		attribute.String("uid", "expired"))).Decision)
+
+	member, err := baggage.NewMember("bag", "1")
+	require.NoError(t, err)
+	bag, err := baggage.New(member)
+	require.NoError(t, err)
+	assert.Equal(t, trace.RecordAndSample,
+		ws.ShouldSample(params(baggage.ContextWithBaggage(context.Background(), bag))).Decision)
+
+	DefaultGetDyeingMetadata = func(ctx context.Context, key string) (string, bool) {
+		return "1", key == "md"
+	}
+	defer func() { DefaultGetDyeingMetadata = nil }()
+	assert.Equal(t, trace.RecordAndSample, ws.ShouldSample(params(context.Background())).Decision)
+
+	// the never sample rule overrides the fraction and the always sample rules
+	ws.samplerConfig.traceIDUpperBound = getTraceIDUpperBound(1)
+	assert.Equal(t, trace.Drop, ws.ShouldSample(params(context.Background(),
+		attribute.String("uid", "blocked"))).Decision)
+}
+
+func TestDyeingRules_ExpireLocally(t *testing.T) {
+	now := time.Now().Unix()
+	rules := newDyeingRules([]*sampler.KeyValue{{Key: "uid", Value: "1", Sampled: true, Deadline: now + 10}})
+	p := trace.SamplingParameters{
+		ParentContext: context.Background(),
+		Attributes:    []attribute.KeyValue{attribute.String("uid", "1")},
+	}
+	sampled, matched := rules.match(p, now)
+	assert.True(t, sampled)
+	assert.True(t, matched)
+	_, matched = rules.match(p, now+10)
+	assert.False(t, matched)
+}
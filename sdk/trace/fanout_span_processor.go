@@ -0,0 +1,125 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"trpc-system/go-opentelemetry/pkg/metrics"
+)
+
+var _ sdktrace.SpanProcessor = (*FanOutSpanProcessor)(nil)
+
+// SpanFilter reports whether the ended span is exported to the destination
+type SpanFilter func(sdktrace.ReadOnlySpan) bool
+
+// ErrorSpanFilter returns the filter of the spans of the error status
+func ErrorSpanFilter() SpanFilter {
+	return func(s sdktrace.ReadOnlySpan) bool {
+		return s.Status().Code == codes.Error
+	}
+}
+
+// AttributeSpanFilter returns the filter of the spans with all the attributes,
+// the values are compared by their string form, e.g. the int 200 matches "200"
+func AttributeSpanFilter(kvs ...attribute.KeyValue) SpanFilter {
+	return func(s sdktrace.ReadOnlySpan) bool {
+		attrs := attribute.NewSet(s.Attributes()...)
+		for _, kv := range kvs {
+			v, ok := attrs.Value(kv.Key)
+			if !ok || v.Emit() != kv.Value.Emit() {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AllSpanFilters returns the filter of the spans passing all the filters, nil filters are skipped
+func AllSpanFilters(filters ...SpanFilter) SpanFilter {
+	return func(s sdktrace.ReadOnlySpan) bool {
+		for _, f := range filters {
+			if f != nil && !f(s) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Destination a named destination of the fan-out span processor
+type Destination struct {
+	// Name the destination label of metrics.DestinationCounter
+	Name string
+	// Processor batches and exports the spans of the destination, usually a BatchSpanProcessor of its own
+	// exporter created with WithDestination(Name), which counts the exported, failed and dropped spans
+	Processor sdktrace.SpanProcessor
+	// Filter the spans exported to the destination, all if nil
+	Filter SpanFilter
+}
+
+// FanOutSpanProcessor sends the ended spans to multiple destinations, each with its own processor and filter
+type FanOutSpanProcessor struct {
+	destinations []Destination
+}
+
+// NewFanOutSpanProcessor create a new fan-out span processor of the destinations
+func NewFanOutSpanProcessor(destinations ...Destination) *FanOutSpanProcessor {
+	return &FanOutSpanProcessor{destinations: destinations}
+}
+
+// OnStart is called when a span is started. It is called synchronously
+// and should not block.
+func (p *FanOutSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	for _, d := range p.destinations {
+		d.Processor.OnStart(parent, s)
+	}
+}
+
+// OnEnd sends the span to the destinations whose filters it passes, the others count it as filtered.
+func (p *FanOutSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	for _, d := range p.destinations {
+		if d.Filter != nil && !d.Filter(s) {
+			metrics.DestinationCounter.WithLabelValues(d.Name, "filtered", "traces").Inc()
+			continue
+		}
+		d.Processor.OnEnd(s)
+	}
+}
+
+// Shutdown shuts down the processors of all destinations, the first error is returned.
+func (p *FanOutSpanProcessor) Shutdown(ctx context.Context) error {
+	var err error
+	for _, d := range p.destinations {
+		if e := d.Processor.Shutdown(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// ForceFlush flushes the processors of all destinations, the first error is returned.
+func (p *FanOutSpanProcessor) ForceFlush(ctx context.Context) error {
+	var err error
+	for _, d := range p.destinations {
+		if e := d.Processor.ForceFlush(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
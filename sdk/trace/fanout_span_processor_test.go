@@ -0,0 +1,94 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"trpc-system/go-opentelemetry/pkg/metrics"
+)
+
+func TestFanOutSpanProcessor(t *testing.T) {
+	all, errs, tagged := &recordProcessor{}, &recordProcessor{}, &recordProcessor{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewFanOutSpanProcessor(
+		Destination{Name: "all", Processor: all},
+		Destination{Name: "errors", Processor: errs, Filter: ErrorSpanFilter()},
+		Destination{Name: "tagged", Processor: tagged, Filter: AllSpanFilters(nil,
+			AttributeSpanFilter(attribute.String("env", "prod"), attribute.String("code", "500")))},
+	)))
+	tracer := tp.Tracer("test")
+	filtered := metrics.DestinationCounter.WithLabelValues("errors", "filtered", "traces")
+	before := testutil.ToFloat64(filtered)
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	ok.SetAttributes(attribute.String("env", "prod"))
+	ok.End()
+	_, failed := tracer.Start(context.Background(), "failed")
+	failed.SetAttributes(attribute.String("env", "prod"), attribute.Int("code", 500))
+	failed.SetStatus(codes.Error, "failed")
+	failed.End()
+
+	assert.Equal(t, []string{"ok", "failed"}, all.names())
+	assert.Equal(t, []string{"failed"}, errs.names())
+	assert.Equal(t, []string{"failed"}, tagged.names())
+	assert.Equal(t, float64(1), testutil.ToFloat64(filtered)-before)
+	assert.NoError(t, tp.Shutdown(context.Background()))
+}
+
+// failingExporter fails the first export
+type failingExporter struct {
+	tracetest.InMemoryExporter
+	failed bool
+}
+
+func (e *failingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if !e.failed {
+		e.failed = true
+		return errors.New("unavailable")
+	}
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+func TestBatchSpanProcessor_Destination(t *testing.T) {
+	exp := &failingExporter{}
+	bsp := NewBatchSpanProcessor(exp, WithDestination("backup"))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(bsp))
+	success := metrics.DestinationCounter.WithLabelValues("backup", "success", "traces")
+	failed := metrics.DestinationCounter.WithLabelValues("backup", "failed", "traces")
+	successBefore, failedBefore := testutil.ToFloat64(success), testutil.ToFloat64(failed)
+
+	ctx := context.Background()
+	_, span := tp.Tracer("test").Start(ctx, "span")
+	span.End()
+	assert.Error(t, tp.ForceFlush(ctx))
+	for i := 0; i < 2; i++ {
+		_, span = tp.Tracer("test").Start(ctx, "span")
+		span.End()
+	}
+	require.NoError(t, tp.ForceFlush(ctx))
+	assert.Len(t, exp.GetSpans(), 2)
+	assert.Equal(t, float64(2), testutil.ToFloat64(success)-successBefore)
+	assert.Equal(t, float64(1), testutil.ToFloat64(failed)-failedBefore)
+	assert.NoError(t, tp.Shutdown(ctx))
+}
@@ -19,18 +19,26 @@ import "time"
 type SpecialFraction struct {
 	DefaultFraction float64
 	Methods         map[string]MethodFraction
+	// RateLimit max sampled traces per second of the service, 0 means unlimited
+	RateLimit float64
 	// defaultTraceIDUpperBound The upper limit of traceID corresponding to the default sampling rate,
 	// calculated during initialization and not exposed to the outside world.
 	defaultTraceIDUpperBound uint64
+	// rateLimiter created during initialization if RateLimit is set
+	rateLimiter *rateLimiter
 }
 
 // MethodFraction method special fraction
 type MethodFraction struct {
 	// Fraction Specified sampling rate
 	Fraction float64
+	// RateLimit max sampled traces per second of the method, 0 means unlimited
+	RateLimit float64
 	// traceIDUpperBound The upper limit of traceID corresponding to the sampling rate is calculated
 	// during initialization and is not exposed to the outside world.
 	traceIDUpperBound uint64
+	// rateLimiter created during initialization if RateLimit is set
+	rateLimiter *rateLimiter
 }
 
 func getSamplerConfig(config SamplerConfig) SamplerConfig {
@@ -39,7 +47,11 @@ func getSamplerConfig(config SamplerConfig) SamplerConfig {
 		SpecialFractions:   getSpecialFraction(config.SpecialFractions),
 		SamplerServiceAddr: config.SamplerServiceAddr,
 		SyncInterval:       getSamplerSyncInterval(config.SyncInterval),
+		RateLimit:          config.RateLimit,
+		Adaptive:           config.Adaptive,
+		Consistent:         config.Consistent,
 		traceIDUpperBound:  getTraceIDUpperBound(config.Fraction),
+		rateLimiter:        newRateLimiter(config.RateLimit),
 	}
 }
 
@@ -56,7 +68,9 @@ func getSpecialFraction(fractions map[string]SpecialFraction) map[string]Special
 		result[k] = SpecialFraction{
 			DefaultFraction:          v.DefaultFraction,
 			Methods:                  getMethodsSpecialFraction(v.Methods),
+			RateLimit:                v.RateLimit,
 			defaultTraceIDUpperBound: getTraceIDUpperBound(v.DefaultFraction),
+			rateLimiter:              newRateLimiter(v.RateLimit),
 		}
 	}
 	return result
@@ -67,7 +81,9 @@ func getMethodsSpecialFraction(methodsFraction map[string]MethodFraction) map[st
 	for k, v := range methodsFraction {
 		result[k] = MethodFraction{
 			Fraction:          v.Fraction,
+			RateLimit:         v.RateLimit,
 			traceIDUpperBound: getTraceIDUpperBound(v.Fraction),
+			rateLimiter:       newRateLimiter(v.RateLimit),
 		}
 	}
 	return result
@@ -0,0 +1,88 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter token bucket which caps the sampled traces per second,
+// it also estimates the probability of a trace being admitted.
+type rateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	// window start of the current one second window, attempts and admits are counted in the
+	// previous and the current window to estimate the admitted probability.
+	window   time.Time
+	attempts [2]float64
+	admits   [2]float64
+}
+
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	burst := math.Max(rate, 1)
+	return &rateLimiter{rate: rate, burst: burst, tokens: burst}
+}
+
+// take takes a token, it returns false if the rate is exceeded,
+// otherwise it returns the probability of a trace being admitted recently.
+func (l *rateLimiter) take(now time.Time) (bool, float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rotate(now)
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.attempts[1]++
+	if l.tokens < 1 {
+		return false, 0
+	}
+	l.tokens--
+	l.admits[1]++
+	return true, (l.admits[0] + l.admits[1]) / (l.attempts[0] + l.attempts[1])
+}
+
+// giveBack returns the token of an admitted trace denied by another limiter
+func (l *rateLimiter) giveBack() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+	if l.admits[1] > 0 {
+		l.admits[1]--
+	}
+}
+
+func (l *rateLimiter) rotate(now time.Time) {
+	elapsed := now.Sub(l.window)
+	switch {
+	case elapsed < time.Second:
+		return
+	case elapsed < 2*time.Second:
+		l.attempts[0], l.admits[0] = l.attempts[1], l.admits[1]
+		l.window = l.window.Add(time.Second)
+	default:
+		l.attempts[0], l.admits[0] = 0, 0
+		l.window = now
+	}
+	l.attempts[1], l.admits[1] = 0, 0
+}
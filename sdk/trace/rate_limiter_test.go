@@ -0,0 +1,108 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRateLimiter(t *testing.T) {
+	assert.Nil(t, newRateLimiter(0))
+
+	l := newRateLimiter(2)
+	now := time.Unix(1000, 0)
+	ok, p := l.take(now)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, p)
+	ok, _ = l.take(now)
+	assert.True(t, ok)
+	ok, _ = l.take(now)
+	assert.False(t, ok, "burst exhausted")
+	ok, _ = l.take(now.Add(100 * time.Millisecond))
+	assert.False(t, ok, "not refilled yet")
+
+	ok, p = l.take(now.Add(500 * time.Millisecond))
+	assert.True(t, ok)
+	assert.Equal(t, 3.0/5, p, "3 of 5 attempts admitted")
+
+	ok, p = l.take(now.Add(5 * time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, p, "old windows are dropped")
+}
+
+func TestSampler_RateLimit(t *testing.T) {
+	defer func(f GetCalleeMethodInfo) { DefaultGetCalleeMethodInfo = f }(DefaultGetCalleeMethodInfo)
+	DefaultGetCalleeMethodInfo = func(ctx context.Context) MethodInfo {
+		return MethodInfo{CalleeService: "service", CalleeMethod: "method"}
+	}
+	ws := NewSampler("", SamplerConfig{
+		Fraction:  1,
+		RateLimit: 1000,
+		SpecialFractions: map[string]SpecialFraction{"service": {
+			DefaultFraction: 1,
+			Methods:         map[string]MethodFraction{"method": {Fraction: 1, RateLimit: 1}},
+		}},
+	}).(*Sampler)
+	p := sdktrace.SamplingParameters{ParentContext: context.Background()}
+
+	got := ws.ShouldSample(p)
+	assert.Equal(t, sdktrace.RecordAndSample, got.Decision)
+	assert.Empty(t, got.Tracestate.Get(string(traceStateProbability)))
+
+	got = ws.ShouldSample(p)
+	assert.Equal(t, sdktrace.Drop, got.Decision, "method budget exhausted")
+
+	ws.samplerConfig.SpecialFractions["service"].Methods["method"].rateLimiter.last = time.Now().Add(-time.Second)
+	got = ws.ShouldSample(p)
+	assert.Equal(t, sdktrace.RecordAndSample, got.Decision)
+	assert.Equal(t, "0.666667", got.Tracestate.Get(string(traceStateProbability)))
+}
+
+func TestSampler_RateLimitRootOnly(t *testing.T) {
+	defer func(f GetCalleeMethodInfo) { DefaultGetCalleeMethodInfo = f }(DefaultGetCalleeMethodInfo)
+	DefaultGetCalleeMethodInfo = func(ctx context.Context) MethodInfo {
+		return MethodInfo{CalleeService: "service", CalleeMethod: "method"}
+	}
+	ws := NewSampler("", SamplerConfig{
+		Fraction:  1,
+		RateLimit: 1,
+		SpecialFractions: map[string]SpecialFraction{"service": {
+			DefaultFraction: 1,
+			Methods:         map[string]MethodFraction{"method": {Fraction: 1, RateLimit: 2}},
+		}},
+	}).(*Sampler)
+	root := sdktrace.SamplingParameters{ParentContext: context.Background()}
+	assert.Equal(t, sdktrace.RecordAndSample, ws.ShouldSample(root).Decision)
+	assert.Equal(t, sdktrace.Drop, ws.ShouldSample(root).Decision, "global budget exhausted")
+	method := ws.samplerConfig.SpecialFractions["service"].Methods["method"].rateLimiter
+	assert.InDelta(t, 1.0, method.tokens, 1e-3, "the method token is given back on the global denial")
+
+	unsampled := apitrace.ContextWithSpanContext(context.Background(), apitrace.NewSpanContext(
+		apitrace.SpanContextConfig{TraceID: [16]byte{1}, SpanID: [8]byte{1}}))
+	ws.samplerConfig.rateLimiter.last = time.Now().Add(-time.Second)
+	got := ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: unsampled})
+	assert.Equal(t, sdktrace.Drop, got.Decision, "the local child follows the unsampled parent")
+	assert.InDelta(t, 1.0, method.tokens, 1e-3, "the local child takes no token")
+
+	remote := apitrace.ContextWithRemoteSpanContext(context.Background(), apitrace.NewSpanContext(
+		apitrace.SpanContextConfig{TraceID: [16]byte{1}, SpanID: [8]byte{1}}))
+	got = ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: remote})
+	assert.Equal(t, sdktrace.RecordAndSample, got.Decision, "the remote parent is a local root")
+}
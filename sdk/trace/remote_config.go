@@ -0,0 +1,108 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc-system/go-opentelemetry/sdk/remote"
+)
+
+var (
+	_ remote.ConfigPrepareFunc = (*Sampler)(nil).PrepareRemoteConfig
+	_ remote.ConfigPrepareFunc = (*DynamicDeferredSampleConfig)(nil).PrepareRemoteConfig
+)
+
+// PrepareRemoteConfig validates the default fraction and the special fractions of the remote config,
+// the returned function applies them. The local config is restored if the remote sampler is unset.
+func (ws *Sampler) PrepareRemoteConfig(config *operation.Operation) (func(), error) {
+	s := config.GetSampler()
+	if s == nil {
+		return func() { ws.remoteConfig.Store((*SamplerConfig)(nil)) }, nil
+	}
+	if err := checkFraction(s.GetFraction()); err != nil {
+		return nil, fmt.Errorf("sampler fraction: %w", err)
+	}
+	if err := checkRateLimit(s.GetRateLimit()); err != nil {
+		return nil, fmt.Errorf("sampler: %w", err)
+	}
+	specialFractions := make(map[string]SpecialFraction, len(s.GetSpecialFractions()))
+	for _, sf := range s.GetSpecialFractions() {
+		if sf.GetService() == "" {
+			return nil, fmt.Errorf("sampler special fraction: empty service")
+		}
+		if err := checkFraction(sf.GetFraction()); err != nil {
+			return nil, fmt.Errorf("sampler special fraction of %s: %w", sf.GetService(), err)
+		}
+		if err := checkRateLimit(sf.GetRateLimit()); err != nil {
+			return nil, fmt.Errorf("sampler special fraction of %s: %w", sf.GetService(), err)
+		}
+		methods := make(map[string]MethodFraction, len(sf.GetMethods()))
+		for _, m := range sf.GetMethods() {
+			if err := checkFraction(m.GetFraction()); err != nil {
+				return nil, fmt.Errorf("sampler special fraction of %s/%s: %w", sf.GetService(), m.GetMethod(), err)
+			}
+			if err := checkRateLimit(m.GetRateLimit()); err != nil {
+				return nil, fmt.Errorf("sampler special fraction of %s/%s: %w", sf.GetService(), m.GetMethod(), err)
+			}
+			methods[m.GetMethod()] = MethodFraction{Fraction: m.GetFraction(), RateLimit: m.GetRateLimit()}
+		}
+		specialFractions[sf.GetService()] = SpecialFraction{
+			DefaultFraction: sf.GetFraction(),
+			Methods:         methods,
+			RateLimit:       sf.GetRateLimit(),
+		}
+	}
+	cfg := ws.samplerConfig
+	cfg.Fraction = s.GetFraction()
+	cfg.SpecialFractions = specialFractions
+	cfg.RateLimit = s.GetRateLimit()
+	cfg = getSamplerConfig(cfg)
+	return func() { ws.remoteConfig.Store(&cfg) }, nil
+}
+
+func checkFraction(fraction float64) error {
+	if fraction < 0 || fraction > 1 {
+		return fmt.Errorf("fraction %g out of range [0, 1]", fraction)
+	}
+	return nil
+}
+
+func checkRateLimit(rateLimit float64) error {
+	if rateLimit < 0 || math.IsNaN(rateLimit) {
+		return fmt.Errorf("rate limit %g out of range [0, +Inf)", rateLimit)
+	}
+	return nil
+}
+
+// PrepareRemoteConfig validates the deferred sampling thresholds of the remote config,
+// the returned function applies them. The local config is restored if the remote config is unset.
+func (c *DynamicDeferredSampleConfig) PrepareRemoteConfig(config *operation.Operation) (func(), error) {
+	ds := config.GetTrace().GetDeferredSample()
+	if ds == nil {
+		return func() { c.Store(c.local) }, nil
+	}
+	if ds.GetSampleSlowDurationMs() < 0 {
+		return nil, fmt.Errorf("deferred sample slow duration %dms is negative", ds.GetSampleSlowDurationMs())
+	}
+	cfg := DeferredSampleConfig{
+		Enabled:            ds.GetEnabled(),
+		SampleError:        ds.GetSampleError(),
+		SampleSlowDuration: time.Duration(ds.GetSampleSlowDurationMs()) * time.Millisecond,
+	}
+	return func() { c.Store(cfg) }, nil
+}
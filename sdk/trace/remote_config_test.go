@@ -0,0 +1,82 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc-system/go-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+func TestSampler_PrepareRemoteConfig(t *testing.T) {
+	ws := NewSampler("tenant", SamplerConfig{Fraction: 0.1}).(*Sampler)
+	assert.Equal(t, 0.1, ws.fractionConfig().Fraction)
+
+	apply, err := ws.PrepareRemoteConfig(&operation.Operation{Sampler: &operation.Sampler{
+		Fraction: 0.5,
+		SpecialFractions: []*operation.SpecialFraction{{
+			Service:  "trpc.app.server.Greeter",
+			Fraction: 1,
+			Methods:  []*operation.MethodFraction{{Method: "Hello", Fraction: 0, RateLimit: 10}},
+		}},
+		RateLimit: 100,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, ws.fractionConfig().Fraction, "not applied before apply is called")
+	apply()
+	cfg := ws.fractionConfig()
+	assert.Equal(t, 0.5, cfg.Fraction)
+	assert.Equal(t, 1.0, cfg.SpecialFractions["trpc.app.server.Greeter"].DefaultFraction)
+	assert.Equal(t, 0.0, cfg.SpecialFractions["trpc.app.server.Greeter"].Methods["Hello"].Fraction)
+	assert.Equal(t, 100.0, cfg.RateLimit)
+	assert.NotNil(t, cfg.rateLimiter)
+	assert.NotNil(t, cfg.SpecialFractions["trpc.app.server.Greeter"].Methods["Hello"].rateLimiter)
+
+	_, err = ws.PrepareRemoteConfig(&operation.Operation{Sampler: &operation.Sampler{Fraction: 1.5}})
+	assert.Error(t, err)
+	_, err = ws.PrepareRemoteConfig(&operation.Operation{Sampler: &operation.Sampler{Fraction: 1, RateLimit: -1}})
+	assert.Error(t, err)
+	assert.Equal(t, 0.5, ws.fractionConfig().Fraction)
+
+	apply, err = ws.PrepareRemoteConfig(&operation.Operation{})
+	require.NoError(t, err)
+	apply()
+	assert.Equal(t, 0.1, ws.fractionConfig().Fraction, "local config restored")
+}
+
+func TestDynamicDeferredSampleConfig_PrepareRemoteConfig(t *testing.T) {
+	local := DeferredSampleConfig{Enabled: true, SampleError: true}
+	c := NewDynamicDeferredSampleConfig(local)
+
+	apply, err := c.PrepareRemoteConfig(&operation.Operation{Trace: &operation.Trace{
+		DeferredSample: &operation.DeferredSample{Enabled: true, SampleSlowDurationMs: 200},
+	}})
+	require.NoError(t, err)
+	apply()
+	assert.Equal(t, DeferredSampleConfig{Enabled: true, SampleSlowDuration: 200 * time.Millisecond}, c.Load())
+
+	_, err = c.PrepareRemoteConfig(&operation.Operation{Trace: &operation.Trace{
+		DeferredSample: &operation.DeferredSample{SampleSlowDurationMs: -1},
+	}})
+	assert.Error(t, err)
+
+	apply, err = c.PrepareRemoteConfig(&operation.Operation{})
+	require.NoError(t, err)
+	apply()
+	assert.Equal(t, local, c.Load())
+}
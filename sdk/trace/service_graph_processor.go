@@ -0,0 +1,515 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ sdktrace.SpanProcessor = (*ServiceGraphProcessor)(nil)
+
+// Defaults for ServiceGraphConfig.
+const (
+	// DefaultServiceGraphWaitTime default time to wait for the other side of the in-process call
+	DefaultServiceGraphWaitTime = 5 * time.Second
+	// DefaultServiceGraphMaxPending default max number of the spans waiting for the other side
+	DefaultServiceGraphMaxPending = 10000
+	// DefaultServiceGraphMaxEdges default max number of the edges
+	DefaultServiceGraphMaxEdges = 1000
+)
+
+// connection types of the service graph edges
+const (
+	// ConnectionInProcess the client and server spans are both ended in this process
+	ConnectionInProcess = "in_process"
+	// ConnectionClient the half edge of the client span, the server is identified by the callee or the peer address
+	ConnectionClient = "client"
+	// ConnectionServer the half edge of the server span, the client is identified by the caller or the peer address
+	ConnectionServer = "server"
+)
+
+// the caller and callee attributes set by the tRPC filters, same as oteltrpc/semconv
+const (
+	callerServiceKey = attribute.Key("trpc.caller_service")
+	calleeServiceKey = attribute.Key("trpc.callee_service")
+)
+
+const unknownService = "unknown"
+
+// serviceGraphLabels the labels of the service graph metrics
+var serviceGraphLabels = []string{"client", "server", "connection_type"}
+
+// ServiceGraphConfig service graph configuration
+type ServiceGraphConfig struct {
+	// Buckets the buckets of the latency histograms, default DefaultSpanMetricsBuckets.
+	Buckets []float64
+	// WaitTime the time to wait for the other side of the in-process call, the half edge is emitted after it.
+	// The default value of WaitTime is 5s.
+	WaitTime time.Duration
+	// MaxPending the max number of the spans waiting for the other side, the oldest one is emitted as the half edge
+	// when it is exceeded.
+	// The default value of MaxPending is 10000.
+	MaxPending int
+	// MaxEdges the max number of the edges, the new edges beyond it are recorded with all label values __overflow__.
+	// The default value of MaxEdges is 1000.
+	MaxEdges int
+	// Registerer registers the service graph metrics.
+	// The default value of Registerer is prometheus.DefaultRegisterer.
+	Registerer prometheus.Registerer
+}
+
+// serviceGraphEdge an edge of the service graph
+type serviceGraphEdge struct {
+	client         string
+	server         string
+	connectionType string
+}
+
+// ServiceGraphEdgeStatus the requests of an edge, served by ServiceGraphHandler
+type ServiceGraphEdgeStatus struct {
+	Client         string `json:"client"`
+	Server         string `json:"server"`
+	ConnectionType string `json:"connection_type"`
+	Requests       uint64 `json:"requests"`
+	Failed         uint64 `json:"failed"`
+}
+
+// ServiceGraphStatus the local dependency graph served by ServiceGraphHandler
+type ServiceGraphStatus struct {
+	Nodes []string                 `json:"nodes"`
+	Edges []ServiceGraphEdgeStatus `json:"edges"`
+}
+
+type edgeStats struct {
+	requests uint64
+	failed   uint64
+}
+
+// pairKey the client span ID, which is the parent span ID of the server span
+type pairKey struct {
+	traceID trace.TraceID
+	spanID  trace.SpanID
+}
+
+// pendingSpan the half of a call waiting for the other side
+type pendingSpan struct {
+	kind     trace.SpanKind
+	edge     serviceGraphEdge
+	failed   bool
+	seconds  float64
+	deadline time.Time
+	elem     *list.Element
+}
+
+// ServiceGraphProcessor aggregates the client and server spans into the edges of the service graph,
+// service_graph_request_total, service_graph_request_failed_total, service_graph_request_client_seconds and
+// service_graph_request_server_seconds by client, server and connection type.
+// The client and server spans of the in-process calls are paired by the span ID, the others are emitted as the
+// half edges identified by the peer address if the caller or callee is unknown.
+type ServiceGraphProcessor struct {
+	cfg           ServiceGraphConfig
+	requests      *prometheus.CounterVec
+	failed        *prometheus.CounterVec
+	clientSeconds *prometheus.HistogramVec
+	serverSeconds *prometheus.HistogramVec
+
+	mu      sync.Mutex
+	pending map[pairKey]*pendingSpan
+	order   *list.List // pair keys, oldest first
+	edges   map[serviceGraphEdge]*edgeStats
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	stopWait sync.WaitGroup
+}
+
+var defaultServiceGraph atomic.Value
+
+// NewServiceGraphProcessor create a new service graph processor, the graph of the latest processor is served by
+// ServiceGraphHandler
+func NewServiceGraphProcessor(cfg ServiceGraphConfig) (*ServiceGraphProcessor, error) {
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = DefaultSpanMetricsBuckets
+	}
+	if cfg.WaitTime <= 0 {
+		cfg.WaitTime = DefaultServiceGraphWaitTime
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = DefaultServiceGraphMaxPending
+	}
+	if cfg.MaxEdges <= 0 {
+		cfg.MaxEdges = DefaultServiceGraphMaxEdges
+	}
+	if cfg.Registerer == nil {
+		cfg.Registerer = prometheus.DefaultRegisterer
+	}
+	p := &ServiceGraphProcessor{
+		cfg:     cfg,
+		pending: make(map[pairKey]*pendingSpan),
+		order:   list.New(),
+		edges:   make(map[serviceGraphEdge]*edgeStats),
+		stopCh:  make(chan struct{}),
+	}
+	var err error
+	if p.requests, err = registerCounterVec(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "service_graph_request_total",
+		Help: "Total number of the requests between two services.",
+	}, serviceGraphLabels)); err != nil {
+		return nil, err
+	}
+	if p.failed, err = registerCounterVec(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "service_graph_request_failed_total",
+		Help: "Total number of the failed requests between two services.",
+	}, serviceGraphLabels)); err != nil {
+		return nil, err
+	}
+	if p.clientSeconds, err = registerHistogramVec(cfg.Registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "service_graph_request_client_seconds",
+		Help:    "Histogram of the latency (seconds) of the requests between two services seen by the client.",
+		Buckets: cfg.Buckets,
+	}, serviceGraphLabels)); err != nil {
+		return nil, err
+	}
+	if p.serverSeconds, err = registerHistogramVec(cfg.Registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "service_graph_request_server_seconds",
+		Help:    "Histogram of the latency (seconds) of the requests between two services seen by the server.",
+		Buckets: cfg.Buckets,
+	}, serviceGraphLabels)); err != nil {
+		return nil, err
+	}
+	p.stopWait.Add(1)
+	go func() {
+		defer p.stopWait.Done()
+		p.expireDaemon()
+	}()
+	defaultServiceGraph.Store(p)
+	return p, nil
+}
+
+// OnStart is called when a span is started. It is called synchronously
+// and should not block.
+func (p *ServiceGraphProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd is called when span is finished. It is called synchronously and
+// hence not block.
+func (p *ServiceGraphProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	var key pairKey
+	switch s.SpanKind() {
+	case trace.SpanKindClient:
+		key = pairKey{traceID: s.SpanContext().TraceID(), spanID: s.SpanContext().SpanID()}
+	case trace.SpanKindServer:
+		if !s.Parent().IsValid() {
+			// no client upstream, e.g. the entry of the trace
+			p.emitHalf(halfSpan(s))
+			return
+		}
+		key = pairKey{traceID: s.SpanContext().TraceID(), spanID: s.Parent().SpanID()}
+	default:
+		return
+	}
+	half := halfSpan(s)
+
+	p.mu.Lock()
+	other, ok := p.pending[key]
+	if ok && other.kind != half.kind {
+		delete(p.pending, key)
+		p.order.Remove(other.elem)
+		p.mu.Unlock()
+		p.emitPair(half, other)
+		return
+	}
+	var evicted []*pendingSpan
+	if !ok {
+		half.deadline = time.Now().Add(p.cfg.WaitTime)
+		half.elem = p.order.PushBack(key)
+		p.pending[key] = half
+		for p.order.Len() > p.cfg.MaxPending {
+			evicted = append(evicted, p.removeLocked(p.order.Front()))
+		}
+	} else {
+		// the span ID is reused by the same kind, which is not a pair
+		evicted = append(evicted, half)
+	}
+	p.mu.Unlock()
+
+	for _, v := range evicted {
+		p.emitHalf(v)
+	}
+}
+
+// halfSpan identifies the both sides by the span, the local side falls back to the service name of the resource,
+// the remote side falls back to the peer address
+func halfSpan(s sdktrace.ReadOnlySpan) *pendingSpan {
+	attrs := make(map[attribute.Key]string, 4)
+	for _, kv := range s.Attributes() {
+		switch kv.Key {
+		case callerServiceKey, calleeServiceKey, semconv.NetPeerIPKey, semconv.NetPeerPortKey:
+			attrs[kv.Key] = kv.Value.Emit()
+		}
+	}
+	var local string
+	if s.Resource() != nil {
+		if v, ok := s.Resource().Set().Value(semconv.ServiceNameKey); ok {
+			local = v.Emit()
+		}
+	}
+	h := &pendingSpan{
+		kind:    s.SpanKind(),
+		failed:  s.Status().Code == codes.Error,
+		seconds: s.EndTime().Sub(s.StartTime()).Seconds(),
+	}
+	if h.kind == trace.SpanKindClient {
+		peer := attrs[semconv.NetPeerIPKey]
+		if peer != "" && attrs[semconv.NetPeerPortKey] != "" {
+			peer += ":" + attrs[semconv.NetPeerPortKey]
+		}
+		h.edge = serviceGraphEdge{
+			client:         firstNonEmpty(attrs[callerServiceKey], local),
+			server:         firstNonEmpty(attrs[calleeServiceKey], peer),
+			connectionType: ConnectionClient,
+		}
+		return h
+	}
+	// the peer port of the server span is ephemeral
+	h.edge = serviceGraphEdge{
+		client:         firstNonEmpty(attrs[callerServiceKey], attrs[semconv.NetPeerIPKey]),
+		server:         firstNonEmpty(attrs[calleeServiceKey], local),
+		connectionType: ConnectionServer,
+	}
+	return h
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return unknownService
+}
+
+func (p *ServiceGraphProcessor) emitPair(a, b *pendingSpan) {
+	client, server := a, b
+	if a.kind == trace.SpanKindServer {
+		client, server = b, a
+	}
+	edge := p.limit(serviceGraphEdge{
+		client:         client.edge.client,
+		server:         server.edge.server,
+		connectionType: ConnectionInProcess,
+	})
+	failed := client.failed || server.failed
+	p.record(edge, failed)
+	p.clientSeconds.WithLabelValues(edge.client, edge.server, edge.connectionType).Observe(client.seconds)
+	p.serverSeconds.WithLabelValues(edge.client, edge.server, edge.connectionType).Observe(server.seconds)
+}
+
+func (p *ServiceGraphProcessor) emitHalf(h *pendingSpan) {
+	edge := p.limit(h.edge)
+	p.record(edge, h.failed)
+	if h.kind == trace.SpanKindClient {
+		p.clientSeconds.WithLabelValues(edge.client, edge.server, edge.connectionType).Observe(h.seconds)
+	} else {
+		p.serverSeconds.WithLabelValues(edge.client, edge.server, edge.connectionType).Observe(h.seconds)
+	}
+}
+
+func (p *ServiceGraphProcessor) record(edge serviceGraphEdge, failed bool) {
+	p.requests.WithLabelValues(edge.client, edge.server, edge.connectionType).Inc()
+	if failed {
+		p.failed.WithLabelValues(edge.client, edge.server, edge.connectionType).Inc()
+	}
+	p.mu.Lock()
+	stats := p.edges[edge]
+	stats.requests++
+	if failed {
+		stats.failed++
+	}
+	p.mu.Unlock()
+}
+
+// limit folds the edges beyond MaxEdges, the stats of the returned edge exist
+func (p *ServiceGraphProcessor) limit(edge serviceGraphEdge) serviceGraphEdge {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.edges[edge]; ok {
+		return edge
+	}
+	if len(p.edges) >= p.cfg.MaxEdges {
+		edge = serviceGraphEdge{
+			client:         overflowLabelValue,
+			server:         overflowLabelValue,
+			connectionType: overflowLabelValue,
+		}
+		if _, ok := p.edges[edge]; ok {
+			return edge
+		}
+	}
+	p.edges[edge] = &edgeStats{}
+	return edge
+}
+
+func (p *ServiceGraphProcessor) removeLocked(elem *list.Element) *pendingSpan {
+	key := elem.Value.(pairKey)
+	h := p.pending[key]
+	delete(p.pending, key)
+	p.order.Remove(elem)
+	return h
+}
+
+// expireDaemon emits the half edges of the spans whose other side did not end within WaitTime.
+func (p *ServiceGraphProcessor) expireDaemon() {
+	interval := p.cfg.WaitTime / 10
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case now := <-ticker.C:
+			p.expire(now)
+		}
+	}
+}
+
+func (p *ServiceGraphProcessor) expire(now time.Time) {
+	var expired []*pendingSpan
+	p.mu.Lock()
+	for p.order.Len() > 0 {
+		elem := p.order.Front()
+		if p.pending[elem.Value.(pairKey)].deadline.After(now) {
+			break
+		}
+		expired = append(expired, p.removeLocked(elem))
+	}
+	p.mu.Unlock()
+
+	for _, h := range expired {
+		p.emitHalf(h)
+	}
+}
+
+// Status returns the current local dependency graph
+func (p *ServiceGraphProcessor) Status() ServiceGraphStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	nodes := make(map[string]struct{})
+	status := ServiceGraphStatus{Nodes: []string{}, Edges: []ServiceGraphEdgeStatus{}}
+	for edge, stats := range p.edges {
+		if stats.requests == 0 {
+			continue
+		}
+		nodes[edge.client] = struct{}{}
+		nodes[edge.server] = struct{}{}
+		status.Edges = append(status.Edges, ServiceGraphEdgeStatus{
+			Client:         edge.client,
+			Server:         edge.server,
+			ConnectionType: edge.connectionType,
+			Requests:       stats.requests,
+			Failed:         stats.failed,
+		})
+	}
+	for node := range nodes {
+		status.Nodes = append(status.Nodes, node)
+	}
+	sort.Strings(status.Nodes)
+	sort.Slice(status.Edges, func(i, j int) bool {
+		a, b := status.Edges[i], status.Edges[j]
+		if a.Client != b.Client {
+			return a.Client < b.Client
+		}
+		if a.Server != b.Server {
+			return a.Server < b.Server
+		}
+		return a.ConnectionType < b.ConnectionType
+	})
+	return status
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (s ServiceGraphStatus) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph service_graph {\n")
+	for _, node := range s.Nodes {
+		fmt.Fprintf(&b, "  %q;\n", node)
+	}
+	for _, e := range s.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.Client, e.Server,
+			fmt.Sprintf("%s requests=%d failed=%d", e.ConnectionType, e.Requests, e.Failed))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Shutdown emits the half edges of the pending spans and stops the expiration.
+func (p *ServiceGraphProcessor) Shutdown(context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		p.stopWait.Wait()
+		p.ForceFlush(context.Background())
+	})
+	return nil
+}
+
+// ForceFlush emits the half edges of the pending spans immediately.
+func (p *ServiceGraphProcessor) ForceFlush(context.Context) error {
+	var pending []*pendingSpan
+	p.mu.Lock()
+	for p.order.Len() > 0 {
+		pending = append(pending, p.removeLocked(p.order.Front()))
+	}
+	p.mu.Unlock()
+
+	for _, h := range pending {
+		p.emitHalf(h)
+	}
+	return nil
+}
+
+// ServiceGraphHandler serves the local dependency graph of the latest service graph processor as JSON,
+// or as DOT with the query format=dot
+func ServiceGraphHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := ServiceGraphStatus{Nodes: []string{}, Edges: []ServiceGraphEdgeStatus{}}
+		if p, ok := defaultServiceGraph.Load().(*ServiceGraphProcessor); ok {
+			status = p.Status()
+		}
+		if r.URL.Query().Get("format") == "dot" {
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+			_, _ = w.Write([]byte(status.DOT()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
@@ -0,0 +1,109 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func newServiceGraphTestProvider(t *testing.T, cfg ServiceGraphConfig) (
+	*sdktrace.TracerProvider, *ServiceGraphProcessor) {
+	cfg.Registerer = prometheus.NewRegistry()
+	p, err := NewServiceGraphProcessor(cfg)
+	require.Nil(t, err)
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(p)), p
+}
+
+func TestServiceGraphProcessor_InProcess(t *testing.T) {
+	tp, p := newServiceGraphTestProvider(t, ServiceGraphConfig{})
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	ctx, client := tracer.Start(context.Background(), "call", apitrace.WithSpanKind(apitrace.SpanKindClient),
+		apitrace.WithAttributes(callerServiceKey.String("a"), calleeServiceKey.String("b")))
+	_, server := tracer.Start(ctx, "handle", apitrace.WithSpanKind(apitrace.SpanKindServer),
+		apitrace.WithAttributes(callerServiceKey.String("a"), calleeServiceKey.String("b")))
+	server.SetStatus(codes.Error, "failed")
+	server.End()
+	client.End()
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.requests.WithLabelValues("a", "b", ConnectionInProcess)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.failed.WithLabelValues("a", "b", ConnectionInProcess)))
+	assert.Equal(t, 1, testutil.CollectAndCount(p.clientSeconds))
+	assert.Equal(t, 1, testutil.CollectAndCount(p.serverSeconds))
+}
+
+func TestServiceGraphProcessor_HalfEdge(t *testing.T) {
+	tp, p := newServiceGraphTestProvider(t, ServiceGraphConfig{WaitTime: 100 * time.Millisecond})
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	_, client := tracer.Start(context.Background(), "call", apitrace.WithSpanKind(apitrace.SpanKindClient),
+		apitrace.WithAttributes(callerServiceKey.String("a"),
+			semconv.NetPeerIPKey.String("10.0.0.1"), semconv.NetPeerPortKey.String("8000")))
+	client.End()
+	assert.Equal(t, float64(0), testutil.ToFloat64(p.requests.WithLabelValues("a", "10.0.0.1:8000", ConnectionClient)))
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(p.requests.WithLabelValues("a", "10.0.0.1:8000", ConnectionClient)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the entry server span has no client span to wait for
+	_, server := tracer.Start(context.Background(), "handle", apitrace.WithSpanKind(apitrace.SpanKindServer),
+		apitrace.WithAttributes(calleeServiceKey.String("a"), semconv.NetPeerIPKey.String("10.0.0.2")))
+	server.End()
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.requests.WithLabelValues("10.0.0.2", "a", ConnectionServer)))
+
+	w := httptest.NewRecorder()
+	ServiceGraphHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/servicegraph", nil))
+	var status ServiceGraphStatus
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, []string{"10.0.0.1:8000", "10.0.0.2", "a"}, status.Nodes)
+	assert.Equal(t, []ServiceGraphEdgeStatus{
+		{Client: "10.0.0.2", Server: "a", ConnectionType: ConnectionServer, Requests: 1},
+		{Client: "a", Server: "10.0.0.1:8000", ConnectionType: ConnectionClient, Requests: 1},
+	}, status.Edges)
+
+	w = httptest.NewRecorder()
+	ServiceGraphHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/servicegraph?format=dot", nil))
+	assert.Contains(t, w.Body.String(), `"a" -> "10.0.0.1:8000" [label="client requests=1 failed=0"];`)
+}
+
+func TestServiceGraphProcessor_MaxEdges(t *testing.T) {
+	tp, p := newServiceGraphTestProvider(t, ServiceGraphConfig{MaxEdges: 1})
+	tracer := tp.Tracer("test")
+	for _, callee := range []string{"b", "c", "d"} {
+		_, client := tracer.Start(context.Background(), "call", apitrace.WithSpanKind(apitrace.SpanKindClient),
+			apitrace.WithAttributes(callerServiceKey.String("a"), calleeServiceKey.String(callee)))
+		client.End()
+	}
+	// the pending spans are emitted as the half edges on shutdown
+	require.Nil(t, tp.Shutdown(context.Background()))
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.requests.WithLabelValues("a", "b", ConnectionClient)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(p.requests.WithLabelValues(
+		overflowLabelValue, overflowLabelValue, overflowLabelValue)))
+}
@@ -0,0 +1,232 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var _ sdktrace.SpanProcessor = (*SpanMetricsProcessor)(nil)
+
+// Defaults for SpanMetricsConfig.
+const (
+	// DefaultSpanMetricsMaxSeries default max number of label sets of the span metrics
+	DefaultSpanMetricsMaxSeries = 2000
+	// overflowLabelValue the label value which the label sets beyond the limits are folded into
+	overflowLabelValue = "__overflow__"
+)
+
+// DefaultSpanMetricsBuckets default buckets of span_duration_seconds, same as the RPC latency histograms
+var DefaultSpanMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 5}
+
+// spanMetricsLabels the labels of every span metric, followed by the attributes
+var spanMetricsLabels = []string{"span_name", "span_kind", "status_code"}
+
+// SpanMetricsConfig span metrics configuration
+type SpanMetricsConfig struct {
+	// Attributes the span attributes added as labels, the characters invalid in the label name are replaced by
+	// underscores, e.g. http.route is the label http_route. The value is empty if the span has no such attribute.
+	Attributes []string
+	// Buckets the buckets of span_duration_seconds, default DefaultSpanMetricsBuckets.
+	Buckets []float64
+	// MaxSeries the max number of label sets, the new label sets beyond it are recorded with all label values
+	// __overflow__, so the totals remain correct.
+	// The default value of MaxSeries is 2000.
+	MaxSeries int
+	// Registerer registers the span metrics, they are served by the /metrics handler of sdk/metric by default.
+	// The default value of Registerer is prometheus.DefaultRegisterer.
+	Registerer prometheus.Registerer
+}
+
+// SpanMetricsProcessor aggregates the ended spans into the RED metrics, span_calls_total and
+// span_duration_seconds by span name, kind, status and the configured attributes.
+// The unsampled spans recorded for the deferred sampling are counted as well, the trace ID of the sampled span is
+// attached as the exemplar.
+type SpanMetricsProcessor struct {
+	calls      *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	attributes []attribute.Key
+	maxSeries  int
+
+	mu     sync.RWMutex
+	series map[string]struct{}
+}
+
+// NewSpanMetricsProcessor create a new span metrics processor, the metrics registered by a previous processor
+// with the same labels are reused
+func NewSpanMetricsProcessor(cfg SpanMetricsConfig) (*SpanMetricsProcessor, error) {
+	if cfg.MaxSeries <= 0 {
+		cfg.MaxSeries = DefaultSpanMetricsMaxSeries
+	}
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = DefaultSpanMetricsBuckets
+	}
+	if cfg.Registerer == nil {
+		cfg.Registerer = prometheus.DefaultRegisterer
+	}
+	labels := append([]string(nil), spanMetricsLabels...)
+	p := &SpanMetricsProcessor{
+		maxSeries: cfg.MaxSeries,
+		series:    make(map[string]struct{}),
+	}
+	for _, attr := range cfg.Attributes {
+		name := spanMetricsLabelName(attr)
+		for _, l := range labels {
+			if l == name {
+				return nil, fmt.Errorf("trace: span metrics label %q of attribute %q is duplicated", name, attr)
+			}
+		}
+		labels = append(labels, name)
+		p.attributes = append(p.attributes, attribute.Key(attr))
+	}
+
+	p.calls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "span",
+		Name:      "calls_total",
+		Help:      "Total number of the ended spans.",
+	}, labels)
+	p.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "span",
+		Name:      "duration_seconds",
+		Help:      "Histogram of the duration (seconds) of the ended spans.",
+		Buckets:   cfg.Buckets,
+	}, labels)
+	var err error
+	if p.calls, err = registerCounterVec(cfg.Registerer, p.calls); err != nil {
+		return nil, err
+	}
+	if p.duration, err = registerHistogramVec(cfg.Registerer, p.duration); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func registerCounterVec(r prometheus.Registerer, c *prometheus.CounterVec) (*prometheus.CounterVec, error) {
+	err := r.Register(c)
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+			return existing, nil
+		}
+	}
+	return c, err
+}
+
+func registerHistogramVec(r prometheus.Registerer, h *prometheus.HistogramVec) (*prometheus.HistogramVec, error) {
+	err := r.Register(h)
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(*prometheus.HistogramVec); ok {
+			return existing, nil
+		}
+	}
+	return h, err
+}
+
+// spanMetricsLabelName replaces the characters invalid in the Prometheus label name by underscores
+func spanMetricsLabelName(attr string) string {
+	b := []byte(attr)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// OnStart is called when a span is started. It is called synchronously
+// and should not block.
+func (p *SpanMetricsProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd is called when span is finished. It is called synchronously and
+// hence not block.
+func (p *SpanMetricsProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	labelValues := p.limit(p.labelValues(s))
+	costSecs := s.EndTime().Sub(s.StartTime()).Seconds()
+	counter := p.calls.WithLabelValues(labelValues...)
+	histogram := p.duration.WithLabelValues(labelValues...)
+	if !s.SpanContext().IsSampled() {
+		counter.Inc()
+		histogram.Observe(costSecs)
+		return
+	}
+	exemplar := prometheus.Labels{"traceID": s.SpanContext().TraceID().String()}
+	if v, ok := counter.(prometheus.ExemplarAdder); ok {
+		v.AddWithExemplar(1, exemplar)
+	} else {
+		counter.Inc()
+	}
+	if v, ok := histogram.(prometheus.ExemplarObserver); ok {
+		v.ObserveWithExemplar(costSecs, exemplar)
+	} else {
+		histogram.Observe(costSecs)
+	}
+}
+
+func (p *SpanMetricsProcessor) labelValues(s sdktrace.ReadOnlySpan) []string {
+	values := make([]string, len(spanMetricsLabels), len(spanMetricsLabels)+len(p.attributes))
+	values[0] = strings.ToValidUTF8(s.Name(), "")
+	values[1] = s.SpanKind().String()
+	values[2] = s.Status().Code.String()
+	if len(p.attributes) == 0 {
+		return values
+	}
+	attrs := make(map[attribute.Key]string, len(p.attributes))
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	for _, key := range p.attributes {
+		values = append(values, strings.ToValidUTF8(attrs[key], ""))
+	}
+	return values
+}
+
+// limit folds the label sets beyond maxSeries, the accepted label sets are kept until the process exits
+func (p *SpanMetricsProcessor) limit(values []string) []string {
+	key := strings.Join(values, "\xff")
+	p.mu.RLock()
+	_, ok := p.series[key]
+	p.mu.RUnlock()
+	if ok {
+		return values
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.series[key]; ok || len(p.series) < p.maxSeries {
+		p.series[key] = struct{}{}
+		return values
+	}
+	for i := range values {
+		values[i] = overflowLabelValue
+	}
+	return values
+}
+
+// Shutdown does nothing, the metrics are kept for the last scrape.
+func (p *SpanMetricsProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing, the metrics are collected when scraped.
+func (p *SpanMetricsProcessor) ForceFlush(context.Context) error {
+	return nil
+}
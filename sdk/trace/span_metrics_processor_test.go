@@ -0,0 +1,107 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func newSpanMetricsTestProvider(t *testing.T, fraction float64, cfg SpanMetricsConfig) (
+	*sdktrace.TracerProvider, *SpanMetricsProcessor) {
+	p, err := NewSpanMetricsProcessor(cfg)
+	require.Nil(t, err)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewSampler("", SamplerConfig{Fraction: fraction}, func(o *SamplerOptions) {
+			o.DefaultSamplingDecision = sdktrace.RecordOnly
+		})),
+		sdktrace.WithSpanProcessor(p),
+	)
+	return tp, p
+}
+
+func TestSpanMetricsProcessor(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	tp, p := newSpanMetricsTestProvider(t, 0, SpanMetricsConfig{
+		Attributes: []string{"http.route"},
+		Registerer: reg,
+	})
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "GET", apitrace.WithSpanKind(apitrace.SpanKindServer),
+		apitrace.WithAttributes(attribute.String("http.route", "/user/{id}")))
+	endOk(span)
+	_, span = tracer.Start(context.Background(), "GET", apitrace.WithSpanKind(apitrace.SpanKindServer),
+		apitrace.WithAttributes(attribute.String("http.route", "/user/{id}")))
+	span.SetStatus(codes.Error, "failed")
+	span.End()
+
+	// the unsampled spans are counted
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.calls.WithLabelValues("GET", "server", "Ok", "/user/{id}")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.calls.WithLabelValues("GET", "server", "Error", "/user/{id}")))
+	assert.Equal(t, 2, testutil.CollectAndCount(p.duration))
+}
+
+func TestSpanMetricsProcessor_Exemplar(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	tp, _ := newSpanMetricsTestProvider(t, 1, SpanMetricsConfig{Registerer: reg})
+	defer tp.Shutdown(context.Background())
+
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	endOk(span)
+	mfs, err := reg.Gather()
+	require.Nil(t, err)
+	var found bool
+	for _, mf := range mfs {
+		if mf.GetName() != "span_calls_total" {
+			continue
+		}
+		found = true
+		exemplar := mf.GetMetric()[0].GetCounter().GetExemplar()
+		require.NotNil(t, exemplar)
+		assert.Equal(t, span.SpanContext().TraceID().String(), exemplar.GetLabel()[0].GetValue())
+	}
+	assert.True(t, found)
+}
+
+func TestSpanMetricsProcessor_MaxSeries(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	tp, p := newSpanMetricsTestProvider(t, 0, SpanMetricsConfig{MaxSeries: 1, Registerer: reg})
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	for _, name := range []string{"op1", "op2", "op3"} {
+		_, span := tracer.Start(context.Background(), name)
+		endOk(span)
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(p.calls.WithLabelValues(
+		overflowLabelValue, overflowLabelValue, overflowLabelValue)))
+
+	// the metrics are reused by the next processor
+	p2, err := NewSpanMetricsProcessor(SpanMetricsConfig{Registerer: reg})
+	require.Nil(t, err)
+	assert.Equal(t, p.calls, p2.calls)
+	_, err = NewSpanMetricsProcessor(SpanMetricsConfig{Attributes: []string{"span.name"}, Registerer: reg})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,410 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"trpc-system/go-opentelemetry/pkg/metrics"
+)
+
+var _ sdktrace.SpanProcessor = (*TailSampleProcessor)(nil)
+
+// Defaults for TailSampleConfig.
+const (
+	// DefaultTailSampleDecisionWait default time to wait for the local root span
+	DefaultTailSampleDecisionWait = 10 * time.Second
+	// DefaultTailSampleMaxSpans default max number of buffered spans
+	DefaultTailSampleMaxSpans = 20000
+	// DefaultTailSampleMaxBytes default max size of buffered spans (in bytes)
+	DefaultTailSampleMaxBytes = 64 * 1024 * 1024
+	// spanBaseSize estimated fixed size of a span besides its name, attributes and events
+	spanBaseSize = 128
+)
+
+var (
+	tailSampleKeptCounter    = metrics.DeferredProcessCounter.WithLabelValues("tail_kept", "traces")
+	tailSampleDroppedCounter = metrics.DeferredProcessCounter.WithLabelValues("tail_dropped", "traces")
+	tailSampleLateCounter    = metrics.DeferredProcessCounter.WithLabelValues("tail_late", "traces")
+	tailSampleBufferSpans    = metrics.TailSampleBufferGauge.WithLabelValues("spans")
+	tailSampleBufferBytes    = metrics.TailSampleBufferGauge.WithLabelValues("bytes")
+	tailSampleBufferTraces   = metrics.TailSampleBufferGauge.WithLabelValues("traces")
+	tailSampleEvictBySpans   = metrics.TailSampleEvictedCounter.WithLabelValues("max_spans")
+	tailSampleEvictByBytes   = metrics.TailSampleEvictedCounter.WithLabelValues("max_bytes")
+	tailSampleEvictByTimeout = metrics.TailSampleEvictedCounter.WithLabelValues("timeout")
+)
+
+// TailSampleConfig tail sampling configuration
+type TailSampleConfig struct {
+	// DecisionWait is the maximum time to buffer a trace waiting for its local root span to end.
+	// When it is reached the decision is made with the spans buffered so far.
+	// The default value of DecisionWait is 10s.
+	DecisionWait time.Duration
+	// MaxSpans is the maximum number of spans buffered across all traces. When it is exceeded,
+	// the oldest trace is decided and evicted. The number of decided trace IDs remembered for
+	// late spans is limited to MaxSpans as well.
+	// The default value of MaxSpans is 20000.
+	MaxSpans int
+	// MaxBytes is the maximum estimated size of spans buffered across all traces. When it is exceeded,
+	// the oldest trace is decided and evicted.
+	// The default value of MaxBytes is 64M (in bytes).
+	MaxBytes int
+}
+
+// traceBuffer the spans of one local trace waiting for a decision
+type traceBuffer struct {
+	spans    []sdktrace.ReadOnlySpan
+	size     int
+	deadline time.Time
+	elem     *list.Element
+}
+
+// TailSampleProcessor trace aware deferred sampling processor. Ended spans are buffered by trace ID
+// until the local root span ends or DecisionWait is reached, then the whole local trace is kept
+// if any of its spans is kept by the deferred sampler, otherwise the whole local trace is dropped.
+type TailSampleProcessor struct {
+	next            sdktrace.SpanProcessor
+	deferredSampler DeferredSampler
+	cfg             TailSampleConfig
+
+	mu           sync.Mutex
+	traces       map[trace.TraceID]*traceBuffer
+	order        *list.List // trace IDs, oldest first
+	decided      map[trace.TraceID]*decision
+	decidedOrder *list.List // decided trace IDs, earliest deadline first
+	spans        int
+	bytes        int
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	stopWait sync.WaitGroup
+}
+
+// decision the sampling result of a trace, kept for spans ending after the decision is made
+type decision struct {
+	keep     bool
+	deadline time.Time
+	elem     *list.Element
+}
+
+// decidedTrace the buffered spans of a trace together with its decision
+type decidedTrace struct {
+	spans []sdktrace.ReadOnlySpan
+	keep  bool
+}
+
+// NewTailSampleProcessor create a new tail sample processor
+func NewTailSampleProcessor(next sdktrace.SpanProcessor, sampleFunc DeferredSampler,
+	cfg TailSampleConfig) *TailSampleProcessor {
+	if cfg.DecisionWait <= 0 {
+		cfg.DecisionWait = DefaultTailSampleDecisionWait
+	}
+	if cfg.MaxSpans <= 0 {
+		cfg.MaxSpans = DefaultTailSampleMaxSpans
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultTailSampleMaxBytes
+	}
+	p := &TailSampleProcessor{
+		next:            next,
+		deferredSampler: sampleFunc,
+		cfg:             cfg,
+		traces:          make(map[trace.TraceID]*traceBuffer),
+		order:           list.New(),
+		decided:         make(map[trace.TraceID]*decision),
+		decidedOrder:    list.New(),
+		stopCh:          make(chan struct{}),
+	}
+	p.stopWait.Add(1)
+	go func() {
+		defer p.stopWait.Done()
+		p.expireDaemon()
+	}()
+	return p
+}
+
+// OnStart is called when a span is started. It is called synchronously
+// and should not block.
+func (p *TailSampleProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd is called when span is finished. It is called synchronously and
+// hence not block.
+func (p *TailSampleProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if p.deferredSampler == nil {
+		p.next.OnEnd(s)
+		return
+	}
+	select {
+	case <-p.stopCh:
+		return
+	default:
+	}
+
+	traceID := s.SpanContext().TraceID()
+	p.mu.Lock()
+	if d, ok := p.decided[traceID]; ok {
+		// span ends after the decision of its local trace, e.g. async calls.
+		keep := d.keep
+		p.mu.Unlock()
+		tailSampleLateCounter.Inc()
+		if keep || p.deferredSampler(s) {
+			p.next.OnEnd(s)
+		}
+		return
+	}
+
+	tb, ok := p.traces[traceID]
+	if !ok {
+		tb = &traceBuffer{deadline: time.Now().Add(p.cfg.DecisionWait)}
+		tb.elem = p.order.PushBack(traceID)
+		p.traces[traceID] = tb
+	}
+	size := estimateSpanSize(s)
+	tb.spans = append(tb.spans, s)
+	tb.size += size
+	p.spans++
+	p.bytes += size
+
+	var ready []decidedTrace
+	if isLocalRoot(s) {
+		ready = append(ready, p.removeLocked(traceID))
+	}
+	ready = append(ready, p.evictLocked()...)
+	p.updateGaugesLocked()
+	p.mu.Unlock()
+
+	for _, dt := range ready {
+		p.export(dt)
+	}
+}
+
+// evictLocked removes the oldest traces until the buffer limits are satisfied.
+func (p *TailSampleProcessor) evictLocked() []decidedTrace {
+	var evicted []decidedTrace
+	for p.order.Len() > 0 && (p.spans > p.cfg.MaxSpans || p.bytes > p.cfg.MaxBytes) {
+		if p.spans > p.cfg.MaxSpans {
+			tailSampleEvictBySpans.Inc()
+		} else {
+			tailSampleEvictByBytes.Inc()
+		}
+		evicted = append(evicted, p.removeLocked(p.oldestLocked()))
+	}
+	return evicted
+}
+
+func (p *TailSampleProcessor) oldestLocked() trace.TraceID {
+	return p.order.Front().Value.(trace.TraceID)
+}
+
+// removeLocked removes a trace from the buffer and decides it. The decision is made before
+// it is published, and the trace ID is remembered until the decision expires, so that spans
+// ending later always follow the final decision of their trace.
+func (p *TailSampleProcessor) removeLocked(traceID trace.TraceID) decidedTrace {
+	tb, ok := p.traces[traceID]
+	if !ok {
+		return decidedTrace{}
+	}
+	delete(p.traces, traceID)
+	p.order.Remove(tb.elem)
+	p.spans -= len(tb.spans)
+	p.bytes -= tb.size
+
+	keep := false
+	for _, s := range tb.spans {
+		if p.deferredSampler(s) {
+			keep = true
+			break
+		}
+	}
+	p.rememberLocked(traceID, keep)
+	return decidedTrace{spans: tb.spans, keep: keep}
+}
+
+// rememberLocked records the decision of a trace, forgetting the oldest decisions
+// when there are more than MaxSpans of them.
+func (p *TailSampleProcessor) rememberLocked(traceID trace.TraceID, keep bool) {
+	for p.decidedOrder.Len() >= p.cfg.MaxSpans {
+		p.forgetLocked(p.decidedOrder.Front())
+	}
+	p.decided[traceID] = &decision{
+		keep:     keep,
+		deadline: time.Now().Add(p.cfg.DecisionWait),
+		elem:     p.decidedOrder.PushBack(traceID),
+	}
+}
+
+func (p *TailSampleProcessor) forgetLocked(elem *list.Element) {
+	delete(p.decided, elem.Value.(trace.TraceID))
+	p.decidedOrder.Remove(elem)
+}
+
+// export passes all spans of a kept local trace to the next processor.
+func (p *TailSampleProcessor) export(dt decidedTrace) {
+	if len(dt.spans) == 0 {
+		return
+	}
+	if !dt.keep {
+		tailSampleDroppedCounter.Inc()
+		return
+	}
+	tailSampleKeptCounter.Inc()
+	for _, s := range dt.spans {
+		p.next.OnEnd(s)
+	}
+}
+
+// expireDaemon decides traces whose local root span did not end within DecisionWait.
+func (p *TailSampleProcessor) expireDaemon() {
+	interval := p.cfg.DecisionWait / 10
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case now := <-ticker.C:
+			p.expire(now)
+		}
+	}
+}
+
+func (p *TailSampleProcessor) expire(now time.Time) {
+	var expired []decidedTrace
+	p.mu.Lock()
+	for p.order.Len() > 0 {
+		traceID := p.oldestLocked()
+		if p.traces[traceID].deadline.After(now) {
+			break
+		}
+		tailSampleEvictByTimeout.Inc()
+		expired = append(expired, p.removeLocked(traceID))
+	}
+	for p.decidedOrder.Len() > 0 {
+		elem := p.decidedOrder.Front()
+		if p.decided[elem.Value.(trace.TraceID)].deadline.After(now) {
+			break
+		}
+		p.forgetLocked(elem)
+	}
+	p.updateGaugesLocked()
+	p.mu.Unlock()
+
+	for _, dt := range expired {
+		p.export(dt)
+	}
+}
+
+// flush decides all buffered traces immediately.
+func (p *TailSampleProcessor) flush() {
+	var pending []decidedTrace
+	p.mu.Lock()
+	for p.order.Len() > 0 {
+		pending = append(pending, p.removeLocked(p.oldestLocked()))
+	}
+	p.updateGaugesLocked()
+	p.mu.Unlock()
+
+	for _, dt := range pending {
+		p.export(dt)
+	}
+}
+
+func (p *TailSampleProcessor) updateGaugesLocked() {
+	tailSampleBufferSpans.Set(float64(p.spans))
+	tailSampleBufferBytes.Set(float64(p.bytes))
+	tailSampleBufferTraces.Set(float64(len(p.traces)))
+}
+
+// Shutdown is called when the SDK shuts down. Buffered traces are decided before the
+// next processor is shut down.
+func (p *TailSampleProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		p.stopWait.Wait()
+		p.flush()
+	})
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush decides all buffered traces and flushes the next processor.
+func (p *TailSampleProcessor) ForceFlush(ctx context.Context) error {
+	p.flush()
+	return p.next.ForceFlush(ctx)
+}
+
+// isLocalRoot reports whether the span is the root of the trace in this process.
+func isLocalRoot(s sdktrace.ReadOnlySpan) bool {
+	parent := s.Parent()
+	return !parent.IsValid() || parent.IsRemote()
+}
+
+// estimateSpanSize estimates the memory used by a buffered span
+func estimateSpanSize(s sdktrace.ReadOnlySpan) int {
+	size := spanBaseSize + len(s.Name()) + calcSpanSize(s)
+	for _, kv := range s.Attributes() {
+		size += len(kv.Key) + len(kv.Value.Emit())
+	}
+	return size
+}
+
+// NewAttributeDeferredSampler create a deferred sampler keeping spans which has an attribute
+// with the given key, and one of the given values if values are not empty.
+func NewAttributeDeferredSampler(key attribute.Key, values ...string) DeferredSampler {
+	matchValues := make(map[string]bool, len(values))
+	for _, v := range values {
+		matchValues[v] = true
+	}
+	return func(s sdktrace.ReadOnlySpan) bool {
+		for _, kv := range s.Attributes() {
+			if kv.Key != key {
+				continue
+			}
+			if len(matchValues) == 0 || matchValues[kv.Value.Emit()] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NewDyeingDeferredSampler create a deferred sampler keeping spans which are dyed by the Sampler.
+func NewDyeingDeferredSampler() DeferredSampler {
+	return func(s sdktrace.ReadOnlySpan) bool {
+		return s.SpanContext().TraceState().Get(string(traceStateDyeing)) == "true"
+	}
+}
+
+// AnyDeferredSampler combines deferred samplers, the span is kept if any of them keeps it.
+func AnyDeferredSampler(samplers ...DeferredSampler) DeferredSampler {
+	return func(s sdktrace.ReadOnlySpan) bool {
+		for _, sampler := range samplers {
+			if sampler != nil && sampler(s) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,239 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 THL A29 Limited, a Tencent company.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+type recordProcessor struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (r *recordProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (r *recordProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+func (r *recordProcessor) Shutdown(context.Context) error { return nil }
+
+func (r *recordProcessor) ForceFlush(context.Context) error { return nil }
+
+func (r *recordProcessor) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var names []string
+	for _, s := range r.spans {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// endOk ends the span with ok status, the deferred sampler treats status other than ok as error
+func endOk(span apitrace.Span) {
+	span.SetStatus(codes.Ok, "")
+	span.End()
+}
+
+func newTailSampleTestProvider(cfg TailSampleConfig) (*sdktrace.TracerProvider, *recordProcessor) {
+	next := &recordProcessor{}
+	p := NewTailSampleProcessor(next, NewDeferredSampler(DeferredSampleConfig{
+		Enabled:     true,
+		SampleError: true,
+	}), cfg)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewSampler("", SamplerConfig{Fraction: 0}, func(o *SamplerOptions) {
+			o.DefaultSamplingDecision = sdktrace.RecordOnly
+		})),
+		sdktrace.WithSpanProcessor(p),
+	)
+	return tp, next
+}
+
+func TestTailSampleProcessor_KeepWholeTrace(t *testing.T) {
+	tp, next := newTailSampleTestProvider(TailSampleConfig{})
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child1 := tracer.Start(ctx, "child1")
+	endOk(child1)
+	_, child2 := tracer.Start(ctx, "child2")
+	child2.SetStatus(codes.Error, "failed")
+	child2.End()
+	assert.Empty(t, next.names())
+	endOk(root)
+	assert.ElementsMatch(t, []string{"root", "child1", "child2"}, next.names())
+
+	// a late span follows the decision of its trace
+	_, late := tracer.Start(ctx, "late")
+	endOk(late)
+	assert.ElementsMatch(t, []string{"root", "child1", "child2", "late"}, next.names())
+}
+
+func TestTailSampleProcessor_DropWholeTrace(t *testing.T) {
+	tp, next := newTailSampleTestProvider(TailSampleConfig{})
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	endOk(child)
+	endOk(root)
+	assert.Empty(t, next.names())
+	assert.NoError(t, tp.Shutdown(context.Background()))
+	assert.Empty(t, next.names())
+}
+
+func TestTailSampleProcessor_ConcurrentLateSpans(t *testing.T) {
+	next := &recordProcessor{}
+	// a slow predicate widens the window between removing a trace and publishing its decision
+	slowSampler := func(s sdktrace.ReadOnlySpan) bool {
+		if s.Status().Code == codes.Error {
+			time.Sleep(5 * time.Millisecond)
+			return true
+		}
+		return false
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewSampler("", SamplerConfig{Fraction: 0}, func(o *SamplerOptions) {
+			o.DefaultSamplingDecision = sdktrace.RecordOnly
+		})),
+		sdktrace.WithSpanProcessor(NewTailSampleProcessor(next, slowSampler, TailSampleConfig{})),
+	)
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	const n = 8
+	for i := 0; i < 10; i++ {
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, failed := tracer.Start(ctx, "failed")
+		failed.SetStatus(codes.Error, "failed")
+		failed.End()
+
+		var wg sync.WaitGroup
+		for j := 0; j < n; j++ {
+			_, child := tracer.Start(ctx, "child")
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				time.Sleep(time.Millisecond)
+				endOk(child)
+			}()
+		}
+		endOk(root)
+		wg.Wait()
+		// spans ending around the decision must follow it, the trace is never fragmented
+		assert.Len(t, next.names(), (i+1)*(n+2))
+	}
+}
+
+func TestTailSampleProcessor_FlushBuffered(t *testing.T) {
+	tp, next := newTailSampleTestProvider(TailSampleConfig{})
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root1")
+	_, child := tracer.Start(ctx, "child1")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	assert.Empty(t, next.names())
+	assert.NoError(t, tp.ForceFlush(context.Background()))
+	assert.Equal(t, []string{"child1"}, next.names())
+	endOk(root)
+	assert.Equal(t, []string{"child1", "root1"}, next.names())
+
+	ctx, root = tracer.Start(context.Background(), "root2")
+	_, child = tracer.Start(ctx, "child2")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	assert.NoError(t, tp.Shutdown(context.Background()))
+	assert.Equal(t, []string{"child1", "root1", "child2"}, next.names())
+}
+
+func TestTailSampleProcessor_Evict(t *testing.T) {
+	tp, next := newTailSampleTestProvider(TailSampleConfig{MaxSpans: 1})
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	ctx1, root1 := tracer.Start(context.Background(), "root1")
+	_, child1 := tracer.Start(ctx1, "child1")
+	child1.SetStatus(codes.Error, "failed")
+	child1.End()
+
+	ctx2, root2 := tracer.Start(context.Background(), "root2")
+	_, child2 := tracer.Start(ctx2, "child2")
+	endOk(child2)
+	// trace 1 is evicted and decided with the spans buffered so far
+	assert.Equal(t, []string{"child1"}, next.names())
+
+	endOk(root1)
+	endOk(root2)
+	assert.Equal(t, []string{"child1", "root1"}, next.names())
+}
+
+func TestTailSampleProcessor_DecisionWait(t *testing.T) {
+	tp, next := newTailSampleTestProvider(TailSampleConfig{DecisionWait: 100 * time.Millisecond})
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	assert.Eventually(t, func() bool {
+		return len(next.names()) == 1
+	}, time.Second, 50*time.Millisecond)
+	endOk(root)
+	assert.Equal(t, []string{"child", "root"}, next.names())
+}
+
+func TestAttributeDeferredSampler(t *testing.T) {
+	next := &recordProcessor{}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewSampler("", SamplerConfig{Fraction: 0}, func(o *SamplerOptions) {
+			o.DefaultSamplingDecision = sdktrace.RecordOnly
+		})),
+		sdktrace.WithSpanProcessor(NewTailSampleProcessor(next,
+			AnyDeferredSampler(NewDyeingDeferredSampler(), NewAttributeDeferredSampler("uid", "1")),
+			TailSampleConfig{})),
+	)
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "root1")
+	_, child := tracer.Start(ctx, "child1")
+	child.SetAttributes(attribute.String("uid", "2"))
+	endOk(child)
+	endOk(root)
+	assert.Empty(t, next.names())
+
+	ctx, root = tracer.Start(context.Background(), "root2")
+	_, child = tracer.Start(ctx, "child2")
+	child.SetAttributes(attribute.String("uid", "1"))
+	endOk(child)
+	endOk(root)
+	assert.Equal(t, []string{"child2", "root2"}, next.names())
+}